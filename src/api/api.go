@@ -170,6 +170,84 @@ type Hook struct {
 }
 
 func CreateWebhooks(ctx context.Context, streamerID string, hooks ...Hook) error {
+	if len(hooks) == 0 {
+		hooks = []Hook{
+			{"channel.ban", "1"},
+			{"channel.unban", "1"},
+			{"channel.moderator.add", "1"},
+			{"channel.moderator.remove", "1"},
+		}
+	}
+
+	return createWebhooks(ctx, streamerID, map[string]interface{}{
+		"broadcaster_user_id": streamerID,
+	}, hooks)
+}
+
+// UnbanRequestHooks need a moderator in their condition, the moderator must have granted the moderator:read:unban_requests
+// or moderator:manage:unban_requests scope.
+var UnbanRequestHooks = []Hook{
+	{"channel.unban_request.create", "1"},
+	{"channel.unban_request.resolve", "1"},
+}
+
+// CreateUnbanRequestWebhooks subscribes to the unban requests of the streamer, as seen by the moderator.
+func CreateUnbanRequestWebhooks(ctx context.Context, streamerID string, moderatorID string) error {
+	return createWebhooks(ctx, streamerID, map[string]interface{}{
+		"broadcaster_user_id": streamerID,
+		"moderator_user_id":   moderatorID,
+	}, UnbanRequestHooks)
+}
+
+// ChatHooks read the chat as a user, the user must have granted the user:read:chat and user:bot scopes
+// and be the broadcaster or one of their moderators.
+var ChatHooks = []Hook{
+	{"channel.chat.message", "1"},
+}
+
+// DeletionHooks tell which chat messages were deleted, they need the same user as the ChatHooks.
+var DeletionHooks = []Hook{
+	{"channel.chat.message_delete", "1"},
+}
+
+// ModerateHooks tell who took an action in the chat, the moderator must have granted the read scopes of every action.
+var ModerateHooks = []Hook{
+	{"channel.moderate", "2"},
+}
+
+// ModerateScopes are the read scopes of every action sent by the ModerateHooks.
+var ModerateScopes = []string{
+	"moderator:read:blocked_terms",
+	"moderator:read:chat_settings",
+	"moderator:manage:unban_requests",
+	"moderator:manage:banned_users",
+	"moderator:read:chat_messages",
+	"moderator:read:warnings",
+	"moderator:read:moderators",
+	"moderator:read:vips",
+}
+
+// CreateChatWebhooks subscribes to the chat of the streamer, as read by the user.
+func CreateChatWebhooks(ctx context.Context, streamerID string, userID string, hooks ...Hook) error {
+	if len(hooks) == 0 {
+		hooks = ChatHooks
+	}
+
+	return createWebhooks(ctx, streamerID, map[string]interface{}{
+		"broadcaster_user_id": streamerID,
+		"user_id":             userID,
+	}, hooks)
+}
+
+// CreateModerateWebhooks subscribes to the actions taken in the chat of the streamer, as seen by the moderator.
+func CreateModerateWebhooks(ctx context.Context, streamerID string, moderatorID string) error {
+	return createWebhooks(ctx, streamerID, map[string]interface{}{
+		"broadcaster_user_id": streamerID,
+		"moderator_user_id":   moderatorID,
+	}, ModerateHooks)
+}
+
+func createWebhooks(ctx context.Context, streamerID string, condition map[string]interface{}, hooks []Hook) error {
 	secret, err := utils.GenerateRandomString(64)
 	if err != nil {
 		return err
@@ -181,11 +259,9 @@ func CreateWebhooks(ctx context.Context, streamerID string, hooks ...Hook) error
 
 	cb := func(t string, v string) error {
 		data, err := json.Marshal(TwitchWebhookRequest{
-			Type:    t,
-			Version: v,
-			Condition: map[string]interface{}{
-				"broadcaster_user_id": streamerID,
-			},
+			Type:      t,
+			Version:   v,
+			Condition: condition,
 			Transport: TwitchCallbackTransport{
 				Method:   "webhook",
 				Callback: fmt.Sprintf("%s/webhook/%s/%s", configure.Config.GetString("website_url"), t, streamerID),
@@ -222,15 +298,6 @@ func CreateWebhooks(ctx context.Context, streamerID string, hooks ...Hook) error
 
 	wg := &sync.WaitGroup{}
 
-	if len(hooks) == 0 {
-		hooks = []Hook{
-			{"channel.ban", "1"},
-			{"channel.unban", "1"},
-			{"channel.moderator.add", "1"},
-			{"channel.moderator.remove", "1"},
-		}
-	}
-
 	redisCb := make(chan struct{})
 	errored := false
 
@@ -311,6 +378,11 @@ func RevokeWebhook(ctx context.Context, streamerID string, hooks ...Hook) error
 			{"channel.unban", "1"},
 			{"channel.moderator.add", "1"},
 			{"channel.moderator.remove", "1"},
+			{"channel.unban_request.create", "1"},
+			{"channel.unban_request.resolve", "1"},
+			{"channel.chat.message", "1"},
+			{"channel.chat.message_delete", "1"},
+			{"channel.moderate", "2"},
 		}
 	}
 
@@ -322,11 +394,12 @@ func RevokeWebhook(ctx context.Context, streamerID string, hooks ...Hook) error
 	wg.Add(len(hooks))
 	mtx := sync.Mutex{}
 	for _, h := range hooks {
-		cmd := pipe.HGet(ctx, fmt.Sprintf("webhook:twitch:%s:%s", h.Name, streamerID), "id")
-		go func(cmd *redis.StringCmd) {
+		key := fmt.Sprintf("webhook:twitch:%s:%s", h.Name, streamerID)
+		cmd := pipe.HGet(ctx, key, "id")
+		go func(key string, cmd *redis.StringCmd) {
 			<-redisCb
 			defer wg.Done()
-			if errored {
+			if errored || cmd.Val() == "" {
 				return
 			}
 			e := cb(cmd.Val())
@@ -334,12 +407,20 @@ func RevokeWebhook(ctx context.Context, streamerID string, hooks ...Hook) error
 				mtx.Lock()
 				err = multierror.Append(err, e)
 				mtx.Unlock()
+				return
+			}
+			// The id tells which subscriptions are active, so it is dropped with the subscription.
+			if e := redis.Client.Del(ctx, key).Err(); e != nil {
+				log.WithError(e).WithField("key", key).Error("redis")
 			}
-		}(cmd)
+		}(key, cmd)
 	}
 
 	_, err = pipe.Exec(ctx)
-	if err != nil {
+	if err == redis.ErrNil {
+		// The subscriptions which were never made have no id.
+		err = nil
+	} else if err != nil {
 		log.WithError(err).Error("revoke webhooks")
 		errored = true
 	}
@@ -350,3 +431,178 @@ func RevokeWebhook(ctx context.Context, streamerID string, hooks ...Hook) error
 
 	return err
 }
+
+type ModeratedChannelsResp struct {
+	Data       []ModeratedChannel `json:"data"`
+	Pagination struct {
+		Cursor string `json:"cursor"`
+	} `json:"pagination"`
+}
+
+type ModeratedChannel struct {
+	BroadcasterID    string `json:"broadcaster_id"`
+	BroadcasterLogin string `json:"broadcaster_login"`
+	BroadcasterName  string `json:"broadcaster_name"`
+}
+
+// GetModeratedChannels returns the channels the user moderates, the oauth token needs the user:read:moderated_channels scope.
+func GetModeratedChannels(ctx context.Context, oauth string, userID string) ([]ModeratedChannel, error) {
+	returnv := []ModeratedChannel{}
+	cursor := ""
+	for {
+		query := map[string]string{
+			"user_id": userID,
+			"first":   "100",
+		}
+		if cursor != "" {
+			query["after"] = cursor
+		}
+		params, _ := qs.Marshal(query)
+
+		req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("https://api.twitch.tv/helix/moderation/channels?%s", params), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header.Add("Client-Id", configure.Config.GetString("twitch_client_id"))
+		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", oauth))
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode > 300 {
+			log.WithField("body", string(data)).Error("twitch")
+			return nil, auth.InvalidRespTwitch
+		}
+
+		respData := ModeratedChannelsResp{}
+
+		if err := json.Unmarshal(data, &respData); err != nil {
+			return nil, err
+		}
+		returnv = append(returnv, respData.Data...)
+
+		if respData.Pagination.Cursor == "" || len(respData.Data) == 0 {
+			break
+		}
+		cursor = respData.Pagination.Cursor
+	}
+
+	return returnv, nil
+}
+
+// TwitchError is the error body helix responds with, the message is meant to be shown to the user.
+type TwitchError struct {
+	Status  int    `json:"status"`
+	Message string `json:"message"`
+}
+
+func (e *TwitchError) Error() string {
+	return fmt.Sprintf("twitch: %v %s", e.Status, e.Message)
+}
+
+type banUserRequest struct {
+	Data banUserData `json:"data"`
+}
+
+type banUserData struct {
+	UserID   string `json:"user_id"`
+	Duration int    `json:"duration,omitempty"`
+	Reason   string `json:"reason"`
+}
+
+// moderationRequest calls a helix moderation endpoint with the user token of the moderator.
+func moderationRequest(ctx context.Context, oauth string, method string, endpoint string, query map[string]string, body interface{}) error {
+	params, _ := qs.Marshal(query)
+
+	var reader *bytes.Buffer
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewBuffer(data)
+	} else {
+		reader = &bytes.Buffer{}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, fmt.Sprintf("https://api.twitch.tv/helix/%s?%s", endpoint, params), reader)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Add("Client-Id", configure.Config.GetString("twitch_client_id"))
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", oauth))
+	if body != nil {
+		req.Header.Add("Content-Type", "application/json")
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 300 {
+		return nil
+	}
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	twitchErr := &TwitchError{Status: resp.StatusCode}
+	if err := json.Unmarshal(data, twitchErr); err != nil || twitchErr.Message == "" {
+		log.WithField("body", string(data)).Error("twitch")
+		return auth.InvalidRespTwitch
+	}
+
+	return twitchErr
+}
+
+// BanUser bans the user from the channel, or times them out when duration is not zero.
+// The oauth token needs the moderator:manage:banned_users scope.
+func BanUser(ctx context.Context, oauth string, broadcasterID string, moderatorID string, userID string, duration time.Duration, reason string) error {
+	return moderationRequest(ctx, oauth, "POST", "moderation/bans", map[string]string{
+		"broadcaster_id": broadcasterID,
+		"moderator_id":   moderatorID,
+	}, banUserRequest{
+		Data: banUserData{
+			UserID:   userID,
+			Duration: int(duration / time.Second),
+			Reason:   reason,
+		},
+	})
+}
+
+// UnbanUser lifts the ban or timeout of the user.
+// The oauth token needs the moderator:manage:banned_users scope.
+func UnbanUser(ctx context.Context, oauth string, broadcasterID string, moderatorID string, userID string) error {
+	return moderationRequest(ctx, oauth, "DELETE", "moderation/bans", map[string]string{
+		"broadcaster_id": broadcasterID,
+		"moderator_id":   moderatorID,
+		"user_id":        userID,
+	}, nil)
+}
+
+// ResolveUnbanRequest approves or denies the unban request, status is either approved or denied.
+// The oauth token needs the moderator:manage:unban_requests scope.
+func ResolveUnbanRequest(ctx context.Context, oauth string, broadcasterID string, moderatorID string, requestID string, status string, resolution string) error {
+	return moderationRequest(ctx, oauth, "PATCH", "moderation/unban_requests", map[string]string{
+		"broadcaster_id":   broadcasterID,
+		"moderator_id":     moderatorID,
+		"unban_request_id": requestID,
+		"status":           status,
+		"resolution_text":  resolution,
+	}, nil)
+}
@@ -4,8 +4,11 @@ import (
 	"context"
 	"fmt"
 	"io/ioutil"
+	"math"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -95,3 +98,127 @@ func GetAuth(ctx context.Context) (string, error) {
 	}()
 	return auth, nil
 }
+
+type UserToken struct {
+	AccessToken  string   `json:"access_token"`
+	RefreshToken string   `json:"refresh_token"`
+	ExpiresIn    int      `json:"expires_in"`
+	Scope        []string `json:"scope"`
+	TokenType    string   `json:"token_type"`
+}
+
+var ErrNoUserToken = fmt.Errorf("no token for the user")
+
+// SaveUserToken stores the token of a user who logged in, it is considered expired a bit before twitch expires it.
+func SaveUserToken(ctx context.Context, userID string, token *UserToken) error {
+	data, _ := json.Marshal(token)
+	exp := int64(math.Floor(float64(token.ExpiresIn) * 0.7))
+	return redis.Client.HSet(ctx, "oauth:streamer", userID, fmt.Sprintf("%v %s", time.Now().Unix()+exp, string(data))).Err()
+}
+
+// GetUserToken returns the access token of a user who logged in, refreshing it when it expired.
+func GetUserToken(ctx context.Context, userID string) (string, error) {
+	val, err := redis.Client.HGet(ctx, "oauth:streamer", userID).Result()
+	if err != nil {
+		if err == redis.ErrNil {
+			return "", ErrNoUserToken
+		}
+		return "", err
+	}
+
+	parts := strings.SplitN(val, " ", 2)
+	if len(parts) != 2 {
+		return "", ErrNoUserToken
+	}
+
+	token := &UserToken{}
+	if err := json.Unmarshal([]byte(parts[1]), token); err != nil {
+		return "", err
+	}
+
+	expires, _ := strconv.ParseInt(parts[0], 10, 64)
+	if time.Now().Unix() < expires {
+		return token.AccessToken, nil
+	}
+
+	if token.RefreshToken == "" {
+		return "", ErrNoUserToken
+	}
+
+	params, _ := qs.Marshal(map[string]string{
+		"client_id":     configure.Config.GetString("twitch_client_id"),
+		"client_secret": configure.Config.GetString("twitch_client_secret"),
+		"grant_type":    "refresh_token",
+		"refresh_token": token.RefreshToken,
+	})
+	u, _ := url.Parse(fmt.Sprintf("https://id.twitch.tv/oauth2/token?%s", params))
+	resp, err := http.DefaultClient.Do(&http.Request{
+		Method: "POST",
+		URL:    u,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode == 400 || resp.StatusCode == 401 {
+		// The user revoked the application or changed their password.
+		if err := redis.Client.HDel(ctx, "oauth:streamer", userID).Err(); err != nil {
+			log.WithError(err).Error("redis")
+		}
+		return "", ErrNoUserToken
+	}
+	if resp.StatusCode > 200 {
+		log.WithField("resp", string(data)).Error("auth")
+		return "", InvalidRespTwitch
+	}
+
+	refreshed := &UserToken{}
+	if err := json.Unmarshal(data, refreshed); err != nil {
+		return "", err
+	}
+
+	if err := SaveUserToken(ctx, userID, refreshed); err != nil {
+		log.WithError(err).Error("redis")
+	}
+
+	return refreshed.AccessToken, nil
+}
+
+// HasUserScopes reports if the user granted every scope when they logged in.
+func HasUserScopes(ctx context.Context, userID string, scopes ...string) (bool, error) {
+	val, err := redis.Client.HGet(ctx, "oauth:streamer", userID).Result()
+	if err != nil {
+		if err == redis.ErrNil {
+			return false, ErrNoUserToken
+		}
+		return false, err
+	}
+
+	parts := strings.SplitN(val, " ", 2)
+	if len(parts) != 2 {
+		return false, ErrNoUserToken
+	}
+
+	token := &UserToken{}
+	if err := json.Unmarshal([]byte(parts[1]), token); err != nil {
+		return false, err
+	}
+
+	granted := map[string]bool{}
+	for _, s := range token.Scope {
+		granted[s] = true
+	}
+	for _, s := range scopes {
+		if !granted[s] {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
@@ -0,0 +1,233 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	log "github.com/sirupsen/logrus"
+	"github.com/troydota/modlogs/src/api"
+	"github.com/troydota/modlogs/src/auth"
+	"github.com/troydota/modlogs/src/mongo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Twitch doesn't allow timeouts longer than two weeks.
+const maxTimeout = 14 * 24 * time.Hour
+
+var errNoModerationToken = fmt.Errorf("no moderation token")
+
+func init() {
+	componentHandlers["unban"] = componentRoute{permissionManage, unbanButtonHandler}
+	componentHandlers["extend"] = componentRoute{permissionManage, extendButtonHandler}
+	componentHandlers["extendsubmit"] = componentRoute{permissionManage, extendSubmitHandler}
+	componentHandlers["history"] = componentRoute{permissionRead, historyButtonHandler}
+}
+
+// logButtons returns the buttons added to the embed log of the event.
+func logButtons(e *mongo.Event) []*component {
+	if e.Case == 0 {
+		return nil
+	}
+
+	buttons := []*component{}
+	switch eventKind(e) {
+	case kindBan:
+		buttons = append(buttons, &component{Type: componentButton, Style: buttonSuccess, Label: "Unban", CustomID: fmt.Sprintf("unban:%v", e.Case)})
+	case kindTimeout:
+		buttons = append(buttons,
+			&component{Type: componentButton, Style: buttonSuccess, Label: "Unban", CustomID: fmt.Sprintf("unban:%v", e.Case)},
+			&component{Type: componentButton, Style: buttonDanger, Label: "Extend timeout", CustomID: fmt.Sprintf("extend:%v", e.Case)},
+		)
+	}
+	if e.UserID != "" {
+		buttons = append(buttons, &component{Type: componentButton, Style: buttonSecondary, Label: "View history", CustomID: fmt.Sprintf("history:%v", e.Case)})
+	}
+	buttons = append(buttons, noteButton(e.Case))
+
+	return []*component{actionRow(buttons...)}
+}
+
+// moderationToken returns a user token which can moderate the channel of the broadcaster, only the accounts which
+// authorized a hook of the broadcaster in the guild are used.
+func moderationToken(ctx context.Context, guildID string, broadcasterID string) (string, string, error) {
+	candidates, err := hookAuthorizers(ctx, guildID, broadcasterID)
+	if err != nil {
+		return "", "", err
+	}
+
+	for _, id := range candidates {
+		ok, err := auth.HasUserScopes(ctx, id, "moderator:manage:banned_users")
+		if err == auth.ErrNoUserToken || (err == nil && !ok) {
+			continue
+		}
+		if err != nil {
+			return "", "", err
+		}
+
+		token, err := auth.GetUserToken(ctx, id)
+		if err == auth.ErrNoUserToken {
+			continue
+		}
+		if err != nil {
+			return "", "", err
+		}
+		return token, id, nil
+	}
+
+	return "", "", errNoModerationToken
+}
+
+// latestRestriction returns the latest ban, timeout or unban of the user in the channel, nil when there is none.
+func latestRestriction(broadcasterID string, userID string) (*mongo.Event, error) {
+	e := &mongo.Event{}
+	err := mongo.Database.Collection("events").FindOne(context.Background(), bson.M{
+		"broadcaster_id": broadcasterID,
+		"user_id":        userID,
+		"action":         bson.M{"$in": bson.A{"channel.ban", "channel.unban"}},
+	}, options.FindOne().SetSort(bson.M{"created_at": -1})).Decode(e)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	return e, err
+}
+
+// moderationErrorMessage explains why a twitch moderation action failed.
+func moderationErrorMessage(err error) string {
+	if err == errUnknownCase {
+		return "That case doesn't belong to a broadcaster hooked in this discord."
+	}
+	if err == errNoModerationToken {
+		return "No one who authorized a hook of that channel in this discord can moderate it, the broadcaster or a moderator has to authorize a hook here before actions can be taken from discord."
+	}
+	if twitchErr, ok := err.(*api.TwitchError); ok {
+		if twitchErr.Status == 401 {
+			return "The stored twitch login is missing the permission for that action, the broadcaster or a moderator has to login again."
+		}
+		return fmt.Sprintf("Twitch refused the action: %s", twitchErr.Message)
+	}
+	log.WithError(err).Error("api")
+	return "Internal server error. Please try again later."
+}
+
+func unbanButtonHandler(s *discordgo.Session, i *discordgo.InteractionCreate, c *componentInteraction, g *discordgo.Guild, arg string) {
+	caseID, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil {
+		respond(s, i, "Invalid case.", true)
+		return
+	}
+
+	e, err := caseEvent(g.ID, caseID)
+	if err != nil {
+		respond(s, i, moderationErrorMessage(err), true)
+		return
+	}
+
+	if err := moderate(context.Background(), g.ID, i.Member.User.ID, e.BroadcasterID, e.UserID, kindUnban, 0, ""); err != nil {
+		respond(s, i, moderationErrorMessage(err), true)
+		return
+	}
+
+	// The outcome is posted visibly under the log, so the channel knows who acted on it.
+	respondf(s, i, false, "<@%s> unbanned `%s` from #%s (case `#%v`).", i.Member.User.ID, strings.ReplaceAll(e.UserName, "`", ""), e.BroadcasterUserName, caseID)
+}
+
+func extendButtonHandler(s *discordgo.Session, i *discordgo.InteractionCreate, c *componentInteraction, g *discordgo.Guild, arg string) {
+	lang := guildLanguage(i.GuildID)
+	if err := showModal(s, i, fmt.Sprintf("extendsubmit:%s", arg), tr(lang, "Extend timeout #%s", arg), tr(lang, "Extra time, such as 30m or 2h")); err != nil {
+		log.WithError(err).Error("discord")
+	}
+}
+
+func extendSubmitHandler(s *discordgo.Session, i *discordgo.InteractionCreate, c *componentInteraction, g *discordgo.Guild, arg string) {
+	caseID, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil {
+		respond(s, i, "Invalid case.", true)
+		return
+	}
+
+	extra, err := time.ParseDuration(strings.TrimSpace(c.value("text")))
+	if err != nil || extra <= 0 {
+		respond(s, i, "Invalid duration, use a value such as 30m or 2h.", true)
+		return
+	}
+
+	e, err := caseEvent(g.ID, caseID)
+	if err != nil {
+		respond(s, i, moderationErrorMessage(err), true)
+		return
+	}
+	if e.Expires == nil {
+		respond(s, i, "That case is not a timeout.", true)
+		return
+	}
+
+	// The user may have been unbanned or actioned again since the case, the latest action decides what is extended.
+	latest, err := latestRestriction(e.BroadcasterID, e.UserID)
+	if err != nil {
+		log.WithError(err).Error("mongo")
+		respond(s, i, "Internal server error. Please try again later.", true)
+		return
+	}
+	if latest == nil || latest.Action != "channel.ban" || latest.Expires == nil || !latest.Expires.After(time.Now()) {
+		respond(s, i, "That user is no longer timed out, time them out again instead.", true)
+		return
+	}
+
+	// A new timeout replaces the current one, so the time left is added to the extension.
+	duration := extra + time.Until(*latest.Expires)
+	if duration > maxTimeout {
+		duration = maxTimeout
+	}
+
+	if err := moderate(context.Background(), g.ID, i.Member.User.ID, e.BroadcasterID, e.UserID, kindTimeout, duration, e.Reason); err != nil {
+		respond(s, i, moderationErrorMessage(err), true)
+		return
+	}
+
+	respondf(s, i, false, "<@%s> extended the timeout of `%s` in #%s by %s (case `#%v`).", i.Member.User.ID, strings.ReplaceAll(e.UserName, "`", ""), e.BroadcasterUserName, formatDuration(extra), caseID)
+}
+
+func historyButtonHandler(s *discordgo.Session, i *discordgo.InteractionCreate, c *componentInteraction, g *discordgo.Guild, arg string) {
+	caseID, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil {
+		respond(s, i, "Invalid case.", true)
+		return
+	}
+
+	e, err := caseEvent(g.ID, caseID)
+	if err != nil {
+		respond(s, i, noteErrorMessage(err), true)
+		return
+	}
+
+	streamers, err := guildStreamers([]string{g.ID})
+	if err != nil {
+		log.WithError(err).Error("mongo")
+		respond(s, i, "Internal server error. Please try again later.", true)
+		return
+	}
+
+	embed, err := historyEmbed(g.ID, &mongo.User{ID: e.UserID, Name: e.UserName, Login: e.UserName}, streamers[g.ID])
+	if err != nil {
+		log.WithError(err).Error("mongo")
+		respond(s, i, "Internal server error. Please try again later.", true)
+		return
+	}
+
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionApplicationCommandResponseData{
+			Embeds: []*discordgo.MessageEmbed{embed},
+			// Makes the response ephemeral https://discord.com/developers/docs/interactions/slash-commands#interaction-response
+			Flags: 64,
+		},
+	})
+	if err != nil {
+		log.WithError(err).Error("discord")
+	}
+}
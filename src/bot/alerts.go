@@ -0,0 +1,369 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	log "github.com/sirupsen/logrus"
+	"github.com/troydota/modlogs/src/mongo"
+	"github.com/troydota/modlogs/src/redis"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Alert windows are capped so that the redis windows can be trimmed to a fixed size.
+const maxAlertWindow = 24 * time.Hour
+
+var alertsCommand = &discordgo.ApplicationCommand{
+	Name:        "alerts",
+	Description: "Alerts the hook channels about unusual moderation activity.",
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "set",
+			Description: "Alerts when an action happens a number of times within some minutes.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "kind",
+					Description: "The activity to watch.",
+					Required:    true,
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "bans in a channel", Value: mongo.AlertBans},
+						{Name: "bans by a single moderator", Value: mongo.AlertModBans},
+						{Name: "timeouts of the same user", Value: mongo.AlertTimeouts},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "count",
+					Description: "The number of actions that triggers the alert.",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "minutes",
+					Description: "The window the actions are counted in, at most 1440 minutes.",
+					Required:    true,
+				},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "disable",
+			Description: "Stops an alert.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "kind",
+					Description: "The activity to stop watching.",
+					Required:    true,
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "bans in a channel", Value: mongo.AlertBans},
+						{Name: "bans by a single moderator", Value: mongo.AlertModBans},
+						{Name: "timeouts of the same user", Value: mongo.AlertTimeouts},
+					},
+				},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "role",
+			Description: "Sets the role pinged by alerts, leave it empty to stop pinging.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionRole,
+					Name:        "role",
+					Description: "The discord role.",
+					Required:    false,
+				},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "list",
+			Description: "Shows the alerts of this discord.",
+		},
+	},
+}
+
+func alertName(kind string, lang string) string {
+	switch kind {
+	case mongo.AlertBans:
+		return tr(lang, "bans in a channel")
+	case mongo.AlertModBans:
+		return tr(lang, "bans by a single moderator")
+	case mongo.AlertTimeouts:
+		return tr(lang, "timeouts of the same user")
+	}
+	return kind
+}
+
+func alertsHandler(s *discordgo.Session, i *discordgo.InteractionCreate, g *discordgo.Guild) {
+	if len(i.Data.Options) == 0 {
+		respond(s, i, "Please select a sub command.", true)
+		return
+	}
+
+	sub := i.Data.Options[0]
+	lang := guildLanguage(g.ID)
+
+	var kind string
+	var count int64
+	var minutes int64
+	var role *discordgo.Role
+	for _, o := range sub.Options {
+		switch o.Name {
+		case "kind":
+			kind = o.StringValue()
+		case "count":
+			count = o.IntValue()
+		case "minutes":
+			minutes = o.IntValue()
+		case "role":
+			role = o.RoleValue(s, g.ID)
+		}
+	}
+
+	filter := bson.M{"guild_id": g.ID}
+	opts := options.Update().SetUpsert(true)
+
+	switch sub.Name {
+	case "list":
+		settings := &mongo.AlertSettings{}
+		err := mongo.Database.Collection("alerts").FindOne(context.Background(), filter).Decode(settings)
+		if err != nil && err != mongo.ErrNoDocuments {
+			log.WithError(err).Error("mongo")
+			respond(s, i, "Internal server error. Please try again later.", true)
+			return
+		}
+
+		lines := []string{}
+		for _, r := range settings.Rules {
+			lines = append(lines, tr(lang, "%v %s within %v minutes", r.Count, alertName(r.Kind, lang), r.Minutes))
+		}
+		if len(lines) == 0 {
+			lines = append(lines, tr(lang, "There are no alerts in this discord."))
+		}
+		if settings.RoleID != "" {
+			lines = append(lines, tr(lang, "Alerts ping <@&%s>.", settings.RoleID))
+		}
+
+		respond(s, i, strings.Join(lines, "\n"), true)
+		return
+	case "role":
+		update := bson.M{"$unset": bson.M{"role_id": ""}}
+		msg := "Alerts no longer ping a role."
+		if role != nil {
+			update = bson.M{"$set": bson.M{"role_id": role.ID}}
+			msg = tr(lang, "Alerts now ping <@&%s>.", role.ID)
+		}
+		if _, err := mongo.Database.Collection("alerts").UpdateOne(context.Background(), filter, update, opts); err != nil {
+			log.WithError(err).Error("mongo")
+			respond(s, i, "Internal server error. Please try again later.", true)
+			return
+		}
+		respond(s, i, msg, true)
+		return
+	}
+
+	// An invalid command must not remove the current alert.
+	if sub.Name != "disable" && (count < 2 || minutes < 1 || time.Duration(minutes)*time.Minute > maxAlertWindow) {
+		respond(s, i, "The count has to be at least 2 and the window between 1 and 1440 minutes.", true)
+		return
+	}
+
+	if _, err := mongo.Database.Collection("alerts").UpdateOne(context.Background(), filter, bson.M{
+		"$pull": bson.M{
+			"rules": bson.M{"kind": kind},
+		},
+	}); err != nil {
+		log.WithError(err).Error("mongo")
+		respond(s, i, "Internal server error. Please try again later.", true)
+		return
+	}
+
+	if sub.Name == "disable" {
+		respondf(s, i, true, "Stopped alerting about %s.", alertName(kind, lang))
+		return
+	}
+
+	if _, err := mongo.Database.Collection("alerts").UpdateOne(context.Background(), filter, bson.M{
+		"$push": bson.M{
+			"rules": mongo.AlertRule{
+				Kind:    kind,
+				Count:   int32(count),
+				Minutes: int32(minutes),
+			},
+		},
+	}, opts); err != nil {
+		log.WithError(err).Error("mongo")
+		respond(s, i, "Internal server error. Please try again later.", true)
+		return
+	}
+
+	respondf(s, i, true, "The hook channels will be alerted about %v %s within %v minutes.", count, alertName(kind, lang), minutes)
+}
+
+// alertWindows returns the redis sorted sets the event is counted in for the guild, by alert kind.
+// Every guild counts on its own so its ignore rules apply.
+func alertWindows(guildID string, e *mongo.Event) map[string]string {
+	switch eventKind(e) {
+	case kindBan:
+		return map[string]string{
+			mongo.AlertBans:    fmt.Sprintf("alerts:%s:bans:%s", guildID, e.BroadcasterID),
+			mongo.AlertModBans: fmt.Sprintf("alerts:%s:bans:%s:%s", guildID, e.BroadcasterID, eventExecutor(e)),
+		}
+	case kindTimeout:
+		return map[string]string{
+			mongo.AlertTimeouts: fmt.Sprintf("alerts:%s:timeouts:%s:%s", guildID, e.BroadcasterID, e.UserID),
+		}
+	}
+	return nil
+}
+
+// checkAlerts counts the event in the sliding windows and alerts the hooks of the guilds whose thresholds are reached.
+// Hooks which ignore the event neither count it nor get alerted.
+func (b *Bot) checkAlerts(e *mongo.Event, hooks []*mongo.Hook) {
+	if len(alertWindows("", e)) == 0 || len(hooks) == 0 {
+		return
+	}
+
+	guildIDs := []string{}
+	guildHooks := map[string][]*mongo.Hook{}
+	for _, h := range hooks {
+		if isIgnored(h.GuildID, h.ChannelID, e) {
+			continue
+		}
+		if _, ok := guildHooks[h.GuildID]; !ok {
+			guildIDs = append(guildIDs, h.GuildID)
+		}
+		guildHooks[h.GuildID] = append(guildHooks[h.GuildID], h)
+	}
+	if len(guildIDs) == 0 {
+		return
+	}
+
+	settings := []*mongo.AlertSettings{}
+	cur, err := mongo.Database.Collection("alerts").Find(context.Background(), bson.M{"guild_id": bson.M{"$in": guildIDs}})
+	if err == nil {
+		err = cur.All(context.Background(), &settings)
+	}
+	if err != nil {
+		log.WithError(err).Error("mongo")
+		return
+	}
+
+	now := time.Now()
+	for _, st := range settings {
+		if len(st.Rules) == 0 {
+			continue
+		}
+
+		windows := alertWindows(st.GuildID, e)
+		pipe := redis.Client.TxPipeline()
+		for _, key := range windows {
+			pipe.ZAdd(context.Background(), key, &redis.Z{Score: float64(e.CreatedAt.UnixNano() / int64(time.Millisecond)), Member: e.ID})
+			pipe.ZRemRangeByScore(context.Background(), key, "-inf", fmt.Sprint(now.Add(-maxAlertWindow).UnixNano()/int64(time.Millisecond)))
+			pipe.Expire(context.Background(), key, maxAlertWindow)
+		}
+		if _, err := pipe.Exec(context.Background()); err != nil {
+			log.WithError(err).Error("redis")
+			continue
+		}
+
+		for _, r := range st.Rules {
+			key, ok := windows[r.Kind]
+			if !ok {
+				continue
+			}
+
+			window := time.Duration(r.Minutes) * time.Minute
+			count, err := redis.Client.ZCount(context.Background(), key, fmt.Sprint(now.Add(-window).UnixNano()/int64(time.Millisecond)), "+inf").Result()
+			if err != nil {
+				log.WithError(err).Error("redis")
+				continue
+			}
+			if count < int64(r.Count) {
+				continue
+			}
+
+			// Only alert once per window, otherwise every following action would alert again.
+			ok, err = redis.Client.SetNX(context.Background(), fmt.Sprintf("alerts:cooldown:%s:%s", st.GuildID, key), 1, window).Result()
+			if err != nil {
+				log.WithError(err).Error("redis")
+				continue
+			}
+			if !ok {
+				continue
+			}
+
+			b.sendAlert(guildHooks[st.GuildID], st.RoleID, alertEmbed(e, r, count, guildLanguage(st.GuildID)))
+		}
+	}
+}
+
+func alertEmbed(e *mongo.Event, r mongo.AlertRule, count int64, lang string) *discordgo.MessageEmbed {
+	var description string
+	switch r.Kind {
+	case mongo.AlertBans:
+		description = tr(lang, "%v users were banned in #%s within %v minutes.", count, e.BroadcasterUserName, r.Minutes)
+	case mongo.AlertModBans:
+		moderator := e.ModeratorUserName
+		if moderator == "" {
+			moderator = e.BroadcasterUserName
+		}
+		description = tr(lang, "%s banned %v users in #%s within %v minutes.", moderator, count, e.BroadcasterUserName, r.Minutes)
+	case mongo.AlertTimeouts:
+		description = tr(lang, "%s was timed out %v times in #%s within %v minutes.", e.UserName, count, e.BroadcasterUserName, r.Minutes)
+	}
+
+	return localizeEmbed(&discordgo.MessageEmbed{
+		Title:       "Moderation Activity Alert",
+		Description: description,
+		Color:       15158332,
+		Timestamp:   e.CreatedAt.Format(time.RFC3339),
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "KomodoHype",
+		},
+	}, lang)
+}
+
+// sendAlert posts the alert into every hook channel once, pinging the role when one is set.
+func (b *Bot) sendAlert(hooks []*mongo.Hook, roleID string, embed *discordgo.MessageEmbed) {
+	var content string
+	mentions := noMentions()
+	if roleID != "" {
+		content = fmt.Sprintf("<@&%s>", roleID)
+		mentions.Roles = []string{roleID}
+	}
+
+	sent := map[string]bool{}
+	for _, h := range hooks {
+		if sent[h.ChannelID] {
+			continue
+		}
+		sent[h.ChannelID] = true
+
+		var err error
+		if h.Delivery == mongo.DeliveryWebhook {
+			_, err = b.executeWebhook(h, &discordgo.WebhookParams{
+				Content:         content,
+				Embeds:          []*discordgo.MessageEmbed{embed},
+				AllowedMentions: mentions,
+			})
+		} else {
+			_, err = b.conn.ChannelMessageSendComplex(h.ChannelID, &discordgo.MessageSend{
+				Content:         content,
+				Embed:           embed,
+				AllowedMentions: mentions,
+			})
+		}
+		if err != nil {
+			log.WithError(err).WithField("hook", h).Error("discord")
+		}
+	}
+}
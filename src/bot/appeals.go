@@ -0,0 +1,424 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	log "github.com/sirupsen/logrus"
+	"github.com/troydota/modlogs/src/configure"
+	"github.com/troydota/modlogs/src/mongo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var ErrNoAppealChannel = fmt.Errorf("no appeal channel")
+
+var errUnknownAppeal = fmt.Errorf("unknown appeal")
+
+var appealsCommand = &discordgo.ApplicationCommand{
+	Name:        "appeals",
+	Description: "Configure where the ban appeals of a broadcaster are reviewed.",
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "set",
+			Description: "Posts the ban appeals of the broadcaster into a channel.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "broadcaster",
+					Description: "The ID or name of the twitch streamer.",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionChannel,
+					Name:        "channel",
+					Description: "Text channel where the appeals are posted.",
+					Required:    true,
+				},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "disable",
+			Description: "Stops posting the ban appeals of the broadcaster.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "broadcaster",
+					Description: "The ID or name of the twitch streamer.",
+					Required:    true,
+				},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "list",
+			Description: "Lists where the ban appeals are posted.",
+		},
+	},
+}
+
+func init() {
+	componentHandlers["appeal"] = componentRoute{permissionManage, appealButtonHandler}
+	componentHandlers["appealreply"] = componentRoute{permissionManage, appealReplyHandler}
+}
+
+func appealsHandler(s *discordgo.Session, i *discordgo.InteractionCreate, g *discordgo.Guild) {
+	if len(i.Data.Options) == 0 {
+		respond(s, i, "Please select a sub command.", true)
+		return
+	}
+
+	sub := i.Data.Options[0]
+
+	var broadcasterInput string
+	var channel *discordgo.Channel
+	for _, o := range sub.Options {
+		switch o.Name {
+		case "broadcaster":
+			broadcasterInput = o.StringValue()
+		case "channel":
+			channel = o.ChannelValue(s)
+		}
+	}
+
+	if sub.Name == "list" {
+		channels := []*mongo.AppealChannel{}
+		cur, err := mongo.Database.Collection("appealchannels").Find(context.Background(), bson.M{"guild_id": g.ID})
+		if err == nil {
+			err = cur.All(context.Background(), &channels)
+		}
+		if err != nil {
+			log.WithError(err).Error("mongo")
+			respond(s, i, "Internal server error. Please try again later.", true)
+			return
+		}
+
+		if len(channels) == 0 {
+			respond(s, i, "Ban appeals are not posted in this discord.", true)
+			return
+		}
+
+		ids := []string{}
+		for _, v := range channels {
+			ids = append(ids, v.StreamerID)
+		}
+		users, err := resolveUsers(ids, nil)
+		if err != nil {
+			log.WithError(err).Error("api")
+		}
+
+		lines := []string{}
+		for _, v := range channels {
+			login := v.StreamerID
+			if u, ok := users[v.StreamerID]; ok {
+				login = u.Login
+			}
+			lines = append(lines, fmt.Sprintf("<https://twitch.tv/%s> -> <#%s>", login, v.ChannelID))
+		}
+
+		respond(s, i, strings.Join(lines, "\n"), true)
+		return
+	}
+
+	broadcaster, err := hookedBroadcaster(g.ID, broadcasterInput)
+	if err != nil {
+		if err == errNotHooked {
+			respond(s, i, "That broadcaster is not hooked in this discord.", true)
+			return
+		}
+		log.WithError(err).Error("mongo")
+		respond(s, i, "Internal server error. Please try again later.", true)
+		return
+	}
+
+	filter := bson.M{
+		"guild_id":    g.ID,
+		"streamer_id": broadcaster.ID,
+	}
+
+	if sub.Name == "disable" {
+		if _, err := mongo.Database.Collection("appealchannels").DeleteOne(context.Background(), filter); err != nil {
+			log.WithError(err).Error("mongo")
+			respond(s, i, "Internal server error. Please try again later.", true)
+			return
+		}
+
+		respondf(s, i, false, "Ban appeals of <https://twitch.tv/%s> are no longer posted in this discord.", broadcaster.Login)
+		return
+	}
+
+	if channel == nil || channel.GuildID != g.ID || channel.Type != discordgo.ChannelTypeGuildText {
+		respond(s, i, "Please select a text channel of this discord.", true)
+		return
+	}
+
+	if _, err := mongo.Database.Collection("appealchannels").UpdateOne(context.Background(), filter, bson.M{
+		"$set": &mongo.AppealChannel{
+			GuildID:    g.ID,
+			StreamerID: broadcaster.ID,
+			ChannelID:  channel.ID,
+		},
+	}, options.Update().SetUpsert(true)); err != nil {
+		log.WithError(err).Error("mongo")
+		respond(s, i, "Internal server error. Please try again later.", true)
+		return
+	}
+
+	respondf(s, i, false, "Ban appeals of <https://twitch.tv/%s> will be posted in <#%s>, users can appeal at <%s/appeal>.", broadcaster.Login, channel.ID, configure.Config.GetString("website_url"))
+}
+
+type AppealRequest struct {
+	Appeal *mongo.Appeal
+	Result chan error
+}
+
+// Appeals receives the ban appeals submitted on the website.
+var Appeals = make(chan AppealRequest)
+
+// appealEmbed shows the appeal, with the decision once it is resolved.
+func appealEmbed(a *mongo.Appeal, lang string) *discordgo.MessageEmbed {
+	fields := []*discordgo.MessageEmbedField{
+		{Name: "Broadcaster", Value: a.BroadcasterUserName, Inline: true},
+		{Name: "User", Value: a.UserName, Inline: true},
+	}
+
+	ban := &mongo.Event{}
+	if err := mongo.Database.Collection("events").FindOne(context.Background(), bson.M{"id": a.EventID}).Decode(ban); err != nil {
+		log.WithError(err).Error("mongo")
+	} else {
+		reason := ban.Reason
+		if reason == "" {
+			reason = "None Provided"
+		}
+		moderator := ban.ModeratorUserName
+		if moderator == "" {
+			moderator = ban.BroadcasterUserName
+		}
+		fields = append(fields,
+			&discordgo.MessageEmbedField{Name: "Banned", Value: tr(lang, "By %s on %s", moderator, discordTime(ban.CreatedAt))},
+			&discordgo.MessageEmbedField{Name: "Ban Reason", Value: reason},
+		)
+		if ban.Case != 0 {
+			fields = append(fields, &discordgo.MessageEmbedField{Name: "Case", Value: fmt.Sprintf("#%v", ban.Case), Inline: true})
+		}
+	}
+
+	fields = append(fields, &discordgo.MessageEmbedField{Name: "Appeal", Value: a.Text})
+
+	title := "Ban Appeal"
+	color := 16312092
+	switch a.Status {
+	case mongo.AppealApproved:
+		title = "Ban Appeal Approved"
+		color = 8311585
+	case mongo.AppealDenied:
+		title = "Ban Appeal Denied"
+		color = 13632027
+	}
+	if a.Status != mongo.AppealPending {
+		fields = append(fields,
+			&discordgo.MessageEmbedField{Name: "Resolved By", Value: fmt.Sprintf("<@%s>", a.ResolvedBy), Inline: true},
+			&discordgo.MessageEmbedField{Name: "Response", Value: a.Response},
+		)
+	}
+
+	return localizeEmbed(&discordgo.MessageEmbed{
+		Title:       title,
+		Description: "_ _",
+		Color:       color,
+		Timestamp:   a.CreatedAt.Format(time.RFC3339),
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "KomodoHype",
+		},
+		Fields: fields,
+	}, lang)
+}
+
+func appealButtons(a *mongo.Appeal) []*component {
+	return []*component{actionRow(
+		&component{Type: componentButton, Style: buttonSuccess, Label: "Approve", CustomID: fmt.Sprintf("appeal:approve:%s", a.ID.Hex())},
+		&component{Type: componentButton, Style: buttonDanger, Label: "Deny", CustomID: fmt.Sprintf("appeal:deny:%s", a.ID.Hex())},
+	)}
+}
+
+// processAppeal posts the appeal into the review channels of the broadcaster.
+func (b *Bot) processAppeal(req AppealRequest) {
+	channels := []*mongo.AppealChannel{}
+	cur, err := mongo.Database.Collection("appealchannels").Find(context.Background(), bson.M{"streamer_id": req.Appeal.BroadcasterID})
+	if err == nil {
+		err = cur.All(context.Background(), &channels)
+	}
+	if err != nil {
+		req.Result <- err
+		return
+	}
+
+	messages := []mongo.AppealMessage{}
+	for _, v := range channels {
+		lang := guildLanguage(v.GuildID)
+		msg, err := channelMessageSendComponents(b.conn, v.ChannelID, appealEmbed(req.Appeal, lang), localizeComponents(appealButtons(req.Appeal), lang))
+		if err != nil {
+			log.WithError(err).WithField("channel", v).Error("discord")
+			continue
+		}
+		messages = append(messages, mongo.AppealMessage{ChannelID: v.ChannelID, MessageID: msg.ID})
+	}
+
+	if len(messages) == 0 {
+		req.Result <- ErrNoAppealChannel
+		return
+	}
+
+	if _, err := mongo.Database.Collection("appeals").UpdateOne(context.Background(), bson.M{"_id": req.Appeal.ID}, bson.M{
+		"$set": bson.M{
+			"messages": messages,
+		},
+	}); err != nil {
+		log.WithError(err).Error("mongo")
+	}
+
+	req.Result <- nil
+}
+
+// guildAppeal returns the pending appeal, when it is reviewed in the guild.
+func guildAppeal(guildID string, id string) (*mongo.Appeal, error) {
+	appealID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errUnknownAppeal
+	}
+
+	appeal := &mongo.Appeal{}
+	if err := mongo.Database.Collection("appeals").FindOne(context.Background(), bson.M{"_id": appealID}).Decode(appeal); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errUnknownAppeal
+		}
+		return nil, err
+	}
+
+	count, err := mongo.Database.Collection("appealchannels").CountDocuments(context.Background(), bson.M{
+		"guild_id":    guildID,
+		"streamer_id": appeal.BroadcasterID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, errUnknownAppeal
+	}
+
+	return appeal, nil
+}
+
+func appealButtonHandler(s *discordgo.Session, i *discordgo.InteractionCreate, c *componentInteraction, g *discordgo.Guild, arg string) {
+	title := "Approve the appeal"
+	if strings.HasPrefix(arg, "deny:") {
+		title = "Deny the appeal"
+	}
+
+	lang := guildLanguage(i.GuildID)
+	if err := showModal(s, i, fmt.Sprintf("appealreply:%s", arg), tr(lang, title), tr(lang, "Reply shown to the user")); err != nil {
+		log.WithError(err).Error("discord")
+	}
+}
+
+func appealReplyHandler(s *discordgo.Session, i *discordgo.InteractionCreate, c *componentInteraction, g *discordgo.Guild, arg string) {
+	parts := strings.SplitN(arg, ":", 2)
+	if len(parts) != 2 || (parts[0] != "approve" && parts[0] != "deny") {
+		respond(s, i, "Invalid appeal.", true)
+		return
+	}
+
+	response := strings.TrimSpace(c.value("text"))
+	if response == "" {
+		respond(s, i, "Please enter a reply.", true)
+		return
+	}
+
+	appeal, err := guildAppeal(g.ID, parts[1])
+	if err != nil {
+		if err == errUnknownAppeal {
+			respond(s, i, "That appeal doesn't belong to a broadcaster reviewed in this discord.", true)
+			return
+		}
+		log.WithError(err).Error("mongo")
+		respond(s, i, "Internal server error. Please try again later.", true)
+		return
+	}
+	if appeal.Status != mongo.AppealPending {
+		respond(s, i, "That appeal was already resolved.", true)
+		return
+	}
+
+	status := mongo.AppealDenied
+	if parts[0] == "approve" {
+		status = mongo.AppealApproved
+	}
+
+	// The appeal is claimed before twitch is called, so two moderators can't resolve it at the same time.
+	now := time.Now()
+	res, err := mongo.Database.Collection("appeals").UpdateOne(context.Background(), bson.M{
+		"_id":    appeal.ID,
+		"status": mongo.AppealPending,
+	}, bson.M{
+		"$set": bson.M{
+			"status":      status,
+			"response":    response,
+			"resolved_by": i.Member.User.ID,
+			"resolved_at": now,
+		},
+	})
+	if err != nil {
+		log.WithError(err).Error("mongo")
+		respond(s, i, "Internal server error. Please try again later.", true)
+		return
+	}
+	if res.ModifiedCount == 0 {
+		respond(s, i, "That appeal was already resolved.", true)
+		return
+	}
+
+	if status == mongo.AppealApproved {
+		// A user who was already unbanned on twitch only needs the appeal resolved.
+		if err := moderate(context.Background(), g.ID, i.Member.User.ID, appeal.BroadcasterID, appeal.UserID, kindUnban, 0, ""); err != nil && !isNotBannedError(err) {
+			msg := moderationErrorMessage(err)
+			if _, err := mongo.Database.Collection("appeals").UpdateOne(context.Background(), bson.M{
+				"_id":         appeal.ID,
+				"status":      status,
+				"resolved_by": i.Member.User.ID,
+			}, bson.M{
+				"$set":   bson.M{"status": mongo.AppealPending},
+				"$unset": bson.M{"response": "", "resolved_by": "", "resolved_at": ""},
+			}); err != nil {
+				log.WithError(err).Error("mongo")
+				respondf(s, i, true, "%s The appeal couldn't be set back to pending, it stays approved although `%s` is still banned.", tr(guildLanguage(g.ID), msg), strings.ReplaceAll(appeal.UserName, "`", ""))
+				return
+			}
+			respond(s, i, msg, true)
+			return
+		}
+	}
+
+	appeal.Status = status
+	appeal.Response = response
+	appeal.ResolvedBy = i.Member.User.ID
+	appeal.ResolvedAt = &now
+
+	for _, m := range appeal.Messages {
+		embed := appealEmbed(appeal, channelLanguage(s, m.ChannelID))
+		if err := channelMessageEditComponents(s, m.ChannelID, m.MessageID, embed, []*component{}); err != nil {
+			log.WithError(err).WithField("message", m).Error("discord")
+		}
+	}
+
+	if status == mongo.AppealApproved {
+		respondf(s, i, true, "Approved the appeal, `%s` was unbanned from #%s.", strings.ReplaceAll(appeal.UserName, "`", ""), appeal.BroadcasterUserName)
+	} else {
+		respond(s, i, "Denied the appeal.", true)
+	}
+}
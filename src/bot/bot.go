@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"math"
-	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -15,17 +14,19 @@ import (
 	"github.com/troydota/modlogs/src/configure"
 	"github.com/troydota/modlogs/src/mongo"
 	"github.com/troydota/modlogs/src/redis"
+	"github.com/troydota/modlogs/src/sinks"
 	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type Command func(b *Bot, m *discordgo.Message) error
 
 type WebhookRequest struct {
+	ID                  string
 	BroadcasterID       string
 	BroadcasterUserName string
 	ModeratorUserName   string
 	ModeratorID         string
+	UserID              string
 	UserName            string
 	Reason              string
 	Action              string
@@ -48,84 +49,20 @@ func (c *cmdWrapper) Delete() error {
 type Bot struct {
 	conn *discordgo.Session
 
-	commands []*cmdWrapper
-	stopped  chan struct{}
-	limiter  *rateLimiter
+	commands   []*cmdWrapper
+	stopped    chan struct{}
+	limiter    *rateLimiter
+	webhookMtx sync.Mutex
 }
 
+// validationWrapper only runs the command for modlogs managers.
 var validationWrapper = func(next func(s *discordgo.Session, i *discordgo.InteractionCreate, g *discordgo.Guild)) func(s *discordgo.Session, i *discordgo.InteractionCreate) {
-	return func(s *discordgo.Session, i *discordgo.InteractionCreate) {
-		var valid bool
-		var guild *discordgo.Guild
-
-		for _, g := range s.State.Guilds {
-			if g.ID == i.GuildID {
-				guild = g
-				break
-			}
-		}
+	return permissionWrapper(permissionManage, next)
+}
 
-		if guild == nil {
-			err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-				Type: discordgo.InteractionResponseChannelMessageWithSource,
-				Data: &discordgo.InteractionApplicationCommandResponseData{
-					Content: "Internal Server Error. Please try again later...",
-					// Makes the response ephemeral https://discord.com/developers/docs/interactions/slash-commands#interaction-response
-					Flags: 64,
-				},
-			})
-			if err != nil {
-				log.WithError(err).Error("discord")
-			}
-			return
-		} else {
-			valid = i.Member.User.ID == guild.OwnerID
-			if !valid {
-			admins:
-				for _, u := range configure.Config.GetStringSlice("admins") {
-					if u == i.Member.User.ID {
-						valid = true
-						break admins
-					}
-				}
-				if !valid {
-				guild:
-					for _, r := range guild.Roles {
-						found := false
-					member:
-						for _, m := range i.Member.Roles {
-							if m == r.ID {
-								found = true
-								break member
-							}
-						}
-						if !found {
-							continue guild
-						}
-						if r.Permissions&discordgo.PermissionAdministrator != 0 {
-							valid = true
-							break guild
-						}
-					}
-				}
-			}
-		}
-		if valid {
-			next(s, i, guild)
-		} else {
-			err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-				Type: discordgo.InteractionResponseChannelMessageWithSource,
-				Data: &discordgo.InteractionApplicationCommandResponseData{
-					Content: "You do not have permission to execute that command.",
-					// Makes the response ephemeral https://discord.com/developers/docs/interactions/slash-commands#interaction-response
-					Flags: 64,
-				},
-			})
-			if err != nil {
-				log.WithError(err).Error("discord")
-			}
-		}
-	}
+// readWrapper runs the command for anyone who can read the modlogs configuration.
+var readWrapper = func(next func(s *discordgo.Session, i *discordgo.InteractionCreate, g *discordgo.Guild)) func(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	return permissionWrapper(permissionRead, next)
 }
 
 var (
@@ -139,7 +76,13 @@ var (
 					Type:        discordgo.ApplicationCommandOptionString,
 					Name:        "token",
 					Description: "Token from the login.",
-					Required:    true,
+					Required:    false,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "broadcaster",
+					Description: "Name of the twitch streamer to send a login link to, instead of a token.",
+					Required:    false,
 				},
 				{
 					Type:        discordgo.ApplicationCommandOptionBoolean,
@@ -153,6 +96,12 @@ var (
 					Description: "Text channel for logging.",
 					Required:    false,
 				},
+				{
+					Type:        discordgo.ApplicationCommandOptionBoolean,
+					Name:        "webhook",
+					Description: "Post the logs through a channel webhook instead of the bot.",
+					Required:    false,
+				},
 			},
 		},
 		{
@@ -185,57 +134,59 @@ var (
 				},
 			},
 		},
-		{
-			Name:        "ignore",
-			Description: "Ignore a user, such as a bot.",
-			Options: []*discordgo.ApplicationCommandOption{
-				{
-					Type:        discordgo.ApplicationCommandOptionString,
-					Name:        "user",
-					Description: "The id or username of the twitch account.",
-					Required:    true,
-				},
-			},
-		},
-		{
-			Name:        "unignore",
-			Description: "Unignore a user that was previously ignored",
-			Options: []*discordgo.ApplicationCommandOption{
-				{
-					Type:        discordgo.ApplicationCommandOptionString,
-					Name:        "user",
-					Description: "The id or username of the twitch account.",
-					Required:    true,
-				},
-			},
-		},
-		{
-			Name:        "ignored",
-			Description: "Shows a list of ignored ids.",
-		},
+		ignoreCommand,
+		unignoreCommand,
+		ignoredCommand,
 		{
 			Name:        "link",
 			Description: "Responds with the invite link and the login link.",
 		},
+		sinkCommand,
+		digestCommand,
+		statsCommand,
+		exportCommand,
+		permissionsCommand,
+		alertsCommand,
+		crossbansCommand,
+		expiryCommand,
+		noteCommand,
+		historyCommand,
+		searchCommand,
+		twitchCommand,
+		appealsCommand,
+		profilesCommand,
+		chatContextCommand,
+		deletionsCommand,
+		languageCommand,
 	}
 	commandHandlers = map[string]func(s *discordgo.Session, i *discordgo.InteractionCreate){
 		"add": validationWrapper(func(s *discordgo.Session, i *discordgo.InteractionCreate, g *discordgo.Guild) {
-			token := i.Data.Options[0].StringValue()
+			var token string
+			var broadcaster string
 			var channel *discordgo.Channel
 			mode := mongo.ModeMinimal
+			delivery := mongo.DeliveryBot
 
 			for _, o := range i.Data.Options {
-				if o.Name == "minimal" {
+				if o.Name == "token" {
+					token = o.StringValue()
+				} else if o.Name == "broadcaster" {
+					broadcaster = strings.ToLower(strings.TrimSpace(o.StringValue()))
+				} else if o.Name == "minimal" {
 					if !o.BoolValue() {
 						mode = mongo.ModeEmbed
 					}
+				} else if o.Name == "webhook" {
+					if o.BoolValue() {
+						delivery = mongo.DeliveryWebhook
+					}
 				} else if o.Name == "channel" {
 					channel = o.ChannelValue(s)
 					if channel != nil && channel.Type != discordgo.ChannelTypeGuildText {
 						err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 							Type: discordgo.InteractionResponseChannelMessageWithSource,
 							Data: &discordgo.InteractionApplicationCommandResponseData{
-								Content: "Logs can only be outputted into a text channel.",
+								Content: tr(guildLanguage(i.GuildID), "Logs can only be outputted into a text channel."),
 								// Makes the response ephemeral https://discord.com/developers/docs/interactions/slash-commands#interaction-response
 								Flags: 64,
 							},
@@ -261,7 +212,7 @@ var (
 				err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 					Type: discordgo.InteractionResponseChannelMessageWithSource,
 					Data: &discordgo.InteractionApplicationCommandResponseData{
-						Content: "Internal server error occured.",
+						Content: tr(guildLanguage(i.GuildID), "Internal server error occured."),
 						// Makes the response ephemeral https://discord.com/developers/docs/interactions/slash-commands#interaction-response
 						Flags: 64,
 					},
@@ -269,9 +220,34 @@ var (
 				if err != nil {
 					log.WithError(err).Error("discord")
 				}
+				return
+			}
+
+			if token == "" {
+				if broadcaster == "" {
+					respond(s, i, "Please provide the token from the login page, or the broadcaster to send a login link to.", true)
+					return
+				}
+
+				linkID, err := createLink(&Link{
+					GuildID:     g.ID,
+					ChannelID:   channel.ID,
+					Mode:        mode,
+					Delivery:    delivery,
+					Broadcaster: broadcaster,
+					RequestedBy: i.Member.User.ID,
+				})
+				if err != nil {
+					log.WithError(err).Error("redis")
+					respond(s, i, "Internal server error. Please try again later.", true)
+					return
+				}
+
+				respondf(s, i, false, "<https://twitch.tv/%s> can start logging into %s by logging in at <%s/login?link=%s>, the link will expire in 24 hours.", broadcaster, channel.Mention(), configure.Config.GetString("website_url"), linkID)
+				return
 			}
 
-			userID, err := redis.AuthTokenValues(context.Background(), token)
+			tokenValue, err := redis.AuthTokenValues(context.Background(), token)
 			if err != nil {
 				msg := "Internal server error. Please try again later."
 				if err == redis.ErrNil {
@@ -282,7 +258,7 @@ var (
 				err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 					Type: discordgo.InteractionResponseChannelMessageWithSource,
 					Data: &discordgo.InteractionApplicationCommandResponseData{
-						Content: msg,
+						Content: tr(guildLanguage(i.GuildID), msg),
 						// Makes the response ephemeral https://discord.com/developers/docs/interactions/slash-commands#interaction-response
 						Flags: 64,
 					},
@@ -293,63 +269,20 @@ var (
 				return
 			}
 
-			user := &mongo.User{}
-
-			filterOr := bson.M{
-				"id": userID,
-			}
-
-			res := mongo.Database.Collection("users").FindOne(context.Background(), filterOr)
+			// Tokens made by moderators hold the broadcaster id followed by the moderator id.
+			tokenParts := strings.SplitN(tokenValue, " ", 2)
+			userID := tokenParts[0]
+			authorizedBy := tokenParts[len(tokenParts)-1]
 
-			err = res.Err()
-			if err == nil {
-				err = res.Decode(user)
-			}
-			if err == mongo.ErrNoDocuments {
-				err = nil
-				users, err := api.GetUsers(context.Background(), "", []string{userID}, nil)
+			users, err := resolveUsers([]string{userID}, nil)
+			user, ok := users[userID]
+			if !ok {
 				if err != nil {
 					log.WithError(err).Error("api")
 					err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 						Type: discordgo.InteractionResponseChannelMessageWithSource,
 						Data: &discordgo.InteractionApplicationCommandResponseData{
-							Content: "Internal server error. Please try again later.",
-							// Makes the response ephemeral https://discord.com/developers/docs/interactions/slash-commands#interaction-response
-							Flags: 64,
-						},
-					})
-					if err != nil {
-						log.WithError(err).Error("discord")
-					}
-					return
-				}
-				if len(users) == 0 {
-					err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-						Type: discordgo.InteractionResponseChannelMessageWithSource,
-						Data: &discordgo.InteractionApplicationCommandResponseData{
-							Content: "The specified broadcaster does not exist.",
-							// Makes the response ephemeral https://discord.com/developers/docs/interactions/slash-commands#interaction-response
-							Flags: 64,
-						},
-					})
-					if err != nil {
-						log.WithError(err).Error("discord")
-					}
-					return
-				}
-				u := users[0]
-				opts := options.Update().SetUpsert(true)
-				user = &mongo.User{
-					ID:    u.ID,
-					Name:  u.DisplayName,
-					Login: u.Login,
-				}
-				if _, err := mongo.Database.Collection("users").UpdateOne(context.Background(), filterOr, bson.M{"$set": user}, opts); err != nil {
-					log.WithError(err).Error("mongo")
-					err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-						Type: discordgo.InteractionResponseChannelMessageWithSource,
-						Data: &discordgo.InteractionApplicationCommandResponseData{
-							Content: "Internal server error. Please try again later.",
+							Content: tr(guildLanguage(i.GuildID), "Internal server error. Please try again later."),
 							// Makes the response ephemeral https://discord.com/developers/docs/interactions/slash-commands#interaction-response
 							Flags: 64,
 						},
@@ -359,46 +292,10 @@ var (
 					}
 					return
 				}
-			}
-
-			if err != nil {
-				log.WithError(err).Error("mongo")
-				err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-					Type: discordgo.InteractionResponseChannelMessageWithSource,
-					Data: &discordgo.InteractionApplicationCommandResponseData{
-						Content: "Internal server error. Please try again later.",
-						// Makes the response ephemeral https://discord.com/developers/docs/interactions/slash-commands#interaction-response
-						Flags: 64,
-					},
-				})
-				if err != nil {
-					log.WithError(err).Error("discord")
-				}
-				return
-			}
-
-			filter := bson.M{
-				"channel_id":  channel.ID,
-				"guild_id":    g.ID,
-				"streamer_id": user.ID,
-			}
-
-			update := bson.M{
-				"$set": bson.M{
-					"channel_id":  channel.ID,
-					"guild_id":    g.ID,
-					"streamer_id": user.ID,
-					"mode":        mode,
-				},
-			}
-
-			updateResp, err := mongo.Database.Collection("hooks").UpdateOne(context.Background(), filter, update)
-			if err != nil {
-				log.WithError(err).Error("mongo")
 				err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 					Type: discordgo.InteractionResponseChannelMessageWithSource,
 					Data: &discordgo.InteractionApplicationCommandResponseData{
-						Content: "Internal server error. Please try again later.",
+						Content: tr(guildLanguage(i.GuildID), "The specified broadcaster does not exist."),
 						// Makes the response ephemeral https://discord.com/developers/docs/interactions/slash-commands#interaction-response
 						Flags: 64,
 					},
@@ -409,130 +306,33 @@ var (
 				return
 			}
 
-			if updateResp.MatchedCount == 1 {
-				err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-					Type: discordgo.InteractionResponseChannelMessageWithSource,
-					Data: &discordgo.InteractionApplicationCommandResponseData{
-						Content: fmt.Sprintf("ModLogs hook updated for <https://twitch.tv/%s>, into %s", user.Login, channel.Mention()),
-					},
-				})
-				if err != nil {
-					log.WithError(err).Error("discord")
-				}
-				return
+			hook := &mongo.Hook{
+				GuildID:      g.ID,
+				ChannelID:    channel.ID,
+				StreamerID:   user.ID,
+				Mode:         mode,
+				Delivery:     delivery,
+				GuildName:    g.Name,
+				ChannelName:  channel.Name,
+				AuthorizedBy: authorizedBy,
+				AddedBy:      i.Member.User.ID,
 			}
 
-			count, err := mongo.Database.Collection("hooks").CountDocuments(context.Background(), bson.M{
-				"guild_id": g.ID,
-			})
+			updated, err := createHook(s, hook, user)
 			if err != nil {
-				log.WithError(err).Error("mongo")
-				err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-					Type: discordgo.InteractionResponseChannelMessageWithSource,
-					Data: &discordgo.InteractionApplicationCommandResponseData{
-						Content: "Internal server error. Please try again later.",
-						// Makes the response ephemeral https://discord.com/developers/docs/interactions/slash-commands#interaction-response
-						Flags: 64,
-					},
-				})
-				if err != nil {
-					log.WithError(err).Error("discord")
-				}
-				return
-			}
-
-			max := configure.Config.GetInt64("max_hooks_per_guild")
-			if max != -1 && count >= max {
-				err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-					Type: discordgo.InteractionResponseChannelMessageWithSource,
-					Data: &discordgo.InteractionApplicationCommandResponseData{
-						Content: fmt.Sprintf("There are too many hooks in this discord. (%v/%v)", count, configure.Config.GetInt64("max_hooks_per_guild")),
-						// Makes the response ephemeral https://discord.com/developers/docs/interactions/slash-commands#interaction-response
-						Flags: 64,
-					},
-				})
-				if err != nil {
-					log.WithError(err).Error("discord")
-				}
+				respond(s, i, hookErrorMessage(err, guildLanguage(g.ID)), true)
 				return
 			}
 
-			opts := options.Update().SetUpsert(true)
-
-			result, err := mongo.Database.Collection("hooks").UpdateOne(context.Background(), filter, update, opts)
-			if err != nil {
-				log.WithError(err).Error("mongo")
-				err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-					Type: discordgo.InteractionResponseChannelMessageWithSource,
-					Data: &discordgo.InteractionApplicationCommandResponseData{
-						Content: "Internal server error. Please try again later.",
-						// Makes the response ephemeral https://discord.com/developers/docs/interactions/slash-commands#interaction-response
-						Flags: 64,
-					},
-				})
-				if err != nil {
-					log.WithError(err).Error("discord")
-				}
-				return
-			}
-			if result.UpsertedCount == 1 {
-				val, err := redis.Client.Incr(context.Background(), fmt.Sprintf("streamers:%s", user.ID)).Result()
-				if err != nil {
-					log.WithError(err).Error("redis")
-					err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-						Type: discordgo.InteractionResponseChannelMessageWithSource,
-						Data: &discordgo.InteractionApplicationCommandResponseData{
-							Content: "Internal server error. Please try again later.",
-							// Makes the response ephemeral https://discord.com/developers/docs/interactions/slash-commands#interaction-response
-							Flags: 64,
-						},
-					})
-					if err != nil {
-						log.WithError(err).Error("discord")
-					}
-					return
-				}
-				if val == 1 {
-					err := api.CreateWebhooks(context.Background(), user.ID)
-					if err != nil {
-						log.WithError(err).Error("api")
-						err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-							Type: discordgo.InteractionResponseChannelMessageWithSource,
-							Data: &discordgo.InteractionApplicationCommandResponseData{
-								Content: "Internal server error. Please try again later.",
-								// Makes the response ephemeral https://discord.com/developers/docs/interactions/slash-commands#interaction-response
-								Flags: 64,
-							},
-						})
-						if err != nil {
-							log.WithError(err).Error("discord")
-						}
-						err = redis.Client.Decr(context.Background(), fmt.Sprintf("streamers:%s", user.ID)).Err()
-						if err != nil {
-							log.WithError(err).Error("redis")
-						}
-						_, err = mongo.Database.Collection("hooks").DeleteOne(context.Background(), bson.M{
-							"_id": result.UpsertedID,
-						})
-						if err != nil {
-							log.WithError(err).Error("mongo")
-						}
-						return
-					}
-				}
+			lang := guildLanguage(g.ID)
+			action := tr(lang, "added")
+			if updated {
+				action = tr(lang, "updated")
 			}
 
-			err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-				Type: discordgo.InteractionResponseChannelMessageWithSource,
-				Data: &discordgo.InteractionApplicationCommandResponseData{
-					Content: fmt.Sprintf("ModLogs hook added for <https://twitch.tv/%s>, into %s", user.Login, channel.Mention()),
-				},
-			})
-			if err != nil {
-				log.WithError(err).Error("discord")
-			}
+			respondf(s, i, false, "ModLogs hook %s for <https://twitch.tv/%s>, into %s", action, user.Login, channel.Mention())
 		}),
-		"list": validationWrapper(func(s *discordgo.Session, i *discordgo.InteractionCreate, g *discordgo.Guild) {
+		"list": readWrapper(func(s *discordgo.Session, i *discordgo.InteractionCreate, g *discordgo.Guild) {
 			var channel *discordgo.Channel
 			for _, o := range i.Data.Options {
 				if o.Name == "channel" {
@@ -541,7 +341,7 @@ var (
 						err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 							Type: discordgo.InteractionResponseChannelMessageWithSource,
 							Data: &discordgo.InteractionApplicationCommandResponseData{
-								Content: "Please select a valid channel.",
+								Content: tr(guildLanguage(i.GuildID), "Please select a valid channel."),
 								// Makes the response ephemeral https://discord.com/developers/docs/interactions/slash-commands#interaction-response
 								Flags: 64,
 							},
@@ -575,7 +375,7 @@ var (
 				err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 					Type: discordgo.InteractionResponseChannelMessageWithSource,
 					Data: &discordgo.InteractionApplicationCommandResponseData{
-						Content: "Internal Server Error.",
+						Content: tr(guildLanguage(i.GuildID), "Internal Server Error."),
 						// Makes the response ephemeral https://discord.com/developers/docs/interactions/slash-commands#interaction-response
 						Flags: 64,
 					},
@@ -597,94 +397,35 @@ var (
 				streamerIDs = append(streamerIDs, k)
 			}
 
-			opts := options.Update().SetUpsert(true)
-
-			filter = bson.M{
-				"id": bson.M{
-					"$in": streamerIDs,
-				},
+			resolved, err := resolveUsers(streamerIDs, nil)
+			if err != nil {
+				log.WithError(err).Error("api")
+				if len(resolved) == 0 {
+					err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+						Type: discordgo.InteractionResponseChannelMessageWithSource,
+						Data: &discordgo.InteractionApplicationCommandResponseData{
+							Content: tr(guildLanguage(i.GuildID), "Internal server error. Please try again later."),
+							// Makes the response ephemeral https://discord.com/developers/docs/interactions/slash-commands#interaction-response
+							Flags: 64,
+						},
+					})
+					if err != nil {
+						log.WithError(err).Error("discord")
+					}
+					return
+				}
 			}
 
 			users := []*mongo.User{}
+			for _, id := range streamerIDs {
+				if u, ok := resolved[id]; ok {
+					users = append(users, u)
+				}
+			}
 
-			cur, err = mongo.Database.Collection("users").Find(context.Background(), filter)
-			if err == nil {
-				err = cur.All(context.Background(), &users)
-			}
-			if err != nil {
-				log.WithError(err).Error("mongo")
-				err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-					Type: discordgo.InteractionResponseChannelMessageWithSource,
-					Data: &discordgo.InteractionApplicationCommandResponseData{
-						Content: "Internal server error. Please try again later.",
-						// Makes the response ephemeral https://discord.com/developers/docs/interactions/slash-commands#interaction-response
-						Flags: 64,
-					},
-				})
-				if err != nil {
-					log.WithError(err).Error("discord")
-				}
-				return
-			}
-
-			if len(users) != len(streamerIDs) {
-				// find missing ones
-				missingIDs := []string{}
-				for _, id := range streamerIDs {
-					found := false
-					for _, u := range users {
-						if u.ID == id {
-							found = true
-							break
-						}
-					}
-					if !found {
-						missingIDs = append(missingIDs, id)
-					}
-				}
-				apiUsers, err := api.GetUsers(context.Background(), "", missingIDs, nil)
-				if err != nil {
-					log.WithError(err).Error("api")
-					err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-						Type: discordgo.InteractionResponseChannelMessageWithSource,
-						Data: &discordgo.InteractionApplicationCommandResponseData{
-							Content: "Internal server error. Please try again later.",
-							// Makes the response ephemeral https://discord.com/developers/docs/interactions/slash-commands#interaction-response
-							Flags: 64,
-						},
-					})
-					if err != nil {
-						log.WithError(err).Error("discord")
-					}
-				}
-				wg := sync.WaitGroup{}
-				wg.Add(len(apiUsers))
-
-				for _, u := range apiUsers {
-					go func(u api.TwitchUser) {
-						defer wg.Done()
-						user := &mongo.User{
-							ID:    u.ID,
-							Name:  u.DisplayName,
-							Login: u.Login,
-						}
-						users = append(users, user)
-						if _, err := mongo.Database.Collection("users").UpdateOne(context.Background(), bson.M{
-							"$or": bson.A{
-								bson.M{"id": u.ID},
-								bson.M{"login": u.Login},
-							},
-						}, bson.M{"$set": user}, opts); err != nil {
-							log.WithError(err).Error("mongo")
-						}
-					}(u)
-				}
-				wg.Done()
-			}
-
-			usrStr := make([]string, len(users))
-			for i, user := range users {
-				usrStr[i] = user.Name
+			usrStr := make([]string, len(users))
+			for i, user := range users {
+				usrStr[i] = user.Name
 			}
 
 			lines := []string{}
@@ -706,403 +447,80 @@ var (
 					if h.Mode == mongo.ModeEmbed {
 						mode = "embed"
 					}
-					channels = append(channels, fmt.Sprintf("<#%s> - %s", h.ChannelID, mode))
-				}
-				lines = append(lines, fmt.Sprintf(`<https://twitch.tv/%s> -> %s`, v.Login, strings.Join(channels, ", ")))
-			}
-
-			if len(lines) == 0 {
-				lines = append(lines, "No hooks were found")
-			}
-
-			err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-				Type: discordgo.InteractionResponseChannelMessageWithSource,
-				Data: &discordgo.InteractionApplicationCommandResponseData{
-					Content: strings.Join(lines, "\n"),
-				},
-			})
-			if err != nil {
-				log.WithError(err).Error("discord")
-			}
-		}),
-		"delete": validationWrapper(func(s *discordgo.Session, i *discordgo.InteractionCreate, g *discordgo.Guild) {
-			var broadcaster string
-			var channel *discordgo.Channel
-
-			for _, o := range i.Data.Options {
-				if o.Name == "broadcaster" {
-					broadcaster = o.StringValue()
-				}
-				if o.Name == "channel" {
-					channel = o.ChannelValue(s)
-					if channel != nil && channel.Type != discordgo.ChannelTypeGuildText {
-						err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-							Type: discordgo.InteractionResponseChannelMessageWithSource,
-							Data: &discordgo.InteractionApplicationCommandResponseData{
-								Content: "Logs can only be outputted into a text channel.",
-								// Makes the response ephemeral https://discord.com/developers/docs/interactions/slash-commands#interaction-response
-								Flags: 64,
-							},
-						})
-						if err != nil {
-							log.WithError(err).Error("discord")
-						}
-						return
+					if h.Delivery == mongo.DeliveryWebhook {
+						mode = fmt.Sprintf("%s (webhook)", mode)
 					}
-				}
-			}
-
-			filter := bson.M{
-				"guild_id":    g.ID,
-				"streamer_id": broadcaster,
-			}
-
-			var channelID string
-			if channel != nil {
-				channelID = channel.ID
-				filter["channel_id"] = channelID
-			}
-
-			broadcaster = strings.ToLower(broadcaster)
-
-			delres, err := mongo.Database.Collection("hooks").DeleteMany(context.Background(), filter)
-			if err != nil {
-				log.WithError(err).Error("mongo")
-				err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-					Type: discordgo.InteractionResponseChannelMessageWithSource,
-					Data: &discordgo.InteractionApplicationCommandResponseData{
-						Content: "Internal server error. Please try again later.",
-						// Makes the response ephemeral https://discord.com/developers/docs/interactions/slash-commands#interaction-response
-						Flags: 64,
-					},
-				})
-				if err != nil {
-					log.WithError(err).Error("discord")
-				}
-				return
-			}
-
-			if delres.DeletedCount > 0 {
-				plural := " has"
-				if delres.DeletedCount > 1 {
-					plural = "s have"
-				}
-				err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-					Type: discordgo.InteractionResponseChannelMessageWithSource,
-					Data: &discordgo.InteractionApplicationCommandResponseData{
-						Content: fmt.Sprintf("The hook%s been removed.", plural),
-					},
-				})
-				if err != nil {
-					log.WithError(err).Error("discord")
-				}
-				return
-			}
-
-			user := &mongo.User{}
-
-			filterOr := bson.M{
-				"$or": bson.A{
-					bson.M{"id": broadcaster},
-					bson.M{"login": broadcaster},
-				},
-			}
-
-			res := mongo.Database.Collection("users").FindOne(context.Background(), filterOr)
-
-			err = res.Err()
-			if err == nil {
-				err = res.Decode(user)
-			}
-			if err == mongo.ErrNoDocuments {
-				err = nil
-				ids := []string{}
-				if _, err := strconv.ParseInt(broadcaster, 10, 64); err == nil {
-					ids = append(ids, broadcaster)
-				}
-
-				users, err := api.GetUsers(context.Background(), "", ids, []string{broadcaster})
-				if err != nil {
-					log.WithError(err).Error("api")
-					err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-						Type: discordgo.InteractionResponseChannelMessageWithSource,
-						Data: &discordgo.InteractionApplicationCommandResponseData{
-							Content: "Internal server error. Please try again later.",
-							// Makes the response ephemeral https://discord.com/developers/docs/interactions/slash-commands#interaction-response
-							Flags: 64,
-						},
-					})
-					if err != nil {
-						log.WithError(err).Error("discord")
-					}
-					return
-				}
-				if len(users) == 0 {
-					err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-						Type: discordgo.InteractionResponseChannelMessageWithSource,
-						Data: &discordgo.InteractionApplicationCommandResponseData{
-							Content: "The specified user does not exist.",
-							// Makes the response ephemeral https://discord.com/developers/docs/interactions/slash-commands#interaction-response
-							Flags: 64,
-						},
-					})
-					if err != nil {
-						log.WithError(err).Error("discord")
-					}
-					return
-				}
-				u := users[0]
-				opts := options.Update().SetUpsert(true)
-				user = &mongo.User{
-					ID:    u.ID,
-					Name:  u.DisplayName,
-					Login: u.Login,
-				}
-				if _, err := mongo.Database.Collection("users").UpdateOne(context.Background(), filterOr, bson.M{"$set": user}, opts); err != nil {
-					log.WithError(err).Error("mongo")
-					err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-						Type: discordgo.InteractionResponseChannelMessageWithSource,
-						Data: &discordgo.InteractionApplicationCommandResponseData{
-							Content: "Internal server error. Please try again later.",
-							// Makes the response ephemeral https://discord.com/developers/docs/interactions/slash-commands#interaction-response
-							Flags: 64,
-						},
-					})
-					if err != nil {
-						log.WithError(err).Error("discord")
-					}
-					return
-				}
-			}
-
-			if err != nil {
-				log.WithError(err).Error("mongo")
-				err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-					Type: discordgo.InteractionResponseChannelMessageWithSource,
-					Data: &discordgo.InteractionApplicationCommandResponseData{
-						Content: "Internal server error. Please try again later.",
-						// Makes the response ephemeral https://discord.com/developers/docs/interactions/slash-commands#interaction-response
-						Flags: 64,
-					},
-				})
-				if err != nil {
-					log.WithError(err).Error("discord")
-				}
-				return
-			}
-
-			filter["streamer_id"] = user.ID
-
-			delres, err = mongo.Database.Collection("hooks").DeleteMany(context.Background(), filter)
-			if err != nil {
-				log.WithError(err).Error("mongo")
-				err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-					Type: discordgo.InteractionResponseChannelMessageWithSource,
-					Data: &discordgo.InteractionApplicationCommandResponseData{
-						Content: "Internal server error. Please try again later.",
-						// Makes the response ephemeral https://discord.com/developers/docs/interactions/slash-commands#interaction-response
-						Flags: 64,
-					},
-				})
-				if err != nil {
-					log.WithError(err).Error("discord")
-				}
-				return
-			}
-
-			if delres.DeletedCount > 0 {
-				plural := " has"
-				if delres.DeletedCount > 1 {
-					plural = "s have"
-				}
-				err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-					Type: discordgo.InteractionResponseChannelMessageWithSource,
-					Data: &discordgo.InteractionApplicationCommandResponseData{
-						Content: fmt.Sprintf("The hook%s been removed.", plural),
-					},
-				})
-				if err != nil {
-					log.WithError(err).Error("discord")
-				}
-
-				val, err := redis.Client.DecrBy(context.Background(), fmt.Sprintf("streamers:%s", user.ID), delres.DeletedCount).Result()
-				if err != nil {
-					log.WithError(err).Error("redis")
-				} else if val == 0 {
-					if err := api.RevokeWebhook(context.Background(), user.ID); err != nil {
-						log.WithError(err).Error("api")
-					}
-				}
-				return
-			}
-
-			err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-				Type: discordgo.InteractionResponseChannelMessageWithSource,
-				Data: &discordgo.InteractionApplicationCommandResponseData{
-					Content: "That hook doesn't exist",
-					// Makes the response ephemeral https://discord.com/developers/docs/interactions/slash-commands#interaction-response
-					Flags: 64,
-				},
-			})
-			if err != nil {
-				log.WithError(err).Error("discord")
-			}
-		}),
-		"ignore": validationWrapper(func(s *discordgo.Session, i *discordgo.InteractionCreate, g *discordgo.Guild) {
-			var userInput string
-
-			for _, o := range i.Data.Options {
-				if o.Name == "user" {
-					userInput = o.StringValue()
-				}
-			}
-
-			if userInput == "" {
-				err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-					Type: discordgo.InteractionResponseChannelMessageWithSource,
-					Data: &discordgo.InteractionApplicationCommandResponseData{
-						Content: "Please enter a valid user.",
-						// Makes the response ephemeral https://discord.com/developers/docs/interactions/slash-commands#interaction-response
-						Flags: 64,
-					},
-				})
-				if err != nil {
-					log.WithError(err).Error("discord")
-				}
-				return
-			}
-
-			user := &mongo.User{}
-
-			filterOr := bson.M{
-				"$or": bson.A{
-					bson.M{"id": userInput},
-					bson.M{"login": userInput},
-				},
-			}
-
-			res := mongo.Database.Collection("users").FindOne(context.Background(), filterOr)
-
-			err := res.Err()
-			if err == nil {
-				err = res.Decode(user)
-			}
-			if err == mongo.ErrNoDocuments {
-				err = nil
-				ids := []string{}
-				if _, err := strconv.ParseInt(userInput, 10, 64); err == nil {
-					ids = append(ids, userInput)
-				}
-
-				users, err := api.GetUsers(context.Background(), "", ids, []string{userInput})
-				if err != nil {
-					log.WithError(err).Error("api")
-					err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-						Type: discordgo.InteractionResponseChannelMessageWithSource,
-						Data: &discordgo.InteractionApplicationCommandResponseData{
-							Content: "Internal server error. Please try again later.",
-							// Makes the response ephemeral https://discord.com/developers/docs/interactions/slash-commands#interaction-response
-							Flags: 64,
-						},
-					})
-					if err != nil {
-						log.WithError(err).Error("discord")
-					}
-					return
-				}
-				if len(users) == 0 {
-					err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-						Type: discordgo.InteractionResponseChannelMessageWithSource,
-						Data: &discordgo.InteractionApplicationCommandResponseData{
-							Content: "The specified user does not exist.",
-							// Makes the response ephemeral https://discord.com/developers/docs/interactions/slash-commands#interaction-response
-							Flags: 64,
-						},
-					})
-					if err != nil {
-						log.WithError(err).Error("discord")
-					}
-					return
-				}
-				u := users[0]
-				opts := options.Update().SetUpsert(true)
-				user = &mongo.User{
-					ID:    u.ID,
-					Name:  u.DisplayName,
-					Login: u.Login,
-				}
-				if _, err := mongo.Database.Collection("users").UpdateOne(context.Background(), filterOr, bson.M{"$set": user}, opts); err != nil {
-					log.WithError(err).Error("mongo")
-					err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-						Type: discordgo.InteractionResponseChannelMessageWithSource,
-						Data: &discordgo.InteractionApplicationCommandResponseData{
-							Content: "Internal server error. Please try again later.",
-							// Makes the response ephemeral https://discord.com/developers/docs/interactions/slash-commands#interaction-response
-							Flags: 64,
-						},
-					})
-					if err != nil {
-						log.WithError(err).Error("discord")
+					if h.AddedBy != "" {
+						mode = fmt.Sprintf("%s, added by <@%s>", mode, h.AddedBy)
 					}
-					return
+					channels = append(channels, fmt.Sprintf("<#%s> - %s", h.ChannelID, mode))
 				}
+				lines = append(lines, fmt.Sprintf(`<https://twitch.tv/%s> -> %s`, v.Login, strings.Join(channels, ", ")))
 			}
 
-			if err != nil {
-				log.WithError(err).Error("mongo")
-				err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-					Type: discordgo.InteractionResponseChannelMessageWithSource,
-					Data: &discordgo.InteractionApplicationCommandResponseData{
-						Content: "Internal server error. Please try again later.",
-						// Makes the response ephemeral https://discord.com/developers/docs/interactions/slash-commands#interaction-response
-						Flags: 64,
-					},
-				})
-				if err != nil {
-					log.WithError(err).Error("discord")
-				}
-				return
+			if len(lines) == 0 {
+				lines = append(lines, "No hooks were found")
 			}
 
-			if err := redis.Client.SAdd(context.Background(), fmt.Sprintf("ignored-users:%s", g.ID), user.ID).Err(); err != nil {
-				log.WithError(err).Error("redis")
-				err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-					Type: discordgo.InteractionResponseChannelMessageWithSource,
-					Data: &discordgo.InteractionApplicationCommandResponseData{
-						Content: "Internal server error.",
-						// Makes the response ephemeral https://discord.com/developers/docs/interactions/slash-commands#interaction-response
-						Flags: 64,
-					},
-				})
-				if err != nil {
-					log.WithError(err).Error("discord")
-				}
-				return
-			}
 			err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 				Type: discordgo.InteractionResponseChannelMessageWithSource,
 				Data: &discordgo.InteractionApplicationCommandResponseData{
-					Content: fmt.Sprintf("Successfully ignored `%s`.", user.Name),
+					Content: strings.Join(lines, "\n"),
 				},
 			})
 			if err != nil {
 				log.WithError(err).Error("discord")
 			}
 		}),
-		"unignore": validationWrapper(func(s *discordgo.Session, i *discordgo.InteractionCreate, g *discordgo.Guild) {
-			var userInput string
+		"delete": validationWrapper(func(s *discordgo.Session, i *discordgo.InteractionCreate, g *discordgo.Guild) {
+			var broadcaster string
+			var channel *discordgo.Channel
 
 			for _, o := range i.Data.Options {
-				if o.Name == "user" {
-					userInput = o.StringValue()
+				if o.Name == "broadcaster" {
+					broadcaster = o.StringValue()
+				}
+				if o.Name == "channel" {
+					channel = o.ChannelValue(s)
+					if channel != nil && channel.Type != discordgo.ChannelTypeGuildText {
+						err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+							Type: discordgo.InteractionResponseChannelMessageWithSource,
+							Data: &discordgo.InteractionApplicationCommandResponseData{
+								Content: tr(guildLanguage(i.GuildID), "Logs can only be outputted into a text channel."),
+								// Makes the response ephemeral https://discord.com/developers/docs/interactions/slash-commands#interaction-response
+								Flags: 64,
+							},
+						})
+						if err != nil {
+							log.WithError(err).Error("discord")
+						}
+						return
+					}
 				}
 			}
 
-			if userInput == "" {
+			filter := bson.M{
+				"guild_id":    g.ID,
+				"streamer_id": broadcaster,
+			}
+
+			var channelID string
+			if channel != nil {
+				channelID = channel.ID
+				filter["channel_id"] = channelID
+			}
+
+			broadcaster = strings.ToLower(broadcaster)
+
+			releaseWebhooks(s, filter)
+
+			delres, err := mongo.Database.Collection("hooks").DeleteMany(context.Background(), filter)
+			if err != nil {
+				log.WithError(err).Error("mongo")
 				err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 					Type: discordgo.InteractionResponseChannelMessageWithSource,
 					Data: &discordgo.InteractionApplicationCommandResponseData{
-						Content: "Please enter a valid user.",
+						Content: tr(guildLanguage(i.GuildID), "Internal server error. Please try again later."),
 						// Makes the response ephemeral https://discord.com/developers/docs/interactions/slash-commands#interaction-response
 						Flags: 64,
 					},
@@ -1113,71 +531,30 @@ var (
 				return
 			}
 
-			user := &mongo.User{}
-
-			filterOr := bson.M{
-				"$or": bson.A{
-					bson.M{"id": userInput},
-					bson.M{"login": userInput},
-				},
-			}
-
-			res := mongo.Database.Collection("users").FindOne(context.Background(), filterOr)
-
-			err := res.Err()
-			if err == nil {
-				err = res.Decode(user)
-			}
-			if err == mongo.ErrNoDocuments {
-				err = nil
-				ids := []string{}
-				if _, err := strconv.ParseInt(userInput, 10, 64); err == nil {
-					ids = append(ids, userInput)
+			if delres.DeletedCount > 0 {
+				msg := "The hook has been removed."
+				if delres.DeletedCount > 1 {
+					msg = "The hooks have been removed."
 				}
-
-				users, err := api.GetUsers(context.Background(), "", ids, []string{userInput})
+				err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+					Type: discordgo.InteractionResponseChannelMessageWithSource,
+					Data: &discordgo.InteractionApplicationCommandResponseData{
+						Content: tr(guildLanguage(i.GuildID), msg),
+					},
+				})
 				if err != nil {
-					log.WithError(err).Error("api")
-					err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-						Type: discordgo.InteractionResponseChannelMessageWithSource,
-						Data: &discordgo.InteractionApplicationCommandResponseData{
-							Content: "Internal server error. Please try again later.",
-							// Makes the response ephemeral https://discord.com/developers/docs/interactions/slash-commands#interaction-response
-							Flags: 64,
-						},
-					})
-					if err != nil {
-						log.WithError(err).Error("discord")
-					}
-					return
-				}
-				if len(users) == 0 {
-					err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-						Type: discordgo.InteractionResponseChannelMessageWithSource,
-						Data: &discordgo.InteractionApplicationCommandResponseData{
-							Content: "The specified user does not exist.",
-							// Makes the response ephemeral https://discord.com/developers/docs/interactions/slash-commands#interaction-response
-							Flags: 64,
-						},
-					})
-					if err != nil {
-						log.WithError(err).Error("discord")
-					}
-					return
-				}
-				u := users[0]
-				opts := options.Update().SetUpsert(true)
-				user = &mongo.User{
-					ID:    u.ID,
-					Name:  u.DisplayName,
-					Login: u.Login,
+					log.WithError(err).Error("discord")
 				}
-				if _, err := mongo.Database.Collection("users").UpdateOne(context.Background(), filterOr, bson.M{"$set": user}, opts); err != nil {
-					log.WithError(err).Error("mongo")
+				return
+			}
+
+			user, err := lookupUser(broadcaster)
+			if err != nil {
+				if err == errUnknownUser {
 					err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 						Type: discordgo.InteractionResponseChannelMessageWithSource,
 						Data: &discordgo.InteractionApplicationCommandResponseData{
-							Content: "Internal server error. Please try again later.",
+							Content: tr(guildLanguage(i.GuildID), "The specified user does not exist."),
 							// Makes the response ephemeral https://discord.com/developers/docs/interactions/slash-commands#interaction-response
 							Flags: 64,
 						},
@@ -1187,14 +564,11 @@ var (
 					}
 					return
 				}
-			}
-
-			if err != nil {
-				log.WithError(err).Error("mongo")
+				log.WithError(err).Error("api")
 				err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 					Type: discordgo.InteractionResponseChannelMessageWithSource,
 					Data: &discordgo.InteractionApplicationCommandResponseData{
-						Content: "Internal server error. Please try again later.",
+						Content: tr(guildLanguage(i.GuildID), "Internal server error. Please try again later."),
 						// Makes the response ephemeral https://discord.com/developers/docs/interactions/slash-commands#interaction-response
 						Flags: 64,
 					},
@@ -1205,46 +579,17 @@ var (
 				return
 			}
 
-			if err := redis.Client.SRem(context.Background(), fmt.Sprintf("ignored-users:%s", g.ID), user.ID).Err(); err != nil {
-				log.WithError(err).Error("redis")
-				err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-					Type: discordgo.InteractionResponseChannelMessageWithSource,
-					Data: &discordgo.InteractionApplicationCommandResponseData{
-						Content: "Internal server error.",
-						// Makes the response ephemeral https://discord.com/developers/docs/interactions/slash-commands#interaction-response
-						Flags: 64,
-					},
-				})
-				if err != nil {
-					log.WithError(err).Error("discord")
-				}
-				return
-			}
-			err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-				Type: discordgo.InteractionResponseChannelMessageWithSource,
-				Data: &discordgo.InteractionApplicationCommandResponseData{
-					Content: fmt.Sprintf("Successfully unignored `%s`.", user.Name),
-					// Makes the response ephemeral https://discord.com/developers/docs/interactions/slash-commands#interaction-response
-					Flags: 64,
-				},
-			})
+			filter["streamer_id"] = user.ID
+
+			releaseWebhooks(s, filter)
+
+			delres, err = mongo.Database.Collection("hooks").DeleteMany(context.Background(), filter)
 			if err != nil {
-				log.WithError(err).Error("discord")
-			}
-		}),
-		"ignored": validationWrapper(func(s *discordgo.Session, i *discordgo.InteractionCreate, g *discordgo.Guild) {
-			val, err := redis.Client.SMembers(context.Background(), fmt.Sprintf("ignored-users:%s", g.ID)).Result()
-			if err != nil || len(val) == 0 {
-				msg := "Internal server error."
-				if err == redis.ErrNil || len(val) == 0 {
-					msg = "There are no ignored users."
-				} else {
-					log.WithError(err).Error("redis")
-				}
+				log.WithError(err).Error("mongo")
 				err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 					Type: discordgo.InteractionResponseChannelMessageWithSource,
 					Data: &discordgo.InteractionApplicationCommandResponseData{
-						Content: msg,
+						Content: tr(guildLanguage(i.GuildID), "Internal server error. Please try again later."),
 						// Makes the response ephemeral https://discord.com/developers/docs/interactions/slash-commands#interaction-response
 						Flags: 64,
 					},
@@ -1254,112 +599,84 @@ var (
 				}
 				return
 			}
-			// We have a bunch of ids...
-			opts := options.Update().SetUpsert(true)
-
-			filter := bson.M{
-				"id": bson.M{
-					"$in": val,
-				},
-			}
-
-			users := []*mongo.User{}
 
-			cur, err := mongo.Database.Collection("users").Find(context.Background(), filter)
-			if err == nil {
-				err = cur.All(context.Background(), &users)
-			}
-			if err != nil {
-				log.WithError(err).Error("mongo")
+			if delres.DeletedCount > 0 {
+				msg := "The hook has been removed."
+				if delres.DeletedCount > 1 {
+					msg = "The hooks have been removed."
+				}
 				err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 					Type: discordgo.InteractionResponseChannelMessageWithSource,
 					Data: &discordgo.InteractionApplicationCommandResponseData{
-						Content: "Internal server error. Please try again later.",
-						// Makes the response ephemeral https://discord.com/developers/docs/interactions/slash-commands#interaction-response
-						Flags: 64,
+						Content: tr(guildLanguage(i.GuildID), msg),
 					},
 				})
 				if err != nil {
 					log.WithError(err).Error("discord")
 				}
-				return
-			}
 
-			if len(users) != len(val) {
-				// find missing ones
-				missingIDs := []string{}
-				for _, id := range val {
-					found := false
-					for _, u := range users {
-						if u.ID == id {
-							found = true
-							break
-						}
-					}
-					if !found {
-						missingIDs = append(missingIDs, id)
+				if count, err := mongo.Database.Collection("hooks").CountDocuments(context.Background(), bson.M{
+					"guild_id":    g.ID,
+					"streamer_id": user.ID,
+				}); err != nil {
+					log.WithError(err).Error("mongo")
+				} else if count == 0 {
+					if _, err := mongo.Database.Collection("sinks").DeleteMany(context.Background(), bson.M{
+						"guild_id":    g.ID,
+						"streamer_id": user.ID,
+					}); err != nil {
+						log.WithError(err).Error("mongo")
 					}
 				}
-				apiUsers, err := api.GetUsers(context.Background(), "", missingIDs, nil)
+
+				val, err := redis.Client.DecrBy(context.Background(), fmt.Sprintf("streamers:%s", user.ID), delres.DeletedCount).Result()
 				if err != nil {
-					log.WithError(err).Error("api")
-					err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-						Type: discordgo.InteractionResponseChannelMessageWithSource,
-						Data: &discordgo.InteractionApplicationCommandResponseData{
-							Content: "Internal server error. Please try again later.",
-							// Makes the response ephemeral https://discord.com/developers/docs/interactions/slash-commands#interaction-response
-							Flags: 64,
-						},
-					})
-					if err != nil {
-						log.WithError(err).Error("discord")
+					log.WithError(err).Error("redis")
+				} else if val == 0 {
+					if err := api.RevokeWebhook(context.Background(), user.ID); err != nil {
+						log.WithError(err).Error("api")
 					}
 				}
-				wg := sync.WaitGroup{}
-				wg.Add(len(apiUsers))
-
-				for _, u := range apiUsers {
-					go func(u api.TwitchUser) {
-						defer wg.Done()
-						user := &mongo.User{
-							ID:    u.ID,
-							Name:  u.DisplayName,
-							Login: u.Login,
-						}
-						users = append(users, user)
-						if _, err := mongo.Database.Collection("users").UpdateOne(context.Background(), bson.M{
-							"$or": bson.A{
-								bson.M{"id": u.ID},
-								bson.M{"login": u.Login},
-							},
-						}, bson.M{"$set": user}, opts); err != nil {
-							log.WithError(err).Error("mongo")
-						}
-					}(u)
-				}
-				wg.Done()
-			}
-
-			usrStr := make([]string, len(users))
-			for i, user := range users {
-				usrStr[i] = user.Name
+				return
 			}
 
 			err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 				Type: discordgo.InteractionResponseChannelMessageWithSource,
 				Data: &discordgo.InteractionApplicationCommandResponseData{
-					Content: fmt.Sprintf("Ignored Users: %s", strings.Join(usrStr, ", ")),
+					Content: tr(guildLanguage(i.GuildID), "That hook doesn't exist"),
+					// Makes the response ephemeral https://discord.com/developers/docs/interactions/slash-commands#interaction-response
+					Flags: 64,
 				},
 			})
 			if err != nil {
 				log.WithError(err).Error("discord")
 			}
 		}),
+		"ignore":      validationWrapper(ignoreHandler),
+		"unignore":    validationWrapper(unignoreHandler),
+		"ignored":     readWrapper(ignoredHandler),
+		"sink":        validationWrapper(sinkHandler),
+		"digest":      validationWrapper(digestHandler),
+		"stats":       readWrapper(statsHandler),
+		"export":      readWrapper(exportHandler),
+		"permissions": permissionWrapper(permissionAdmin, permissionsHandler),
+		"alerts":      validationWrapper(alertsHandler),
+		"crossbans":   readWrapper(crossbansHandler),
+		"expiry":      validationWrapper(expiryHandler),
+		"note":        validationWrapper(noteHandler),
+		"history":     readWrapper(historyHandler),
+		"search":      readWrapper(searchHandler),
+		"twitch":      validationWrapper(twitchHandler),
+		"appeals":     validationWrapper(appealsHandler),
+		"profiles":    validationWrapper(profilesHandler),
+		"chatcontext": validationWrapper(chatContextHandler),
+		"deletions":   validationWrapper(deletionsHandler),
+		"language":    validationWrapper(languageHandler),
 		"link": func(s *discordgo.Session, i *discordgo.InteractionCreate) {
 			err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 				Type: discordgo.InteractionResponseChannelMessageWithSource,
 				Data: &discordgo.InteractionApplicationCommandResponseData{
-					Content: fmt.Sprintf("This bot can be invited to a server by going to <%s/login>.\nThis is an opensource bot and it's free, <https://github.com/troydota/modlogs>", configure.Config.GetString("website_url")),
+					Content: tr(guildLanguage(i.GuildID), "This bot can be invited to a server by going to <%s/login>.\nThis is an opensource bot and it's free, <https://github.com/troydota/modlogs>", configure.Config.GetString("website_url")),
 				},
 			})
 			if err != nil {
@@ -1396,6 +713,13 @@ func New() *Bot {
 		}
 	})
 
+	// Button presses and modal submits are not parsed by discordgo, so they are read from the raw event.
+	dg.AddHandler(func(s *discordgo.Session, e *discordgo.Event) {
+		if e.Type == "INTERACTION_CREATE" {
+			handleComponent(s, e.RawData)
+		}
+	})
+
 	// Open a websocket connection to Discord and begin listening.
 	err = dg.Open()
 	if err != nil {
@@ -1412,7 +736,7 @@ func New() *Bot {
 	if configure.Config.GetBool("rebuild_commands") {
 		go func() {
 			for _, v := range commands {
-				c, err := dg.ApplicationCommandCreate(dg.State.User.ID, "", v)
+				c, err := createCommand(dg, dg.State.User.ID, v)
 				if err != nil {
 					panic(fmt.Sprintf("cannot create '%v' command: %v", v.Name, err))
 				}
@@ -1432,14 +756,35 @@ func New() *Bot {
 				return
 			case msg := <-Callback:
 				go bot.processCallback(msg)
+			case req := <-Links:
+				go bot.processLink(req)
+			case req := <-Revokes:
+				go bot.processRevoke(req)
+			case req := <-Appeals:
+				go bot.processAppeal(req)
+			case ev := <-UnbanRequests:
+				go bot.processUnbanRequest(ev)
+			case d := <-Deletions:
+				go bot.processDeletion(d)
 			}
 		}
 	}()
 
+	go bot.runDigests()
+
+	go bot.runTimeouts()
+
+	go bot.runUserRefresh()
+
+	go migrateIgnoredUsers()
+
 	return bot
 }
 
 func (b *Bot) processCallback(cb WebhookRequest) {
+	event := callbackEvent(cb)
+	claimDiscordAction(event)
+
 	hooks := []*mongo.Hook{}
 
 	cur, err := mongo.Database.Collection("hooks").Find(context.Background(), bson.M{"streamer_id": cb.BroadcasterID})
@@ -1447,11 +792,32 @@ func (b *Bot) processCallback(cb WebhookRequest) {
 		err = cur.All(context.Background(), &hooks)
 	}
 
+	guilds := map[string]bool{}
+	for _, hook := range hooks {
+		if !guilds[hook.GuildID] {
+			guilds[hook.GuildID] = true
+			event.GuildIDs = append(event.GuildIDs, hook.GuildID)
+		}
+	}
+	storeEvent(event)
+	go trackLogins(event)
+
 	if err != nil {
 		log.WithError(err).Error("mongo")
 		return
 	}
 
+	go b.checkAlerts(event, hooks)
+
+	if eventKind(event) == kindTimeout {
+		for _, hook := range hooks {
+			if hook.Expiry != mongo.ExpiryOff {
+				scheduleTimeout(event)
+				break
+			}
+		}
+	}
+
 	wg := &sync.WaitGroup{}
 	wg.Add(len(hooks))
 
@@ -1479,7 +845,7 @@ func (b *Bot) processCallback(cb WebhookRequest) {
 			cmd = fmt.Sprintf("ban %s", cb.UserName)
 		} else {
 			title = "User Timeout Event"
-			fields = append(fields, &discordgo.MessageEmbedField{Name: "Expires", Value: cb.Expires.Format("Mon Jan _2 15:04:05 2006")})
+			fields = append(fields, &discordgo.MessageEmbedField{Name: "Expires", Value: discordTime(*cb.Expires)})
 			cmd = fmt.Sprintf("timeout %s %v", cb.UserName, int64(math.Round(float64(cb.Expires.Sub(cb.CreatedAt)/time.Second))+1))
 		}
 		if cb.Reason != "" {
@@ -1510,6 +876,16 @@ func (b *Bot) processCallback(cb WebhookRequest) {
 		cmd = fmt.Sprintf("unmod %s", cb.UserName)
 	}
 
+	if event.UserID != "" {
+		if v := previousLoginsValue(&mongo.User{ID: event.UserID, Login: strings.ToLower(event.UserName)}, []string{event.BroadcasterID}); v != "" {
+			fields = append(fields, &discordgo.MessageEmbedField{Name: "Previously Known As", Value: v})
+		}
+	}
+
+	if event.Case != 0 {
+		fields = append(fields, &discordgo.MessageEmbedField{Name: "Case", Value: fmt.Sprintf("#%v", event.Case), Inline: true})
+	}
+
 	embed := &discordgo.MessageEmbed{
 		Title:       title,
 		Description: "_ _",
@@ -1531,8 +907,52 @@ func (b *Bot) processCallback(cb WebhookRequest) {
 		executerID = cb.BroadcasterID
 	}
 
-	minimalText := fmt.Sprintf("**%s: #%s** - `%s` executed `/%s`", title, cb.BroadcasterUserName, strings.ReplaceAll(executer, "`", ""), strings.ReplaceAll(cmd, "`", ""))
+	minimalText := func(lang string) string {
+		text := tr(lang, "**%s: #%s** - `%s` executed `/%s`", tr(lang, title), cb.BroadcasterUserName, strings.ReplaceAll(executer, "`", ""), strings.ReplaceAll(cmd, "`", ""))
+		if event.Case != 0 {
+			text = fmt.Sprintf("`#%v` %s", event.Case, text)
+		}
+		return text
+	}
+
+	buttons := logButtons(event)
+
+	entry := &sinks.Entry{
+		Title:       title,
+		Color:       color,
+		Broadcaster: cb.BroadcasterUserName,
+		Executer:    executer,
+		ExecuterID:  executerID,
+		Command:     cmd,
+		CreatedAt:   cb.CreatedAt,
+	}
+	for _, f := range fields {
+		entry.Fields = append(entry.Fields, sinks.Field{Name: f.Name, Value: f.Value})
+	}
+
+	go b.sendSinks(event, entry)
+
+	guildIDs := []string{}
+	for _, hook := range hooks {
+		guildIDs = append(guildIDs, hook.GuildID)
+	}
+	crossChannels := crossChannelSummaries(event, guildIDs)
+
+	var profileEmbed *discordgo.MessageEmbed
+	if wantsProfiles(hooks) {
+		profileEmbed = enrichEmbed(embed, event)
+	}
+
+	var chat []*ChatMessage
+	if count := wantsChatContext(event, hooks); count != 0 {
+		chat, err = chatMessages(event.BroadcasterID, event.UserID, count)
+		if err != nil {
+			log.WithError(err).Error("redis")
+		}
+	}
 
+	unbanMessages := map[string]*discordgo.Message{}
+	unbanMtx := &sync.Mutex{}
 	for _, hook := range hooks {
 		go func(hook *mongo.Hook) {
 			defer wg.Done()
@@ -1566,39 +986,100 @@ func (b *Bot) processCallback(cb WebhookRequest) {
 				}
 			}
 
-			if redis.Client.SIsMember(context.Background(), fmt.Sprintf("ignored-users:%s", hook.GuildID), executerID).Val() {
+			if isIgnored(hook.GuildID, hook.ChannelID, event) {
 				return
 			}
 
+			lang := guildLanguage(hook.GuildID)
+			text := minimalText(lang)
+			hookFields := []*discordgo.MessageEmbedField{}
+			if event.IssuedBy != "" && event.IssuedGuildID == hook.GuildID {
+				hookFields = append(hookFields, &discordgo.MessageEmbedField{Name: "Issued From Discord", Value: fmt.Sprintf("<@%s>", event.IssuedBy)})
+				text = tr(lang, "%s (issued from discord by <@%s>)", text, event.IssuedBy)
+			}
+			if summary, ok := crossChannels[hook.GuildID]; ok {
+				hookFields = append(hookFields, &discordgo.MessageEmbedField{Name: "Other Channels", Value: summary})
+				text = tr(lang, "%s (other channels: %s)", text, strings.ReplaceAll(summary, "\n", ", "))
+			}
+
+			hookEmbed := embed
+			if hook.Profiles && profileEmbed != nil {
+				hookEmbed = profileEmbed
+			}
+			hookChat := lastMessages(chat, int(hook.ChatContext))
+			if len(hookChat) != 0 && hook.Mode == mongo.ModeEmbed && !hook.ChatAttachment {
+				if v := chatContextValue(hookChat); v != "" {
+					hookFields = append(hookFields, &discordgo.MessageEmbedField{Name: "Recent Chat", Value: v})
+				}
+			}
+			if len(hookFields) != 0 {
+				copied := *hookEmbed
+				copied.Fields = append(append([]*discordgo.MessageEmbedField{}, hookEmbed.Fields...), hookFields...)
+				hookEmbed = &copied
+			}
+			hookEmbed = localizeEmbed(hookEmbed, lang)
+
+			var msg *discordgo.Message
 			var err error
+			merged := []string{}
 			if hook.Mode == mongo.ModeEmbed {
-				if result := b.limiter.Limit(hook.ChannelID, "", func(c string) bool {
+				if result := b.limiter.Limit(hook.ChannelID, event.ID, "", func(c string, id string) bool {
 					return false
 				}); result {
-					_, err = b.conn.ChannelMessageSendEmbed(hook.ChannelID, embed)
+					msg, err = b.sendEmbed(hook, hookEmbed, localizeComponents(buttons, lang)...)
 				}
 			} else {
 				mtx := &sync.Mutex{}
-				if result := b.limiter.Limit(hook.ChannelID, minimalText, func(c string) bool {
+				if result := b.limiter.Limit(hook.ChannelID, event.ID, text, func(c string, id string) bool {
 					mtx.Lock()
 					defer mtx.Unlock()
-					newMessage := fmt.Sprintf("%s\n%s", minimalText, c)
+					newMessage := fmt.Sprintf("%s\n%s", text, c)
 					if len(newMessage) < 2000 {
-						minimalText = newMessage
+						text = newMessage
+						merged = append(merged, id)
 						return true
 					}
 					return false
 				}); result {
-					_, err = b.conn.ChannelMessageSend(hook.ChannelID, minimalText)
+					mtx.Lock()
+					content := text
+					mtx.Unlock()
+					msg, err = b.sendMessage(hook, content)
 				}
 			}
 			if err != nil {
 				log.WithError(err).WithField("hook", hook).Error("discord")
+			} else if msg != nil {
+				storeLogMessage(hook, event.ID, msg)
+				// The events merged into the message share it, so their follow-ups find it too.
+				for _, id := range merged {
+					storeLogMessage(hook, id, msg)
+				}
+				if eventKind(event) == kindUnban {
+					unbanMtx.Lock()
+					unbanMessages[reversalKey(hook.GuildID, hook.ChannelID)] = msg
+					unbanMtx.Unlock()
+				}
+			}
+
+			// Minimal logs have no room for the chat, so it is always attached to them.
+			if msg != nil && len(hookChat) != 0 && (hook.Mode != mongo.ModeEmbed || hook.ChatAttachment) {
+				content := tr(lang, "Last chat messages of `%s` in #%s", strings.ReplaceAll(event.UserName, "`", ""), event.BroadcasterUserName)
+				if event.Case != 0 {
+					content = tr(lang, "%s (case `#%v`)", content, event.Case)
+				}
+				if _, err := b.sendFile(hook, content, chatContextFile(event, hookChat)); err != nil {
+					log.WithError(err).WithField("hook", hook).Error("discord")
+				}
 			}
 		}(hook)
 	}
 
 	wg.Wait()
+
+	if eventKind(event) == kindUnban {
+		b.markReversed(event, unbanMessages)
+	}
 }
 
 func (b *Bot) Shutdown() error {
@@ -0,0 +1,596 @@
+package bot
+
+// commandNames are the localized names of the commands and options shown in the command picker.
+var commandNames = map[string]map[string]string{
+	"de": {
+		"history":  "verlauf",
+		"search":   "suche",
+		"language": "sprache",
+		"ignored":  "ignoriert",
+		"export":   "exportieren",
+	},
+	"fr": {
+		"history":  "historique",
+		"search":   "recherche",
+		"language": "langue",
+		"ignored":  "ignores",
+		"export":   "exporter",
+	},
+}
+
+// catalog translates the replies, logs and command descriptions, keyed by the english text or format.
+// Formats keep the verbs of the english text in the same order.
+var catalog = map[string]map[string]string{
+	"de": {
+		// Replies
+		"%s output added for <https://twitch.tv/%s>.":                                                     "%s-Ausgabe für <https://twitch.tv/%s> hinzugefügt.",
+		"<@%s> extended the timeout of `%s` in #%s by %s (case `#%v`).":                                   "<@%s> hat den Timeout von `%s` in #%s um %s verlängert (Fall `#%v`).",
+		"<@%s> unbanned `%s` from #%s (case `#%v`).":                                                      "<@%s> hat den Bann von `%s` in #%s aufgehoben (Fall `#%v`).",
+		"<@&%s> can no longer use the modlogs commands.":                                                  "<@&%s> kann die modlogs-Befehle nicht mehr verwenden.",
+		"<@&%s> is now a modlogs %s.":                                                                     "<@&%s> ist jetzt modlogs %s.",
+		"A daily digest for <https://twitch.tv/%s> will be posted every day at 00:00 UTC.":                "Eine tägliche Zusammenfassung für <https://twitch.tv/%s> wird jeden Tag um 00:00 UTC gepostet.",
+		"A message will be posted once a timeout of <https://twitch.tv/%s> ends.":                         "Eine Nachricht wird gepostet, sobald ein Timeout von <https://twitch.tv/%s> endet.",
+		"A weekly digest for <https://twitch.tv/%s> will be posted every monday at 00:00 UTC.":            "Eine wöchentliche Zusammenfassung für <https://twitch.tv/%s> wird jeden Montag um 00:00 UTC gepostet.",
+		"Added the note to case `#%v`.":                                                                   "Die Notiz wurde zu Fall `#%v` hinzugefügt.",
+		"Approved the appeal, `%s` was unbanned from #%s.":                                                "Der Einspruch wurde angenommen, der Bann von `%s` in #%s wurde aufgehoben.",
+		"Ban and timeout logs of <https://twitch.tv/%s> will no longer show the chat of the user.":        "Bann- und Timeout-Logs von <https://twitch.tv/%s> zeigen den Chat des Nutzers nicht mehr.",
+		"Ban and timeout logs of <https://twitch.tv/%s> will show the last %v chat messages of the user.": "Bann- und Timeout-Logs von <https://twitch.tv/%s> zeigen die letzten %v Chatnachrichten des Nutzers.",
+		"Ban appeals are not posted in this discord.":                                                     "Einsprüche gegen Banns werden in diesem Discord nicht gepostet.",
+		"Ban appeals of <https://twitch.tv/%s> are no longer posted in this discord.":                     "Einsprüche gegen Banns von <https://twitch.tv/%s> werden in diesem Discord nicht mehr gepostet.",
+		"Ban appeals of <https://twitch.tv/%s> will be posted in <#%s>, users can appeal at <%s/appeal>.": "Einsprüche gegen Banns von <https://twitch.tv/%s> werden in <#%s> gepostet, Nutzer können unter <%s/appeal> Einspruch einlegen.",
+		"Banned `%s` from <https://twitch.tv/%s>.":                                                        "`%s` wurde in <https://twitch.tv/%s> gebannt.",
+		"Deleted messages of <https://twitch.tv/%s> will be logged.":                                      "Gelöschte Nachrichten von <https://twitch.tv/%s> werden geloggt.",
+		"Deleted messages of <https://twitch.tv/%s> will no longer be logged.":                            "Gelöschte Nachrichten von <https://twitch.tv/%s> werden nicht mehr geloggt.",
+		"Denied the appeal.": "Der Einspruch wurde abgelehnt.",
+		"Digests are disabled for <https://twitch.tv/%s>.":                                           "Zusammenfassungen für <https://twitch.tv/%s> sind deaktiviert.",
+		"Embed logs of <https://twitch.tv/%s> will no longer show the twitch profiles of the users.": "Embed-Logs von <https://twitch.tv/%s> zeigen die Twitch-Profile der Nutzer nicht mehr.",
+		"Embed logs of <https://twitch.tv/%s> will show the twitch profiles of the users.":           "Embed-Logs von <https://twitch.tv/%s> zeigen die Twitch-Profile der Nutzer.",
+		"Failed to listen to the chat, please try again later.":                                      "Der Chat konnte nicht abonniert werden, bitte versuche es später erneut.",
+		"Failed to listen to the deleted messages, please try again later.":                          "Die gelöschten Nachrichten konnten nicht abonniert werden, bitte versuche es später erneut.",
+		"Internal Server Error. Please try again later...":                                           "Interner Serverfehler. Bitte versuche es später erneut...",
+		"Internal Server Error.":                         "Interner Serverfehler.",
+		"Internal server error occured.":                 "Ein interner Serverfehler ist aufgetreten.",
+		"Internal server error. Please try again later.": "Interner Serverfehler. Bitte versuche es später erneut.",
+		"Invalid appeal.":                                "Ungültiger Einspruch.",
+		"Invalid case.":                                  "Ungültiger Fall.",
+		"Invalid duration, use a value such as 10m or 2h, up to two weeks.":                         "Ungültige Dauer, verwende einen Wert wie 10m oder 2h, höchstens zwei Wochen.",
+		"Invalid duration, use a value such as 30m or 2h.":                                          "Ungültige Dauer, verwende einen Wert wie 30m oder 2h.",
+		"Invalid unban request.":                                                                    "Ungültige Entbannungsanfrage.",
+		"Logs can only be outputted into a text channel.":                                           "Logs können nur in einen Textkanal gepostet werden.",
+		"No twitch account used that username.":                                                     "Kein Twitch-Konto hat diesen Nutzernamen verwendet.",
+		"No user is banned on more than one hooked channel.":                                        "Kein Nutzer ist in mehr als einem verknüpften Kanal gebannt.",
+		"Please enter a note.":                                                                      "Bitte gib eine Notiz ein.",
+		"Please enter a reply.":                                                                     "Bitte gib eine Antwort ein.",
+		"Please enter a rule id as shown by /ignored.":                                              "Bitte gib eine Regel-ID ein, wie sie /ignored anzeigt.",
+		"Please enter a valid https url.":                                                           "Bitte gib eine gültige https-URL ein.",
+		"Please enter a valid user or a reason pattern.":                                            "Bitte gib einen gültigen Nutzer oder ein Grundmuster ein.",
+		"Please enter a valid user or rule.":                                                        "Bitte gib einen gültigen Nutzer oder eine gültige Regel ein.",
+		"Please enter the dates as YYYY-MM-DD.":                                                     "Bitte gib die Daten als JJJJ-MM-TT ein.",
+		"Please provide the token from the login page, or the broadcaster to send a login link to.": "Bitte gib den Token von der Login-Seite an, oder den Streamer, dem ein Login-Link geschickt werden soll.",
+		"Please select a channel in this discord.":                                                  "Bitte wähle einen Kanal in diesem Discord.",
+		"Please select a sub command.":                                                              "Bitte wähle einen Unterbefehl.",
+		"Please select a text channel of this discord.":                                             "Bitte wähle einen Textkanal dieses Discords.",
+		"Please select a valid channel.":                                                            "Bitte wähle einen gültigen Kanal.",
+		"Please select a valid language.":                                                           "Bitte wähle eine gültige Sprache.",
+		"Please select a valid role.":                                                               "Bitte wähle eine gültige Rolle.",
+		"Removed %v output(s) for <https://twitch.tv/%s>.":                                          "%v Ausgabe(n) für <https://twitch.tv/%s> entfernt.",
+		"Stopped alerting about %s.":                                                                "Keine Warnungen mehr zu %s.",
+		"Successfully ignored %s.":                                                                  "%s wird jetzt ignoriert.",
+		"Successfully unignored %s.":                                                                "%s wird nicht mehr ignoriert.",
+		"That appeal doesn't belong to a broadcaster reviewed in this discord.":                     "Dieser Einspruch gehört zu keinem Streamer, der in diesem Discord geprüft wird.",
+		"That appeal was already resolved.":                                                         "Dieser Einspruch wurde bereits bearbeitet.",
+		"That broadcaster has no outputs.":                                                          "Dieser Streamer hat keine Ausgaben.",
+		"That broadcaster is not hooked in that channel.":                                           "Dieser Streamer ist in diesem Kanal nicht verknüpft.",
+		"That broadcaster is not hooked in this discord, use /add first.":                           "Dieser Streamer ist in diesem Discord nicht verknüpft, verwende zuerst /add.",
+		"That broadcaster is not hooked in this discord.":                                           "Dieser Streamer ist in diesem Discord nicht verknüpft.",
+		"That case is not a timeout.":                                                               "Dieser Fall ist kein Timeout.",
+		"That hook doesn't exist":                                                                   "Diese Verknüpfung existiert nicht",
+		"That unban request doesn't belong to a broadcaster hooked in this discord.":                "Diese Entbannungsanfrage gehört zu keinem Streamer, der in diesem Discord verknüpft ist.",
+		"That unban request was already resolved.":                                                  "Diese Entbannungsanfrage wurde bereits bearbeitet.",
+		"The bot can't read that chat, the broadcaster or the moderator who added the hook must log in on the website again to allow it.": "Der Bot kann diesen Chat nicht lesen, der Streamer oder der Moderator, der die Verknüpfung hinzugefügt hat, muss sich dafür erneut auf der Website anmelden.",
+		"The bot now replies and logs in %s.":                                          "Der Bot antwortet und loggt jetzt auf %s.",
+		"The count has to be at least 2 and the window between 1 and 1440 minutes.":    "Die Anzahl muss mindestens 2 und das Zeitfenster zwischen 1 und 1440 Minuten sein.",
+		"The hook channels will be alerted about %v %s within %v minutes.":             "Die verknüpften Kanäle werden bei %v %s innerhalb von %v Minuten gewarnt.",
+		"The number of messages must be between 0 and %v.":                             "Die Anzahl der Nachrichten muss zwischen 0 und %v liegen.",
+		"The reason is not a valid regular expression.":                                "Der Grund ist kein gültiger regulärer Ausdruck.",
+		"The specified broadcaster does not exist.":                                    "Der angegebene Streamer existiert nicht.",
+		"The specified user does not exist.":                                           "Der angegebene Nutzer existiert nicht.",
+		"The start date has to be before the end date.":                                "Das Startdatum muss vor dem Enddatum liegen.",
+		"The unban request of `%s` was %s.":                                            "Die Entbannungsanfrage von `%s` wurde %s.",
+		"There are no hooks in this discord.":                                          "Es gibt keine Verknüpfungen in diesem Discord.",
+		"There are no ignore rules for %s.":                                            "Es gibt keine Ignorierregeln für %s.",
+		"There are no ignored users.":                                                  "Es gibt keine ignorierten Nutzer.",
+		"There have to be at least two hooked broadcasters in this discord.":           "In diesem Discord müssen mindestens zwei Streamer verknüpft sein.",
+		"Timed out `%s` in <https://twitch.tv/%s> for %s.":                             "`%s` hat in <https://twitch.tv/%s> einen Timeout für %s erhalten.",
+		"Timeout expiry messages are disabled for <https://twitch.tv/%s>.":             "Nachrichten zum Ablauf von Timeouts sind für <https://twitch.tv/%s> deaktiviert.",
+		"Timeout logs of <https://twitch.tv/%s> will be edited once the timeout ends.": "Timeout-Logs von <https://twitch.tv/%s> werden bearbeitet, sobald der Timeout endet.",
+		"Unbanned `%s` from <https://twitch.tv/%s>.":                                   "Der Bann von `%s` in <https://twitch.tv/%s> wurde aufgehoben.",
+		"Unknown action `%s`, the actions are %s.":                                     "Unbekannte Aktion `%s`, die Aktionen sind %s.",
+		"You do not have permission to execute that command.":                          "Du hast keine Berechtigung, diesen Befehl auszuführen.",
+		"You do not have permission to use that button.":                               "Du hast keine Berechtigung, diesen Button zu verwenden.",
+		"That user is no longer timed out, time them out again instead.":               "Dieser Nutzer hat keinen Timeout mehr, gib ihm stattdessen einen neuen Timeout.",
+		"That case doesn't belong to a broadcaster hooked in this discord.":            "Dieser Fall gehört zu keinem Streamer, der in diesem Discord verknüpft ist.",
+		"No one who authorized a hook of that channel in this discord can moderate it, the broadcaster or a moderator has to authorize a hook here before actions can be taken from discord.": "Niemand, der in diesem Discord einen Hook für diesen Kanal autorisiert hat, kann ihn moderieren, der Streamer oder ein Moderator muss hier einen Hook autorisieren, bevor Aktionen über Discord ausgeführt werden können.",
+		"The stored twitch login is missing the permission for that action, the broadcaster or a moderator has to login again.":                                                               "Der gespeicherten Twitch-Anmeldung fehlt die Berechtigung für diese Aktion, der Streamer oder ein Moderator muss sich erneut anmelden.",
+		"bans in a channel":                    "Banns in einem Kanal",
+		"bans by a single moderator":           "Banns durch einen einzelnen Moderator",
+		"timeouts of the same user":            "Timeouts desselben Nutzers",
+		"%v %s within %v minutes":              "%v %s innerhalb von %v Minuten",
+		"There are no alerts in this discord.": "In diesem Discord gibt es keine Warnungen.",
+		"Alerts ping <@&%s>.":                  "Warnungen pingen <@&%s>.",
+		"Alerts now ping <@&%s>.":              "Warnungen pingen jetzt <@&%s>.",
+		"Alerts no longer ping a role.":        "Warnungen pingen keine Rolle mehr.",
+		"<https://twitch.tv/%s> can start logging into %s by logging in at <%s/login?link=%s>, the link will expire in 24 hours.": "<https://twitch.tv/%s> kann das Loggen in %s starten, indem er sich unter <%s/login?link=%s> anmeldet, der Link läuft in 24 Stunden ab.",
+		"ModLogs hook %s for <https://twitch.tv/%s>, into %s":                                                                     "ModLogs-Hook %s für <https://twitch.tv/%s>, in %s",
+		"added":   "hinzugefügt",
+		"updated": "aktualisiert",
+		"Users banned on several hooked channels:": "Nutzer, die in mehreren verknüpften Kanälen gebannt sind:",
+		"and %v more...": "und %v weitere...",
+		"There are too many hooks in this discord. (%v/%v)": "In diesem Discord gibt es zu viele Hooks. (%v/%v)",
+		"The broadcaster has not authorized ModLogs and the moderator who authorized the channel did not grant every permission needed to read its moderation actions, they have to login again.": "Der Streamer hat ModLogs nicht autorisiert und der Moderator, der den Kanal autorisiert hat, hat nicht alle Berechtigungen erteilt, um die Moderationsaktionen zu lesen, er muss sich erneut anmelden.",
+		"Failed to create a webhook in that channel, make sure the bot has the Manage Webhooks permission.":                                                                                       "Der Webhook konnte in diesem Kanal nicht erstellt werden, stelle sicher, dass der Bot die Berechtigung Webhooks verwalten hat.",
+		"actions on `%s`":                        "Aktionen gegen `%s`",
+		"actions by or on `%s`":                  "Aktionen von oder gegen `%s`",
+		"actions by `%s`":                        "Aktionen von `%s`",
+		"all actions":                            "alle Aktionen",
+		"of type %s":                             "vom Typ %s",
+		"with a reason matching `%s`":            "mit einem Grund passend zu `%s`",
+		"in #%s":                                 "in #%s",
+		"posted into <#%s>":                      "gepostet in <#%s>",
+		"Ignore rules:":                          "Ignorierregeln:",
+		"the streamer":                           "dem Streamer",
+		"their moderator <https://twitch.tv/%s>": "seinem Moderator <https://twitch.tv/%s>",
+		"Failed to add the ModLogs hook for <https://twitch.tv/%s>: %s":                                 "Der ModLogs-Hook für <https://twitch.tv/%s> konnte nicht hinzugefügt werden: %s",
+		"ModLogs hook %s for <https://twitch.tv/%s>, into <#%s>. Requested by <@%s>, authorized by %s.": "ModLogs-Hook %s für <https://twitch.tv/%s>, in <#%s>. Angefordert von <@%s>, autorisiert von %s.",
+		"Current username: [%s](https://twitch.tv/%s)":                                                  "Aktueller Nutzername: [%s](https://twitch.tv/%s)",
+		"Previously known as: %s":            "Früher bekannt als: %s",
+		"Logged actions: %v":                 "Geloggte Aktionen: %v",
+		"Twitch accounts which used `%s`.":   "Twitch-Konten, die `%s` verwendet haben.",
+		"Note on case #%s":                   "Notiz zu Fall #%s",
+		"Why did it happen? Links to clips?": "Warum ist es passiert? Links zu Clips?",
+		"Extend timeout #%s":                 "Timeout #%s verlängern",
+		"Extra time, such as 30m or 2h":      "Zusätzliche Zeit, wie 30m oder 2h",
+		"Approve the appeal":                 "Einspruch annehmen",
+		"Deny the appeal":                    "Einspruch ablehnen",
+		"Reply shown to the user":            "Antwort, die dem Nutzer angezeigt wird",
+		"Approve the unban request":          "Entbannungsanfrage annehmen",
+		"Deny the unban request":             "Entbannungsanfrage ablehnen",
+		"Resolution shown to the user":       "Entscheidung, die dem Nutzer angezeigt wird",
+		"Managers: %s\nReaders: %s\nThe server owner and administrators can always use every command.": "Manager: %s\nLeser: %s\nDer Serverbesitzer und Administratoren können immer alle Befehle verwenden.",
+		"Please enter a Slack incoming webhook url, such as https://hooks.slack.com/services/....":     "Bitte gib eine Slack-Incoming-Webhook-URL ein, wie https://hooks.slack.com/services/....",
+		"Please enter a valid https url of a public homeserver.":                                       "Bitte gib eine gültige https-URL eines öffentlichen Homeservers ein.",
+		"Slack webhook":                     "Slack-Webhook",
+		"Matrix room `%s`":                  "Matrix-Raum `%s`",
+		"No outputs were found":             "Es wurden keine Ausgaben gefunden",
+		"minimal":                           "minimal",
+		"rich":                              "ausführlich",
+		"all hooked channels":               "alle verknüpften Kanäle",
+		"the last day":                      "den letzten Tag",
+		"the last 7 days":                   "die letzten 7 Tage",
+		"the last 30 days":                  "die letzten 30 Tage",
+		"all time":                          "die gesamte Zeit",
+		"Moderation actions on %s over %s.": "Moderationsaktionen in %s über %s.",
+		"No moderation activity.":           "Keine Moderationsaktivität.",
+		"Bans: %v\nTimeouts: %v (avg %s)\nUnbans: %v\nUnban ratio: %.0f%%":                        "Banns: %v\nTimeouts: %v (Ø %s)\nEntbannungen: %v\nEntbannungsquote: %.0f%%",
+		"The chart follows the order above, bans in red, timeouts in orange and unbans in green.": "Das Diagramm folgt der Reihenfolge oben, Banns in Rot, Timeouts in Orange und Entbannungen in Grün.",
+		"None": "Keine",
+		"The token you provided is expired or invalid. Please login again to make a new one.": "Das angegebene Token ist abgelaufen oder ungültig. Bitte melde dich erneut an, um ein neues zu erstellen.",
+		"The hook has been removed.":   "Der Hook wurde entfernt.",
+		"The hooks have been removed.": "Die Hooks wurden entfernt.",
+		"This bot can be invited to a server by going to <%s/login>.\nThis is an opensource bot and it's free, <https://github.com/troydota/modlogs>": "Dieser Bot kann über <%s/login> zu einem Server eingeladen werden.\nDies ist ein kostenloser Open-Source-Bot, <https://github.com/troydota/modlogs>",
+		"rule `%s`": "Regel `%s`",
+		"Moderation logs for <https://twitch.tv/%s>.":                                                         "Moderationslogs für <https://twitch.tv/%s>.",
+		"Your export for <https://twitch.tv/%s> is ready at <%s/export/%s>, the link will expire in an hour.": "Dein Export für <https://twitch.tv/%s> ist unter <%s/export/%s> bereit, der Link läuft in einer Stunde ab.",
+		"No moderation logs of that broadcaster were posted in this discord.":                                 "Es wurden keine Moderationslogs dieses Streamers in diesem Discord gepostet.",
+		"%s The appeal couldn't be set back to pending, it stays approved although `%s` is still banned.":     "%s Der Einspruch konnte nicht wieder auf offen gesetzt werden, er bleibt angenommen, obwohl `%s` weiterhin gebannt ist.",
+
+		// Logs
+		"**%s: #%s** - `%s` executed `/%s`":                                 "**%s: #%s** - `%s` hat `/%s` ausgeführt",
+		"%s (issued from discord by <@%s>)":                                 "%s (über Discord ausgelöst von <@%s>)",
+		"%s (other channels: %s)":                                           "%s (andere Kanäle: %s)",
+		"**Message Deleted Event: #%s** - %s deleted a message of `%s`: %s": "**Nachricht gelöscht: #%s** - %s hat eine Nachricht von `%s` gelöscht: %s",
+		"A moderator":                       "Ein Moderator",
+		"User Ban Event":                    "Nutzer gebannt",
+		"User Timeout Event":                "Nutzer-Timeout",
+		"User Unban Event":                  "Nutzer entbannt",
+		"User Mod Event":                    "Nutzer zum Moderator ernannt",
+		"User Unmod Event":                  "Moderator entfernt",
+		"Message Deleted Event":             "Nachricht gelöscht",
+		"Unban Request":                     "Entbannungsanfrage",
+		"Unban Request Approved":            "Entbannungsanfrage angenommen",
+		"Unban Request Denied":              "Entbannungsanfrage abgelehnt",
+		"Unban Request Canceled":            "Entbannungsanfrage zurückgezogen",
+		"Broadcaster":                       "Streamer",
+		"User":                              "Nutzer",
+		"Moderator":                         "Moderator",
+		"Reason":                            "Grund",
+		"Expires":                           "Läuft ab",
+		"Case":                              "Fall",
+		"Previously Known As":               "Früher bekannt als",
+		"Issued From Discord":               "Über Discord ausgelöst",
+		"Other Channels":                    "Andere Kanäle",
+		"Recent Chat":                       "Letzte Nachrichten",
+		"Message":                           "Nachricht",
+		"Account Created":                   "Konto erstellt",
+		"Request":                           "Anfrage",
+		"Resolved By":                       "Bearbeitet von",
+		"Resolution":                        "Entscheidung",
+		"None Provided":                     "Nicht angegeben",
+		"Unknown":                           "Unbekannt",
+		"Unban":                             "Entbannen",
+		"Extend timeout":                    "Timeout verlängern",
+		"View history":                      "Verlauf anzeigen",
+		"Add note":                          "Notiz hinzufügen",
+		"Approve":                           "Annehmen",
+		"Deny":                              "Ablehnen",
+		"Notes":                             "Notizen",
+		"↳ Note on `#%v`: %s":               "↳ Notiz zu `#%v`: %s",
+		"Status":                            "Status",
+		"Expired at %s":                     "Abgelaufen am %s",
+		"Lifted early by %s at %s":          "Vorzeitig aufgehoben von %s am %s",
+		"Superseded by a ban from %s at %s": "Ersetzt durch einen Bann von %s am %s",
+		"Superseded by a new timeout from %s at %s":     "Ersetzt durch einen neuen Timeout von %s am %s",
+		"**User Timeout Ended: #%s** - `%s`: %s (<%s>)": "**Nutzer-Timeout beendet: #%s** - `%s`: %s (<%s>)",
+		"Reversed":                 "Aufgehoben",
+		"Reversed by %s at %s":     "Aufgehoben von %s am %s",
+		"[Unban message](%s)":      "[Entbannungsnachricht](%s)",
+		"↳ Reversed by `%s` at %s": "↳ Aufgehoben von `%s` am %s",
+		"**Unban Request: #%s** - `%s` (created %s): %s": "**Entbannungsanfrage: #%s** - `%s` (erstellt %s): %s",
+		"the user":                  "dem Nutzer",
+		"↳ %s by `%s` at %s":        "↳ %s von `%s` am %s",
+		"Approved":                  "Angenommen",
+		"Denied":                    "Abgelehnt",
+		"Canceled":                  "Zurückgezogen",
+		"Moderation Activity Alert": "Warnung zur Moderationsaktivität",
+		"%v users were banned in #%s within %v minutes.":      "%v Nutzer wurden in #%s innerhalb von %v Minuten gebannt.",
+		"%s banned %v users in #%s within %v minutes.":        "%s hat %v Nutzer in #%s innerhalb von %v Minuten gebannt.",
+		"%s was timed out %v times in #%s within %v minutes.": "%s hat %v Timeouts in #%s innerhalb von %v Minuten bekommen.",
+		"Moderator Statistics":                                "Moderatorstatistiken",
+		"User History":                                        "Nutzerverlauf",
+		"Ban":                                                 "Bann",
+		"Timeout (%s)":                                        "Timeout (%s)",
+		"By %s on %s":                                         "Von %s am %s",
+		"Reason: %s":                                          "Grund: %s",
+		"#%v %s in #%s":                                       "#%v %s in #%s",
+		"Latest actions against %s.":                          "Letzte Aktionen gegen %s.",
+		"No actions against %s were logged.":                  "Es wurden keine Aktionen gegen %s geloggt.",
+		"Previously known as %s.":                             "Früher bekannt als %s.",
+		"User Search":                                         "Nutzersuche",
+		"Ban Appeal":                                          "Einspruch gegen Bann",
+		"Ban Appeal Approved":                                 "Einspruch gegen Bann angenommen",
+		"Ban Appeal Denied":                                   "Einspruch gegen Bann abgelehnt",
+		"Banned":                                              "Gebannt",
+		"Ban Reason":                                          "Banngrund",
+		"Appeal":                                              "Einspruch",
+		"Response":                                            "Antwort",
+		"Moderation Digest: #%s":                              "Moderationszusammenfassung: #%s",
+		"%s to %s":                                            "%s bis %s",
+		"Actions per moderator":                               "Aktionen pro Moderator",
+		"Most actioned users":                                 "Am häufigsten betroffene Nutzer",
+		"Busiest hours":                                       "Aktivste Stunden",
+		"Mod team changes":                                    "Änderungen im Mod-Team",
+		"`%s` - %v bans, %v timeouts, %v unbans":              "`%s` - %v Banns, %v Timeouts, %v Entbannungen",
+		"`%s` - %v actions":                                   "`%s` - %v Aktionen",
+		"%s - %v actions":                                     "%s - %v Aktionen",
+		"Last chat messages of `%s` in #%s":                   "Letzte Chatnachrichten von `%s` in #%s",
+		"%s (case `#%v`)":                                     "%s (Fall `#%v`)",
+		"<https://twitch.tv/%s> revoked the ModLogs hook for this channel.": "<https://twitch.tv/%s> hat den ModLogs-Hook für diesen Kanal widerrufen.",
+		"#%s: banned %s ago":    "#%s: vor %s gebannt",
+		"#%s: timed out %s ago": "#%s: vor %s getimeoutet",
+		"#%s: unbanned %s ago":  "#%s: vor %s entbannt",
+
+		// Commands
+		"Alerts the hook channels about unusual moderation activity.":                          "Warnt die verknüpften Kanäle bei ungewöhnlicher Moderationsaktivität.",
+		"Configure where the ban appeals of a broadcaster are reviewed.":                       "Lege fest, wo Einsprüche gegen Banns eines Streamers geprüft werden.",
+		"Add the last chat messages of the user to the ban and timeout logs of a broadcaster.": "Füge die letzten Chatnachrichten des Nutzers zu den Bann- und Timeout-Logs eines Streamers hinzu.",
+		"Lists the users banned on several of the channels hooked in this discord.":            "Listet die Nutzer auf, die in mehreren der in diesem Discord verknüpften Kanäle gebannt sind.",
+		"Log the chat messages of a broadcaster deleted by moderators.":                        "Logge die von Moderatoren gelöschten Chatnachrichten eines Streamers.",
+		"Configure or post a summary of the moderation activity of a broadcaster.":             "Konfiguriere oder poste eine Zusammenfassung der Moderationsaktivität eines Streamers.",
+		"Configure what happens to the timeout logs of a broadcaster once the timeout ends.":   "Lege fest, was mit den Timeout-Logs eines Streamers passiert, sobald der Timeout endet.",
+		"Exports the moderation logs of a broadcaster as a file.":                              "Exportiert die Moderationslogs eines Streamers als Datei.",
+		"Set the language of the bot replies and the logs in this discord.":                    "Lege die Sprache der Antworten und Logs des Bots in diesem Discord fest.",
+		"The language to use.": "Die zu verwendende Sprache.",
+		"Ignore a user, such as a bot, or the actions matching a reason.":                 "Ignoriere einen Nutzer, etwa einen Bot, oder die Aktionen mit einem bestimmten Grund.",
+		"Unignore a user that was previously ignored":                                     "Ignoriere einen zuvor ignorierten Nutzer nicht mehr",
+		"Shows a list of the ignore rules.":                                               "Zeigt eine Liste der Ignorierregeln.",
+		"Finds the twitch accounts which used a username, with their previous usernames.": "Findet die Twitch-Konten, die einen Nutzernamen verwendet haben, mit ihren früheren Nutzernamen.",
+		"Attach context, such as why it happened or a clip, to a logged action.":          "Füge einer geloggten Aktion Kontext hinzu, etwa den Grund oder einen Clip.",
+		"Shows the logged actions against a twitch user, with their notes.":               "Zeigt die geloggten Aktionen gegen einen Twitch-Nutzer, mit ihren Notizen.",
+		"Manage which roles can use the modlogs commands.":                                "Verwalte, welche Rollen die modlogs-Befehle verwenden können.",
+		"Show the twitch profiles of the users in the embed logs of a broadcaster.":       "Zeige die Twitch-Profile der Nutzer in den Embed-Logs eines Streamers.",
+		"Manage Slack and Matrix outputs for the hooks in this discord.":                  "Verwalte Slack- und Matrix-Ausgaben für die Verknüpfungen in diesem Discord.",
+		"Shows moderator activity statistics for the hooked broadcasters.":                "Zeigt Statistiken zur Moderatorenaktivität der verknüpften Streamer.",
+		"Ban, timeout or unban a user on twitch.":                                         "Banne, timeoute oder entbanne einen Nutzer auf Twitch.",
+		"Shows a list of current hooks in this discord.":                                  "Zeigt eine Liste der aktuellen Verknüpfungen in diesem Discord.",
+		"The ID or name of the twitch streamer.":                                          "Die ID oder der Name des Twitch-Streamers.",
+		"Text channel where the hook is active.":                                          "Textkanal, in dem die Verknüpfung aktiv ist.",
+		"The id or username of the twitch account.":                                       "Die ID oder der Nutzername des Twitch-Kontos.",
+		"The id, current or old username of the twitch account.":                          "Die ID, der aktuelle oder ein alter Nutzername des Twitch-Kontos.",
+	},
+	"fr": {
+		// Replies
+		"%s output added for <https://twitch.tv/%s>.":                                                     "Sortie %s ajoutée pour <https://twitch.tv/%s>.",
+		"<@%s> extended the timeout of `%s` in #%s by %s (case `#%v`).":                                   "<@%s> a prolongé le timeout de `%s` sur #%s de %s (cas `#%v`).",
+		"<@%s> unbanned `%s` from #%s (case `#%v`).":                                                      "<@%s> a débanni `%s` de #%s (cas `#%v`).",
+		"<@&%s> can no longer use the modlogs commands.":                                                  "<@&%s> ne peut plus utiliser les commandes modlogs.",
+		"<@&%s> is now a modlogs %s.":                                                                     "<@&%s> est maintenant modlogs %s.",
+		"A daily digest for <https://twitch.tv/%s> will be posted every day at 00:00 UTC.":                "Un résumé quotidien de <https://twitch.tv/%s> sera publié chaque jour à 00:00 UTC.",
+		"A message will be posted once a timeout of <https://twitch.tv/%s> ends.":                         "Un message sera publié à la fin d'un timeout sur <https://twitch.tv/%s>.",
+		"A weekly digest for <https://twitch.tv/%s> will be posted every monday at 00:00 UTC.":            "Un résumé hebdomadaire de <https://twitch.tv/%s> sera publié chaque lundi à 00:00 UTC.",
+		"Added the note to case `#%v`.":                                                                   "Note ajoutée au cas `#%v`.",
+		"Approved the appeal, `%s` was unbanned from #%s.":                                                "Appel accepté, `%s` a été débanni de #%s.",
+		"Ban and timeout logs of <https://twitch.tv/%s> will no longer show the chat of the user.":        "Les logs de ban et de timeout de <https://twitch.tv/%s> n'afficheront plus le chat de l'utilisateur.",
+		"Ban and timeout logs of <https://twitch.tv/%s> will show the last %v chat messages of the user.": "Les logs de ban et de timeout de <https://twitch.tv/%s> afficheront les %v derniers messages de l'utilisateur.",
+		"Ban appeals are not posted in this discord.":                                                     "Les appels de ban ne sont pas publiés sur ce discord.",
+		"Ban appeals of <https://twitch.tv/%s> are no longer posted in this discord.":                     "Les appels de ban de <https://twitch.tv/%s> ne sont plus publiés sur ce discord.",
+		"Ban appeals of <https://twitch.tv/%s> will be posted in <#%s>, users can appeal at <%s/appeal>.": "Les appels de ban de <https://twitch.tv/%s> seront publiés dans <#%s>, les utilisateurs peuvent faire appel sur <%s/appeal>.",
+		"Banned `%s` from <https://twitch.tv/%s>.":                                                        "`%s` a été banni de <https://twitch.tv/%s>.",
+		"Deleted messages of <https://twitch.tv/%s> will be logged.":                                      "Les messages supprimés de <https://twitch.tv/%s> seront enregistrés.",
+		"Deleted messages of <https://twitch.tv/%s> will no longer be logged.":                            "Les messages supprimés de <https://twitch.tv/%s> ne seront plus enregistrés.",
+		"Denied the appeal.": "Appel refusé.",
+		"Digests are disabled for <https://twitch.tv/%s>.":                                           "Les résumés sont désactivés pour <https://twitch.tv/%s>.",
+		"Embed logs of <https://twitch.tv/%s> will no longer show the twitch profiles of the users.": "Les logs embed de <https://twitch.tv/%s> n'afficheront plus les profils twitch des utilisateurs.",
+		"Embed logs of <https://twitch.tv/%s> will show the twitch profiles of the users.":           "Les logs embed de <https://twitch.tv/%s> afficheront les profils twitch des utilisateurs.",
+		"Failed to listen to the chat, please try again later.":                                      "Impossible d'écouter le chat, veuillez réessayer plus tard.",
+		"Failed to listen to the deleted messages, please try again later.":                          "Impossible d'écouter les messages supprimés, veuillez réessayer plus tard.",
+		"Internal Server Error. Please try again later...":                                           "Erreur interne du serveur. Veuillez réessayer plus tard...",
+		"Internal Server Error.":                         "Erreur interne du serveur.",
+		"Internal server error occured.":                 "Une erreur interne du serveur est survenue.",
+		"Internal server error. Please try again later.": "Erreur interne du serveur. Veuillez réessayer plus tard.",
+		"Invalid appeal.":                                "Appel invalide.",
+		"Invalid case.":                                  "Cas invalide.",
+		"Invalid duration, use a value such as 10m or 2h, up to two weeks.":                         "Durée invalide, utilisez une valeur comme 10m ou 2h, deux semaines au plus.",
+		"Invalid duration, use a value such as 30m or 2h.":                                          "Durée invalide, utilisez une valeur comme 30m ou 2h.",
+		"Invalid unban request.":                                                                    "Demande de déban invalide.",
+		"Logs can only be outputted into a text channel.":                                           "Les logs ne peuvent être publiés que dans un salon textuel.",
+		"No twitch account used that username.":                                                     "Aucun compte twitch n'a utilisé ce nom d'utilisateur.",
+		"No user is banned on more than one hooked channel.":                                        "Aucun utilisateur n'est banni sur plus d'une chaîne liée.",
+		"Please enter a note.":                                                                      "Veuillez saisir une note.",
+		"Please enter a reply.":                                                                     "Veuillez saisir une réponse.",
+		"Please enter a rule id as shown by /ignored.":                                              "Veuillez saisir un identifiant de règle tel qu'affiché par /ignored.",
+		"Please enter a valid https url.":                                                           "Veuillez saisir une url https valide.",
+		"Please enter a valid user or a reason pattern.":                                            "Veuillez saisir un utilisateur valide ou un motif de raison.",
+		"Please enter a valid user or rule.":                                                        "Veuillez saisir un utilisateur ou une règle valide.",
+		"Please enter the dates as YYYY-MM-DD.":                                                     "Veuillez saisir les dates au format AAAA-MM-JJ.",
+		"Please provide the token from the login page, or the broadcaster to send a login link to.": "Veuillez fournir le jeton de la page de connexion, ou le streamer à qui envoyer un lien de connexion.",
+		"Please select a channel in this discord.":                                                  "Veuillez choisir un salon de ce discord.",
+		"Please select a sub command.":                                                              "Veuillez choisir une sous-commande.",
+		"Please select a text channel of this discord.":                                             "Veuillez choisir un salon textuel de ce discord.",
+		"Please select a valid channel.":                                                            "Veuillez choisir un salon valide.",
+		"Please select a valid language.":                                                           "Veuillez choisir une langue valide.",
+		"Please select a valid role.":                                                               "Veuillez choisir un rôle valide.",
+		"Removed %v output(s) for <https://twitch.tv/%s>.":                                          "%v sortie(s) supprimée(s) pour <https://twitch.tv/%s>.",
+		"Stopped alerting about %s.":                                                                "Plus d'alertes pour %s.",
+		"Successfully ignored %s.":                                                                  "%s est maintenant ignoré.",
+		"Successfully unignored %s.":                                                                "%s n'est plus ignoré.",
+		"That appeal doesn't belong to a broadcaster reviewed in this discord.":                     "Cet appel n'appartient à aucun streamer examiné sur ce discord.",
+		"That appeal was already resolved.":                                                         "Cet appel a déjà été traité.",
+		"That broadcaster has no outputs.":                                                          "Ce streamer n'a aucune sortie.",
+		"That broadcaster is not hooked in that channel.":                                           "Ce streamer n'est pas lié dans ce salon.",
+		"That broadcaster is not hooked in this discord, use /add first.":                           "Ce streamer n'est pas lié sur ce discord, utilisez d'abord /add.",
+		"That broadcaster is not hooked in this discord.":                                           "Ce streamer n'est pas lié sur ce discord.",
+		"That case is not a timeout.":                                                               "Ce cas n'est pas un timeout.",
+		"That hook doesn't exist":                                                                   "Cette liaison n'existe pas",
+		"That unban request doesn't belong to a broadcaster hooked in this discord.":                "Cette demande de déban n'appartient à aucun streamer lié sur ce discord.",
+		"That unban request was already resolved.":                                                  "Cette demande de déban a déjà été traitée.",
+		"The bot can't read that chat, the broadcaster or the moderator who added the hook must log in on the website again to allow it.": "Le bot ne peut pas lire ce chat, le streamer ou le modérateur qui a ajouté la liaison doit se reconnecter sur le site pour l'autoriser.",
+		"The bot now replies and logs in %s.":                                          "Le bot répond et publie maintenant les logs en %s.",
+		"The count has to be at least 2 and the window between 1 and 1440 minutes.":    "Le nombre doit être d'au moins 2 et la fenêtre entre 1 et 1440 minutes.",
+		"The hook channels will be alerted about %v %s within %v minutes.":             "Les salons liés seront alertés de %v %s en %v minutes.",
+		"The number of messages must be between 0 and %v.":                             "Le nombre de messages doit être compris entre 0 et %v.",
+		"The reason is not a valid regular expression.":                                "La raison n'est pas une expression régulière valide.",
+		"The specified broadcaster does not exist.":                                    "Le streamer indiqué n'existe pas.",
+		"The specified user does not exist.":                                           "L'utilisateur indiqué n'existe pas.",
+		"The start date has to be before the end date.":                                "La date de début doit précéder la date de fin.",
+		"The unban request of `%s` was %s.":                                            "La demande de déban de `%s` a été %s.",
+		"There are no hooks in this discord.":                                          "Il n'y a aucune liaison sur ce discord.",
+		"There are no ignore rules for %s.":                                            "Il n'y a aucune règle d'ignorance pour %s.",
+		"There are no ignored users.":                                                  "Il n'y a aucun utilisateur ignoré.",
+		"There have to be at least two hooked broadcasters in this discord.":           "Il faut au moins deux streamers liés sur ce discord.",
+		"Timed out `%s` in <https://twitch.tv/%s> for %s.":                             "`%s` a reçu un timeout sur <https://twitch.tv/%s> pour %s.",
+		"Timeout expiry messages are disabled for <https://twitch.tv/%s>.":             "Les messages de fin de timeout sont désactivés pour <https://twitch.tv/%s>.",
+		"Timeout logs of <https://twitch.tv/%s> will be edited once the timeout ends.": "Les logs de timeout de <https://twitch.tv/%s> seront modifiés à la fin du timeout.",
+		"Unbanned `%s` from <https://twitch.tv/%s>.":                                   "`%s` a été débanni de <https://twitch.tv/%s>.",
+		"Unknown action `%s`, the actions are %s.":                                     "Action `%s` inconnue, les actions sont %s.",
+		"You do not have permission to execute that command.":                          "Vous n'avez pas la permission d'exécuter cette commande.",
+		"You do not have permission to use that button.":                               "Vous n'avez pas la permission d'utiliser ce bouton.",
+		"That user is no longer timed out, time them out again instead.":               "Cet utilisateur n'est plus en timeout, remets-le plutôt en timeout.",
+		"That case doesn't belong to a broadcaster hooked in this discord.":            "Ce cas n'appartient à aucun streamer lié à ce discord.",
+		"No one who authorized a hook of that channel in this discord can moderate it, the broadcaster or a moderator has to authorize a hook here before actions can be taken from discord.": "Personne ayant autorisé un hook de cette chaîne dans ce discord ne peut la modérer, le streamer ou un modérateur doit autoriser un hook ici avant que des actions puissent être faites depuis discord.",
+		"The stored twitch login is missing the permission for that action, the broadcaster or a moderator has to login again.":                                                               "La connexion twitch enregistrée n'a pas la permission pour cette action, le streamer ou un modérateur doit se reconnecter.",
+		"bans in a channel":                    "bans dans une chaîne",
+		"bans by a single moderator":           "bans par un seul modérateur",
+		"timeouts of the same user":            "timeouts du même utilisateur",
+		"%v %s within %v minutes":              "%v %s en %v minutes",
+		"There are no alerts in this discord.": "Il n'y a aucune alerte dans ce discord.",
+		"Alerts ping <@&%s>.":                  "Les alertes mentionnent <@&%s>.",
+		"Alerts now ping <@&%s>.":              "Les alertes mentionnent maintenant <@&%s>.",
+		"Alerts no longer ping a role.":        "Les alertes ne mentionnent plus de rôle.",
+		"<https://twitch.tv/%s> can start logging into %s by logging in at <%s/login?link=%s>, the link will expire in 24 hours.": "<https://twitch.tv/%s> peut commencer à logger dans %s en se connectant sur <%s/login?link=%s>, le lien expire dans 24 heures.",
+		"ModLogs hook %s for <https://twitch.tv/%s>, into %s":                                                                     "Hook ModLogs %s pour <https://twitch.tv/%s>, dans %s",
+		"added":   "ajouté",
+		"updated": "mis à jour",
+		"Users banned on several hooked channels:": "Utilisateurs bannis sur plusieurs chaînes liées :",
+		"and %v more...": "et %v de plus...",
+		"There are too many hooks in this discord. (%v/%v)": "Il y a trop de hooks dans ce discord. (%v/%v)",
+		"The broadcaster has not authorized ModLogs and the moderator who authorized the channel did not grant every permission needed to read its moderation actions, they have to login again.": "Le streamer n'a pas autorisé ModLogs et le modérateur qui a autorisé la chaîne n'a pas accordé toutes les permissions nécessaires pour lire ses actions de modération, il doit se reconnecter.",
+		"Failed to create a webhook in that channel, make sure the bot has the Manage Webhooks permission.":                                                                                       "Impossible de créer un webhook dans ce salon, vérifie que le bot a la permission Gérer les webhooks.",
+		"actions on `%s`":                        "les actions sur `%s`",
+		"actions by or on `%s`":                  "les actions par ou sur `%s`",
+		"actions by `%s`":                        "les actions par `%s`",
+		"all actions":                            "toutes les actions",
+		"of type %s":                             "de type %s",
+		"with a reason matching `%s`":            "avec une raison correspondant à `%s`",
+		"in #%s":                                 "dans #%s",
+		"posted into <#%s>":                      "postées dans <#%s>",
+		"Ignore rules:":                          "Règles d'ignorance :",
+		"the streamer":                           "le streamer",
+		"their moderator <https://twitch.tv/%s>": "son modérateur <https://twitch.tv/%s>",
+		"Failed to add the ModLogs hook for <https://twitch.tv/%s>: %s":                                 "Impossible d'ajouter le hook ModLogs pour <https://twitch.tv/%s> : %s",
+		"ModLogs hook %s for <https://twitch.tv/%s>, into <#%s>. Requested by <@%s>, authorized by %s.": "Hook ModLogs %s pour <https://twitch.tv/%s>, dans <#%s>. Demandé par <@%s>, autorisé par %s.",
+		"Current username: [%s](https://twitch.tv/%s)":                                                  "Nom d'utilisateur actuel : [%s](https://twitch.tv/%s)",
+		"Previously known as: %s":            "Anciennement connu sous : %s",
+		"Logged actions: %v":                 "Actions loggées : %v",
+		"Twitch accounts which used `%s`.":   "Comptes twitch ayant utilisé `%s`.",
+		"Note on case #%s":                   "Note sur le cas #%s",
+		"Why did it happen? Links to clips?": "Pourquoi c'est arrivé ? Des liens vers des clips ?",
+		"Extend timeout #%s":                 "Prolonger le timeout #%s",
+		"Extra time, such as 30m or 2h":      "Temps supplémentaire, comme 30m ou 2h",
+		"Approve the appeal":                 "Accepter l'appel",
+		"Deny the appeal":                    "Refuser l'appel",
+		"Reply shown to the user":            "Réponse montrée à l'utilisateur",
+		"Approve the unban request":          "Accepter la demande de déban",
+		"Deny the unban request":             "Refuser la demande de déban",
+		"Resolution shown to the user":       "Décision montrée à l'utilisateur",
+		"Managers: %s\nReaders: %s\nThe server owner and administrators can always use every command.": "Gestionnaires : %s\nLecteurs : %s\nLe propriétaire du serveur et les administrateurs peuvent toujours utiliser toutes les commandes.",
+		"Please enter a Slack incoming webhook url, such as https://hooks.slack.com/services/....":     "Merci d'entrer une url de webhook entrant Slack, comme https://hooks.slack.com/services/....",
+		"Please enter a valid https url of a public homeserver.":                                       "Merci d'entrer une url https valide d'un homeserver public.",
+		"Slack webhook":                     "Webhook Slack",
+		"Matrix room `%s`":                  "Salon Matrix `%s`",
+		"No outputs were found":             "Aucune sortie n'a été trouvée",
+		"minimal":                           "minimal",
+		"rich":                              "riche",
+		"all hooked channels":               "toutes les chaînes liées",
+		"the last day":                      "le dernier jour",
+		"the last 7 days":                   "les 7 derniers jours",
+		"the last 30 days":                  "les 30 derniers jours",
+		"all time":                          "toute la période",
+		"Moderation actions on %s over %s.": "Actions de modération sur %s sur %s.",
+		"No moderation activity.":           "Aucune activité de modération.",
+		"Bans: %v\nTimeouts: %v (avg %s)\nUnbans: %v\nUnban ratio: %.0f%%":                        "Bans : %v\nTimeouts : %v (moy. %s)\nDébans : %v\nTaux de déban : %.0f%%",
+		"The chart follows the order above, bans in red, timeouts in orange and unbans in green.": "Le graphique suit l'ordre ci-dessus, les bans en rouge, les timeouts en orange et les débans en vert.",
+		"None": "Aucun",
+		"The token you provided is expired or invalid. Please login again to make a new one.": "Le token fourni est expiré ou invalide. Veuillez vous reconnecter pour en créer un nouveau.",
+		"The hook has been removed.":   "Le hook a été supprimé.",
+		"The hooks have been removed.": "Les hooks ont été supprimés.",
+		"This bot can be invited to a server by going to <%s/login>.\nThis is an opensource bot and it's free, <https://github.com/troydota/modlogs>": "Ce bot peut être invité sur un serveur en allant sur <%s/login>.\nC'est un bot open source et gratuit, <https://github.com/troydota/modlogs>",
+		"rule `%s`": "règle `%s`",
+		"Moderation logs for <https://twitch.tv/%s>.":                                                         "Logs de modération pour <https://twitch.tv/%s>.",
+		"Your export for <https://twitch.tv/%s> is ready at <%s/export/%s>, the link will expire in an hour.": "Votre export pour <https://twitch.tv/%s> est prêt sur <%s/export/%s>, le lien expirera dans une heure.",
+		"No moderation logs of that broadcaster were posted in this discord.":                                 "Aucun log de modération de ce streamer n'a été posté dans ce discord.",
+		"%s The appeal couldn't be set back to pending, it stays approved although `%s` is still banned.":     "%s L'appel n'a pas pu être remis en attente, il reste approuvé alors que `%s` est toujours banni.",
+
+		// Logs
+		"**%s: #%s** - `%s` executed `/%s`":                                 "**%s : #%s** - `%s` a exécuté `/%s`",
+		"%s (issued from discord by <@%s>)":                                 "%s (lancé depuis discord par <@%s>)",
+		"%s (other channels: %s)":                                           "%s (autres chaînes : %s)",
+		"**Message Deleted Event: #%s** - %s deleted a message of `%s`: %s": "**Message supprimé : #%s** - %s a supprimé un message de `%s` : %s",
+		"A moderator":                       "Un modérateur",
+		"User Ban Event":                    "Utilisateur banni",
+		"User Timeout Event":                "Timeout d'un utilisateur",
+		"User Unban Event":                  "Utilisateur débanni",
+		"User Mod Event":                    "Utilisateur nommé modérateur",
+		"User Unmod Event":                  "Modérateur retiré",
+		"Message Deleted Event":             "Message supprimé",
+		"Unban Request":                     "Demande de déban",
+		"Unban Request Approved":            "Demande de déban acceptée",
+		"Unban Request Denied":              "Demande de déban refusée",
+		"Unban Request Canceled":            "Demande de déban annulée",
+		"Broadcaster":                       "Streamer",
+		"User":                              "Utilisateur",
+		"Moderator":                         "Modérateur",
+		"Reason":                            "Raison",
+		"Expires":                           "Expire",
+		"Case":                              "Cas",
+		"Previously Known As":               "Anciennement",
+		"Issued From Discord":               "Lancé depuis Discord",
+		"Other Channels":                    "Autres chaînes",
+		"Recent Chat":                       "Messages récents",
+		"Message":                           "Message",
+		"Account Created":                   "Compte créé",
+		"Request":                           "Demande",
+		"Resolved By":                       "Traitée par",
+		"Resolution":                        "Décision",
+		"None Provided":                     "Non précisée",
+		"Unknown":                           "Inconnu",
+		"Unban":                             "Débannir",
+		"Extend timeout":                    "Prolonger le timeout",
+		"View history":                      "Voir l'historique",
+		"Add note":                          "Ajouter une note",
+		"Approve":                           "Accepter",
+		"Deny":                              "Refuser",
+		"Notes":                             "Notes",
+		"↳ Note on `#%v`: %s":               "↳ Note sur `#%v` : %s",
+		"Status":                            "Statut",
+		"Expired at %s":                     "Expiré le %s",
+		"Lifted early by %s at %s":          "Levé plus tôt par %s le %s",
+		"Superseded by a ban from %s at %s": "Remplacé par un ban de %s le %s",
+		"Superseded by a new timeout from %s at %s":     "Remplacé par un nouveau timeout de %s le %s",
+		"**User Timeout Ended: #%s** - `%s`: %s (<%s>)": "**Fin du timeout : #%s** - `%s` : %s (<%s>)",
+		"Reversed":                 "Annulé",
+		"Reversed by %s at %s":     "Annulé par %s le %s",
+		"[Unban message](%s)":      "[Message de déban](%s)",
+		"↳ Reversed by `%s` at %s": "↳ Annulé par `%s` le %s",
+		"**Unban Request: #%s** - `%s` (created %s): %s": "**Demande de déban : #%s** - `%s` (créé %s) : %s",
+		"the user":                  "l'utilisateur",
+		"↳ %s by `%s` at %s":        "↳ %s par `%s` le %s",
+		"Approved":                  "Acceptée",
+		"Denied":                    "Refusée",
+		"Canceled":                  "Annulée",
+		"Moderation Activity Alert": "Alerte d'activité de modération",
+		"%v users were banned in #%s within %v minutes.":      "%v utilisateurs ont été bannis dans #%s en %v minutes.",
+		"%s banned %v users in #%s within %v minutes.":        "%s a banni %v utilisateurs dans #%s en %v minutes.",
+		"%s was timed out %v times in #%s within %v minutes.": "%s a reçu %v timeouts dans #%s en %v minutes.",
+		"Moderator Statistics":                                "Statistiques des modérateurs",
+		"User History":                                        "Historique de l'utilisateur",
+		"Ban":                                                 "Ban",
+		"Timeout (%s)":                                        "Timeout (%s)",
+		"By %s on %s":                                         "Par %s le %s",
+		"Reason: %s":                                          "Raison : %s",
+		"#%v %s in #%s":                                       "#%v %s dans #%s",
+		"Latest actions against %s.":                          "Dernières actions contre %s.",
+		"No actions against %s were logged.":                  "Aucune action contre %s n'a été loggée.",
+		"Previously known as %s.":                             "Anciennement connu sous %s.",
+		"User Search":                                         "Recherche d'utilisateur",
+		"Ban Appeal":                                          "Appel de ban",
+		"Ban Appeal Approved":                                 "Appel de ban accepté",
+		"Ban Appeal Denied":                                   "Appel de ban refusé",
+		"Banned":                                              "Banni",
+		"Ban Reason":                                          "Raison du ban",
+		"Appeal":                                              "Appel",
+		"Response":                                            "Réponse",
+		"Moderation Digest: #%s":                              "Résumé de modération : #%s",
+		"%s to %s":                                            "%s au %s",
+		"Actions per moderator":                               "Actions par modérateur",
+		"Most actioned users":                                 "Utilisateurs les plus sanctionnés",
+		"Busiest hours":                                       "Heures les plus chargées",
+		"Mod team changes":                                    "Changements de l'équipe de modération",
+		"`%s` - %v bans, %v timeouts, %v unbans":              "`%s` - %v bans, %v timeouts, %v débans",
+		"`%s` - %v actions":                                   "`%s` - %v actions",
+		"%s - %v actions":                                     "%s - %v actions",
+		"Last chat messages of `%s` in #%s":                   "Derniers messages de `%s` sur #%s",
+		"%s (case `#%v`)":                                     "%s (cas `#%v`)",
+		"<https://twitch.tv/%s> revoked the ModLogs hook for this channel.": "<https://twitch.tv/%s> a révoqué le hook ModLogs de ce salon.",
+		"#%s: banned %s ago":    "#%s : banni il y a %s",
+		"#%s: timed out %s ago": "#%s : timeout il y a %s",
+		"#%s: unbanned %s ago":  "#%s : débanni il y a %s",
+
+		// Commands
+		"Alerts the hook channels about unusual moderation activity.":                          "Alerte les salons liés en cas d'activité de modération inhabituelle.",
+		"Configure where the ban appeals of a broadcaster are reviewed.":                       "Configure où les appels de ban d'un streamer sont examinés.",
+		"Add the last chat messages of the user to the ban and timeout logs of a broadcaster.": "Ajoute les derniers messages de l'utilisateur aux logs de ban et de timeout d'un streamer.",
+		"Lists the users banned on several of the channels hooked in this discord.":            "Liste les utilisateurs bannis sur plusieurs des chaînes liées à ce discord.",
+		"Log the chat messages of a broadcaster deleted by moderators.":                        "Enregistre les messages d'un streamer supprimés par les modérateurs.",
+		"Configure or post a summary of the moderation activity of a broadcaster.":             "Configure ou publie un résumé de l'activité de modération d'un streamer.",
+		"Configure what happens to the timeout logs of a broadcaster once the timeout ends.":   "Configure ce que deviennent les logs de timeout d'un streamer à la fin du timeout.",
+		"Exports the moderation logs of a broadcaster as a file.":                              "Exporte les logs de modération d'un streamer dans un fichier.",
+		"Set the language of the bot replies and the logs in this discord.":                    "Définit la langue des réponses et des logs du bot sur ce discord.",
+		"The language to use.": "La langue à utiliser.",
+		"Ignore a user, such as a bot, or the actions matching a reason.":                 "Ignore un utilisateur, comme un bot, ou les actions correspondant à une raison.",
+		"Unignore a user that was previously ignored":                                     "N'ignore plus un utilisateur ignoré auparavant",
+		"Shows a list of the ignore rules.":                                               "Affiche la liste des règles d'ignorance.",
+		"Finds the twitch accounts which used a username, with their previous usernames.": "Trouve les comptes twitch ayant utilisé un nom d'utilisateur, avec leurs anciens noms.",
+		"Attach context, such as why it happened or a clip, to a logged action.":          "Ajoute du contexte, comme la raison ou un clip, à une action enregistrée.",
+		"Shows the logged actions against a twitch user, with their notes.":               "Affiche les actions enregistrées contre un utilisateur twitch, avec leurs notes.",
+		"Manage which roles can use the modlogs commands.":                                "Gère les rôles pouvant utiliser les commandes modlogs.",
+		"Show the twitch profiles of the users in the embed logs of a broadcaster.":       "Affiche les profils twitch des utilisateurs dans les logs embed d'un streamer.",
+		"Manage Slack and Matrix outputs for the hooks in this discord.":                  "Gère les sorties Slack et Matrix des liaisons de ce discord.",
+		"Shows moderator activity statistics for the hooked broadcasters.":                "Affiche les statistiques d'activité des modérateurs des streamers liés.",
+		"Ban, timeout or unban a user on twitch.":                                         "Bannit, met en timeout ou débannit un utilisateur sur twitch.",
+		"Shows a list of current hooks in this discord.":                                  "Affiche la liste des liaisons actuelles de ce discord.",
+		"The ID or name of the twitch streamer.":                                          "L'ID ou le nom du streamer twitch.",
+		"Text channel where the hook is active.":                                          "Salon textuel où la liaison est active.",
+		"The id or username of the twitch account.":                                       "L'id ou le nom d'utilisateur du compte twitch.",
+		"The id, current or old username of the twitch account.":                          "L'id, le nom d'utilisateur actuel ou ancien du compte twitch.",
+	},
+}
@@ -0,0 +1,72 @@
+package bot
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+)
+
+const (
+	chartWidth   = 640
+	chartHeight  = 320
+	chartPadding = 20
+)
+
+var (
+	chartBackground = color.RGBA{R: 47, G: 49, B: 54, A: 255}
+	chartAxis       = color.RGBA{R: 185, G: 187, B: 190, A: 255}
+	// Bans and unbans use the colors of their embeds, timeouts share the red of the bans there so they get orange here.
+	chartColors = []color.RGBA{
+		{R: 208, G: 2, B: 27, A: 255},
+		{R: 245, G: 166, B: 35, A: 255},
+		{R: 126, G: 211, B: 33, A: 255},
+	}
+)
+
+// stackedBarChart draws one stacked bar per entry, every entry holds one value per chart color.
+func stackedBarChart(values [][]int) ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, chartWidth, chartHeight))
+	draw.Draw(img, img.Bounds(), &image.Uniform{C: chartBackground}, image.Point{}, draw.Src)
+
+	max := 0
+	for _, v := range values {
+		total := 0
+		for _, n := range v {
+			total += n
+		}
+		if total > max {
+			max = total
+		}
+	}
+
+	bottom := chartHeight - chartPadding
+	height := chartHeight - 2*chartPadding
+
+	draw.Draw(img, image.Rect(chartPadding, chartPadding, chartPadding+1, bottom+1), &image.Uniform{C: chartAxis}, image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(chartPadding, bottom, chartWidth-chartPadding, bottom+1), &image.Uniform{C: chartAxis}, image.Point{}, draw.Src)
+
+	if len(values) != 0 && max != 0 {
+		slot := (chartWidth - 2*chartPadding) / len(values)
+		barWidth := slot * 2 / 3
+		for i, v := range values {
+			x := chartPadding + i*slot + (slot-barWidth)/2 + 1
+			y := bottom
+			for j, n := range v {
+				h := n * height / max
+				if h == 0 {
+					continue
+				}
+				draw.Draw(img, image.Rect(x, y-h, x+barWidth, y), &image.Uniform{C: chartColors[j%len(chartColors)]}, image.Point{}, draw.Src)
+				y -= h
+			}
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	if err := png.Encode(buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
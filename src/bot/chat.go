@@ -0,0 +1,304 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	log "github.com/sirupsen/logrus"
+	"github.com/troydota/modlogs/src/api"
+	"github.com/troydota/modlogs/src/auth"
+	"github.com/troydota/modlogs/src/mongo"
+	"github.com/troydota/modlogs/src/redis"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	// How many messages are kept for each user of a hooked channel.
+	chatBufferSize = 20
+	// Messages of users who stopped chatting are dropped after this.
+	chatBufferExpiry = time.Hour
+	chatMessageType  = "channel.chat.message"
+)
+
+var errNoChatToken = fmt.Errorf("no token can read the chat")
+
+var chatContextCommand = &discordgo.ApplicationCommand{
+	Name:        "chatcontext",
+	Description: "Add the last chat messages of the user to the ban and timeout logs of a broadcaster.",
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "broadcaster",
+			Description: "The ID or name of the twitch streamer.",
+			Required:    true,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionInteger,
+			Name:        "messages",
+			Description: fmt.Sprintf("How many messages to show, up to %v, 0 disables it.", chatBufferSize),
+			Required:    true,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionBoolean,
+			Name:        "attachment",
+			Description: "Post the messages as a text file after the log instead of in the embed.",
+			Required:    false,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionChannel,
+			Name:        "channel",
+			Description: "Text channel where the hook is active.",
+			Required:    false,
+		},
+	},
+}
+
+// ChatMessage is a message sent in a hooked channel.
+type ChatMessage struct {
+	ID     string    `json:"id"`
+	Text   string    `json:"text"`
+	SentAt time.Time `json:"sent_at"`
+}
+
+func chatBufferKey(broadcasterID string, userID string) string {
+	return fmt.Sprintf("chat:%s:%s", broadcasterID, userID)
+}
+
+// StoreChatMessage adds the message to the buffer of the user, only the latest messages are kept.
+func StoreChatMessage(ctx context.Context, broadcasterID string, userID string, msg *ChatMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	key := chatBufferKey(broadcasterID, userID)
+	pipe := redis.Client.Pipeline()
+	pipe.LPush(ctx, key, data)
+	pipe.LTrim(ctx, key, 0, chatBufferSize-1)
+	pipe.Expire(ctx, key, chatBufferExpiry)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// chatMessages returns the last messages of the user, oldest first.
+func chatMessages(broadcasterID string, userID string, count int) ([]*ChatMessage, error) {
+	values, err := redis.Client.LRange(context.Background(), chatBufferKey(broadcasterID, userID), 0, int64(count-1)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	messages := []*ChatMessage{}
+	for i := len(values) - 1; i >= 0; i-- {
+		msg := &ChatMessage{}
+		if err := json.Unmarshal([]byte(values[i]), msg); err != nil {
+			log.WithError(err).Error("redis")
+			continue
+		}
+		messages = append(messages, msg)
+	}
+	return messages, nil
+}
+
+// chatLines formats the messages, one per line.
+func chatLines(messages []*ChatMessage) []string {
+	lines := []string{}
+	for _, m := range messages {
+		lines = append(lines, fmt.Sprintf("[%s] %s", m.SentAt.UTC().Format("15:04:05"), m.Text))
+	}
+	return lines
+}
+
+// chatContextValue shows the messages in an embed field, dropping the oldest ones which don't fit.
+func chatContextValue(messages []*ChatMessage) string {
+	lines := chatLines(messages)
+	for len(lines) != 0 {
+		value := fmt.Sprintf("```\n%s\n```", strings.ReplaceAll(strings.Join(lines, "\n"), "`", "'"))
+		if len(value) <= 1024 {
+			return value
+		}
+		lines = lines[1:]
+	}
+	return ""
+}
+
+// chatContextFile is the text attachment of the messages.
+func chatContextFile(e *mongo.Event, messages []*ChatMessage) *discordgo.File {
+	return &discordgo.File{
+		Name:        fmt.Sprintf("chat-%s-%s.txt", e.BroadcasterUserName, e.UserName),
+		ContentType: "text/plain",
+		Reader:      strings.NewReader(strings.Join(chatLines(messages), "\n")),
+	}
+}
+
+// wantsChatContext returns the most messages a hook of the event wants, zero when none does.
+func wantsChatContext(e *mongo.Event, hooks []*mongo.Hook) int {
+	kind := eventKind(e)
+	if kind != kindBan && kind != kindTimeout {
+		return 0
+	}
+
+	count := 0
+	for _, hook := range hooks {
+		if int(hook.ChatContext) > count {
+			count = int(hook.ChatContext)
+		}
+	}
+	return count
+}
+
+// lastMessages returns the latest messages of a list from chatMessages.
+func lastMessages(messages []*ChatMessage, count int) []*ChatMessage {
+	if len(messages) > count {
+		return messages[len(messages)-count:]
+	}
+	return messages
+}
+
+// webhookSubscribed is true when the subscription of the streamer was confirmed by twitch.
+func webhookSubscribed(hookType string, streamerID string) (bool, error) {
+	id, err := redis.Client.HGet(context.Background(), fmt.Sprintf("webhook:twitch:%s:%s", hookType, streamerID), "id").Result()
+	if err != nil && err != redis.ErrNil {
+		return false, err
+	}
+	return id != "", nil
+}
+
+// chatReader returns the user the chat of the streamer is read as, the broadcaster or a moderator who authorized one
+// of the hooks of the guild, they must have granted the chat scopes when they logged in.
+func chatReader(guildID string, streamerID string) (string, error) {
+	candidates, err := hookAuthorizers(context.Background(), guildID, streamerID)
+	if err != nil {
+		return "", err
+	}
+
+	for _, userID := range candidates {
+		ok, err := auth.HasUserScopes(context.Background(), userID, "user:read:chat", "user:bot")
+		if err == auth.ErrNoUserToken || (err == nil && !ok) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		return userID, nil
+	}
+
+	return "", errNoChatToken
+}
+
+// subscribeChat reads the chat of the streamer when it wasn't done yet.
+func subscribeChat(guildID string, streamerID string) error {
+	ok, err := webhookSubscribed(chatMessageType, streamerID)
+	if err != nil || ok {
+		return err
+	}
+
+	userID, err := chatReader(guildID, streamerID)
+	if err != nil {
+		return err
+	}
+	return api.CreateChatWebhooks(context.Background(), streamerID, userID)
+}
+
+// unsubscribeChat stops reading the chat of the streamer once no hook uses it anymore.
+func unsubscribeChat(streamerID string) error {
+	count, err := mongo.Database.Collection("hooks").CountDocuments(context.Background(), bson.M{
+		"streamer_id": streamerID,
+		"$or": bson.A{
+			bson.M{"chat_context": bson.M{"$gt": 0}},
+			bson.M{"deletions": true},
+		},
+	})
+	if err != nil || count != 0 {
+		return err
+	}
+
+	if err := api.RevokeWebhook(context.Background(), streamerID, api.ChatHooks...); err != nil {
+		return err
+	}
+	return redis.Client.Del(context.Background(), fmt.Sprintf("webhook:twitch:%s:%s", chatMessageType, streamerID)).Err()
+}
+
+func chatContextHandler(s *discordgo.Session, i *discordgo.InteractionCreate, g *discordgo.Guild) {
+	var broadcaster string
+	var count int64
+	var attachment bool
+	var channel *discordgo.Channel
+
+	for _, o := range i.Data.Options {
+		switch o.Name {
+		case "broadcaster":
+			broadcaster = o.StringValue()
+		case "messages":
+			count = o.IntValue()
+		case "attachment":
+			attachment = o.BoolValue()
+		case "channel":
+			channel = o.ChannelValue(s)
+		}
+	}
+
+	if count < 0 || count > chatBufferSize {
+		respondf(s, i, true, "The number of messages must be between 0 and %v.", chatBufferSize)
+		return
+	}
+
+	user, err := hookedBroadcaster(g.ID, broadcaster)
+	if err != nil {
+		if err == errNotHooked {
+			respond(s, i, "That broadcaster is not hooked in this discord.", true)
+			return
+		}
+		log.WithError(err).Error("mongo")
+		respond(s, i, "Internal server error. Please try again later.", true)
+		return
+	}
+
+	if count != 0 {
+		if err := subscribeChat(g.ID, user.ID); err != nil {
+			if err == errNoChatToken {
+				respond(s, i, "The bot can't read that chat, the broadcaster or the moderator who added the hook must log in on the website again to allow it.", true)
+				return
+			}
+			log.WithError(err).Error("api")
+			respond(s, i, "Failed to listen to the chat, please try again later.", true)
+			return
+		}
+	}
+
+	filter := bson.M{
+		"guild_id":    g.ID,
+		"streamer_id": user.ID,
+	}
+	if channel != nil {
+		filter["channel_id"] = channel.ID
+	}
+
+	res, err := mongo.Database.Collection("hooks").UpdateMany(context.Background(), filter, bson.M{
+		"$set": bson.M{
+			"chat_context":    count,
+			"chat_attachment": attachment,
+		},
+	})
+	if err != nil {
+		log.WithError(err).Error("mongo")
+		respond(s, i, "Internal server error. Please try again later.", true)
+		return
+	}
+	if res.MatchedCount == 0 {
+		respond(s, i, "That broadcaster is not hooked in that channel.", true)
+		return
+	}
+
+	if count == 0 {
+		if err := unsubscribeChat(user.ID); err != nil {
+			log.WithError(err).Error("api")
+		}
+		respondf(s, i, false, "Ban and timeout logs of <https://twitch.tv/%s> will no longer show the chat of the user.", user.Login)
+		return
+	}
+
+	respondf(s, i, false, "Ban and timeout logs of <https://twitch.tv/%s> will show the last %v chat messages of the user.", user.Login, count)
+}
@@ -0,0 +1,101 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/troydota/modlogs/src/mongo"
+)
+
+func TestChatContextValue(t *testing.T) {
+	at := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	message := func(text string, seconds int) *ChatMessage {
+		return &ChatMessage{Text: text, SentAt: at.Add(time.Duration(seconds) * time.Second)}
+	}
+
+	long := []*ChatMessage{}
+	for i := 0; i < 20; i++ {
+		long = append(long, message(strings.Repeat("a", 89), i))
+	}
+
+	tests := []struct {
+		name     string
+		messages []*ChatMessage
+		want     string
+	}{
+		{"no messages", nil, ""},
+		{"messages", []*ChatMessage{message("hello", 0), message("world", 5)}, "```\n[12:00:00] hello\n[12:00:05] world\n```"},
+		{"backticks", []*ChatMessage{message("```escape```", 0)}, "```\n[12:00:00] '''escape'''\n```"},
+		{"too long for a field", []*ChatMessage{message(strings.Repeat("a", 1100), 0)}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := chatContextValue(tt.messages); got != tt.want {
+				t.Errorf("chatContextValue = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("drops the oldest messages", func(t *testing.T) {
+		got := chatContextValue(long)
+		if len(got) > 1024 {
+			t.Fatalf("value is %d long", len(got))
+		}
+		lines := strings.Split(strings.Trim(got, "`\n"), "\n")
+		// Each line takes 101 bytes with its newline and the code block 7 more, so ten lines fit.
+		if len(lines) != 10 {
+			t.Errorf("kept %d messages, want 10", len(lines))
+		}
+		if !strings.HasPrefix(lines[len(lines)-1], "[12:00:19]") || !strings.HasPrefix(lines[0], "[12:00:10]") {
+			t.Errorf("kept %q to %q, want the latest messages", lines[0], lines[len(lines)-1])
+		}
+	})
+}
+
+func TestLastMessages(t *testing.T) {
+	messages := []*ChatMessage{{ID: "1"}, {ID: "2"}, {ID: "3"}}
+
+	tests := []struct {
+		count int
+		want  string
+	}{
+		{0, ""},
+		{2, "2,3"},
+		{3, "1,2,3"},
+		{5, "1,2,3"},
+	}
+
+	for _, tt := range tests {
+		ids := []string{}
+		for _, m := range lastMessages(messages, tt.count) {
+			ids = append(ids, m.ID)
+		}
+		if got := strings.Join(ids, ","); got != tt.want {
+			t.Errorf("lastMessages(%d) = %q, want %q", tt.count, got, tt.want)
+		}
+	}
+}
+
+func TestWantsChatContext(t *testing.T) {
+	expires := time.Now()
+	hooks := []*mongo.Hook{{ChatContext: 5}, {ChatContext: 0}, {ChatContext: 15}}
+
+	tests := []struct {
+		name  string
+		event *mongo.Event
+		want  int
+	}{
+		{"ban", &mongo.Event{Action: "channel.ban"}, 15},
+		{"timeout", &mongo.Event{Action: "channel.ban", Expires: &expires}, 15},
+		{"unban", &mongo.Event{Action: "channel.unban"}, 0},
+		{"mod", &mongo.Event{Action: "channel.moderator.add"}, 0},
+	}
+
+	for _, tt := range tests {
+		if got := wantsChatContext(tt.event, hooks); got != tt.want {
+			t.Errorf("wantsChatContext(%s) = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
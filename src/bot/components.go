@@ -0,0 +1,195 @@
+package bot
+
+import (
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	log "github.com/sirupsen/logrus"
+)
+
+// The version of discordgo we use predates message components, so they are sent and received as raw json.
+
+const (
+	componentActionRow = 1
+	componentButton    = 2
+	componentTextInput = 4
+)
+
+const (
+	buttonPrimary   = 1
+	buttonSecondary = 2
+	buttonSuccess   = 3
+	buttonDanger    = 4
+	buttonLink      = 5
+)
+
+const (
+	interactionComponent   = 3
+	interactionModalSubmit = 5
+)
+
+const (
+	responseDeferredUpdate = 6
+	responseUpdateMessage  = 7
+	responseModal          = 9
+)
+
+type component struct {
+	Type       int          `json:"type"`
+	Style      int          `json:"style,omitempty"`
+	Label      string       `json:"label,omitempty"`
+	CustomID   string       `json:"custom_id,omitempty"`
+	URL        string       `json:"url,omitempty"`
+	Disabled   bool         `json:"disabled,omitempty"`
+	Required   bool         `json:"required,omitempty"`
+	MaxLength  int          `json:"max_length,omitempty"`
+	Value      string       `json:"value,omitempty"`
+	Components []*component `json:"components,omitempty"`
+}
+
+type componentData struct {
+	CustomID   string       `json:"custom_id"`
+	Components []*component `json:"components"`
+}
+
+type componentInteraction struct {
+	Type    int                `json:"type"`
+	Message *discordgo.Message `json:"message"`
+	Data    componentData      `json:"data"`
+}
+
+// value returns the value of a text input submitted with a modal.
+func (c *componentInteraction) value(customID string) string {
+	for _, row := range c.Data.Components {
+		for _, v := range row.Components {
+			if v.CustomID == customID {
+				return v.Value
+			}
+		}
+	}
+	return ""
+}
+
+type componentHandler func(s *discordgo.Session, i *discordgo.InteractionCreate, c *componentInteraction, g *discordgo.Guild, arg string)
+
+// componentRoute is the handler of a component and the permission level needed to use it.
+type componentRoute struct {
+	level   int
+	handler componentHandler
+}
+
+// componentHandlers are keyed by the custom id prefix, the rest of the custom id after the colon is passed as arg.
+var componentHandlers = map[string]componentRoute{}
+
+func actionRow(components ...*component) *component {
+	return &component{Type: componentActionRow, Components: components}
+}
+
+// channelMessageSendComponents posts an embed with components as the bot.
+func channelMessageSendComponents(s *discordgo.Session, channelID string, embed *discordgo.MessageEmbed, components []*component) (*discordgo.Message, error) {
+	endpoint := discordgo.EndpointChannelMessages(channelID)
+	body, err := s.RequestWithBucketID("POST", endpoint, map[string]interface{}{
+		"embed":            embed,
+		"components":       components,
+		"allowed_mentions": noMentions(),
+	}, endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := &discordgo.Message{}
+	err = json.Unmarshal(body, msg)
+	return msg, err
+}
+
+// channelMessageEditComponents replaces the embed and the components of a message posted by the bot, an empty list removes the components.
+func channelMessageEditComponents(s *discordgo.Session, channelID string, messageID string, embed *discordgo.MessageEmbed, components []*component) error {
+	_, err := s.RequestWithBucketID("PATCH", discordgo.EndpointChannelMessage(channelID, messageID), map[string]interface{}{
+		"embed":      embed,
+		"components": components,
+	}, discordgo.EndpointChannelMessage(channelID, ""))
+	return err
+}
+
+// interactionRespond answers an interaction with a response type discordgo doesn't know about.
+func interactionRespond(s *discordgo.Session, i *discordgo.InteractionCreate, responseType int, data interface{}) error {
+	endpoint := discordgo.EndpointInteractionResponse(i.ID, i.Token)
+	_, err := s.RequestWithBucketID("POST", endpoint, map[string]interface{}{
+		"type": responseType,
+		"data": data,
+	}, endpoint)
+	return err
+}
+
+// showModal opens a form with a single paragraph text input.
+func showModal(s *discordgo.Session, i *discordgo.InteractionCreate, customID string, title string, label string) error {
+	return interactionRespond(s, i, responseModal, map[string]interface{}{
+		"custom_id": customID,
+		"title":     title,
+		"components": []*component{
+			actionRow(&component{
+				Type:      componentTextInput,
+				CustomID:  "text",
+				Label:     label,
+				Style:     2,
+				Required:  true,
+				MaxLength: 1000,
+			}),
+		},
+	})
+}
+
+// handleComponent runs the handler of a button press or a modal submit, when the member is allowed to.
+func handleComponent(s *discordgo.Session, raw []byte) {
+	c := &componentInteraction{}
+	if err := json.Unmarshal(raw, c); err != nil {
+		log.WithError(err).Error("discord")
+		return
+	}
+	if c.Type != interactionComponent && c.Type != interactionModalSubmit {
+		return
+	}
+
+	i := &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{}}
+	if err := json.Unmarshal(raw, i.Interaction); err != nil {
+		log.WithError(err).Error("discord")
+		return
+	}
+
+	parts := strings.SplitN(c.Data.CustomID, ":", 2)
+	route, ok := componentHandlers[parts[0]]
+	if !ok {
+		return
+	}
+	var arg string
+	if len(parts) == 2 {
+		arg = parts[1]
+	}
+
+	var guild *discordgo.Guild
+	for _, g := range s.State.Guilds {
+		if g.ID == i.GuildID {
+			guild = g
+			break
+		}
+	}
+
+	if guild == nil || i.Member == nil {
+		respond(s, i, "Internal Server Error. Please try again later...", true)
+		return
+	}
+
+	level, err := memberPermission(s, i, guild)
+	if err != nil {
+		log.WithError(err).Error("mongo")
+		respond(s, i, "Internal Server Error. Please try again later...", true)
+		return
+	}
+
+	if level < route.level {
+		respond(s, i, "You do not have permission to use that button.", true)
+		return
+	}
+
+	route.handler(s, i, c, guild, arg)
+}
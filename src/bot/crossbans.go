@@ -0,0 +1,221 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	log "github.com/sirupsen/logrus"
+	"github.com/troydota/modlogs/src/mongo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// How far back actions on other channels are shown next to a ban.
+const crossChannelPeriod = 30 * 24 * time.Hour
+
+var crossbansCommand = &discordgo.ApplicationCommand{
+	Name:        "crossbans",
+	Description: "Lists the users banned on several of the channels hooked in this discord.",
+}
+
+// guildStreamers returns the hooked streamer ids of each guild.
+func guildStreamers(guildIDs []string) (map[string][]string, error) {
+	hooks := []*mongo.Hook{}
+	cur, err := mongo.Database.Collection("hooks").Find(context.Background(), bson.M{"guild_id": bson.M{"$in": guildIDs}})
+	if err == nil {
+		err = cur.All(context.Background(), &hooks)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	streamers := map[string][]string{}
+	seen := map[string]bool{}
+	for _, h := range hooks {
+		key := fmt.Sprintf("%s:%s", h.GuildID, h.StreamerID)
+		if !seen[key] {
+			seen[key] = true
+			streamers[h.GuildID] = append(streamers[h.GuildID], h.StreamerID)
+		}
+	}
+
+	return streamers, nil
+}
+
+// crossChannelSummaries describes, for each guild, the recent actions against the target of the event on the other streamers hooked in that guild.
+// Guilds without any such actions are left out.
+func crossChannelSummaries(e *mongo.Event, guildIDs []string) map[string]string {
+	if kind := eventKind(e); kind != kindBan && kind != kindTimeout {
+		return nil
+	}
+
+	streamers, err := guildStreamers(guildIDs)
+	if err != nil {
+		log.WithError(err).Error("mongo")
+		return nil
+	}
+
+	others := []string{}
+	for _, ids := range streamers {
+		for _, id := range ids {
+			if id != e.BroadcasterID {
+				others = append(others, id)
+			}
+		}
+	}
+	if len(others) == 0 {
+		return nil
+	}
+
+	events, err := findEvents(bson.M{
+		"user_id": e.UserID,
+		"broadcaster_id": bson.M{
+			"$in": others,
+		},
+		"action": bson.M{
+			"$in": bson.A{"channel.ban", "channel.unban"},
+		},
+		"created_at": bson.M{
+			"$gte": time.Now().Add(-crossChannelPeriod),
+		},
+	})
+	if err != nil {
+		log.WithError(err).Error("mongo")
+		return nil
+	}
+
+	// Events are sorted by date, so the last one of each broadcaster is their current state.
+	latest := map[string]*mongo.Event{}
+	order := []string{}
+	for _, ev := range events {
+		if _, ok := latest[ev.BroadcasterID]; !ok {
+			order = append(order, ev.BroadcasterID)
+		}
+		latest[ev.BroadcasterID] = ev
+	}
+
+	summaries := map[string]string{}
+	for guildID, ids := range streamers {
+		lang := guildLanguage(guildID)
+		lines := []string{}
+		for _, id := range order {
+			hooked := false
+			for _, v := range ids {
+				if v == id {
+					hooked = true
+					break
+				}
+			}
+			if !hooked {
+				continue
+			}
+
+			ev := latest[id]
+			ago := formatDuration(time.Since(ev.CreatedAt))
+			switch eventKind(ev) {
+			case kindBan:
+				lines = append(lines, tr(lang, "#%s: banned %s ago", ev.BroadcasterUserName, ago))
+			case kindTimeout:
+				lines = append(lines, tr(lang, "#%s: timed out %s ago", ev.BroadcasterUserName, ago))
+			case kindUnban:
+				lines = append(lines, tr(lang, "#%s: unbanned %s ago", ev.BroadcasterUserName, ago))
+			}
+		}
+		if len(lines) != 0 {
+			summaries[guildID] = strings.Join(lines, "\n")
+		}
+	}
+
+	return summaries
+}
+
+type crossBan struct {
+	UserID   string   `bson:"_id"`
+	UserName string   `bson:"user_name"`
+	Channels []string `bson:"channels"`
+}
+
+func crossbansHandler(s *discordgo.Session, i *discordgo.InteractionCreate, g *discordgo.Guild) {
+	streamers, err := guildStreamers([]string{g.ID})
+	if err != nil {
+		log.WithError(err).Error("mongo")
+		respond(s, i, "Internal server error. Please try again later.", true)
+		return
+	}
+
+	if len(streamers[g.ID]) < 2 {
+		respond(s, i, "There have to be at least two hooked broadcasters in this discord.", true)
+		return
+	}
+
+	pipeline := bson.A{
+		bson.M{"$match": bson.M{
+			"broadcaster_id": bson.M{
+				"$in": streamers[g.ID],
+			},
+			"action": bson.M{
+				"$in": bson.A{"channel.ban", "channel.unban"},
+			},
+		}},
+		bson.M{"$sort": bson.M{"created_at": 1}},
+		bson.M{"$group": bson.M{
+			"_id": bson.M{
+				"broadcaster_id": "$broadcaster_id",
+				"user_id":        "$user_id",
+			},
+			"broadcaster_user_name": bson.M{"$last": "$broadcaster_user_name"},
+			"user_name":             bson.M{"$last": "$user_name"},
+			"action":                bson.M{"$last": "$action"},
+			"expires":               bson.M{"$last": "$expires"},
+		}},
+		// Only users who are still banned, a timeout or an unban ends the ban.
+		bson.M{"$match": bson.M{
+			"action":  "channel.ban",
+			"expires": nil,
+		}},
+		bson.M{"$group": bson.M{
+			"_id":       "$_id.user_id",
+			"user_name": bson.M{"$last": "$user_name"},
+			"channels":  bson.M{"$push": "$broadcaster_user_name"},
+			"count":     bson.M{"$sum": 1},
+		}},
+		bson.M{"$match": bson.M{
+			"count": bson.M{"$gte": 2},
+		}},
+		bson.M{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "user_name", Value: 1}}},
+	}
+
+	bans := []*crossBan{}
+	cur, err := mongo.Database.Collection("events").Aggregate(context.Background(), pipeline, options.Aggregate().SetAllowDiskUse(true))
+	if err == nil {
+		err = cur.All(context.Background(), &bans)
+	}
+	if err != nil {
+		log.WithError(err).Error("mongo")
+		respond(s, i, "Internal server error. Please try again later.", true)
+		return
+	}
+
+	if len(bans) == 0 {
+		respond(s, i, "No user is banned on more than one hooked channel.", true)
+		return
+	}
+
+	lang := guildLanguage(g.ID)
+	lines := []string{tr(lang, "Users banned on several hooked channels:")}
+	length := len(lines[0])
+	for n, b := range bans {
+		line := fmt.Sprintf("`%s` - #%s", strings.ReplaceAll(b.UserName, "`", ""), strings.Join(b.Channels, ", #"))
+		if length+len(line)+1 > 1900 {
+			lines = append(lines, tr(lang, "and %v more...", len(bans)-n))
+			break
+		}
+		length += len(line) + 1
+		lines = append(lines, line)
+	}
+
+	respond(s, i, strings.Join(lines, "\n"), true)
+}
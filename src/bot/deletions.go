@@ -0,0 +1,337 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	log "github.com/sirupsen/logrus"
+	"github.com/troydota/modlogs/src/api"
+	"github.com/troydota/modlogs/src/mongo"
+	"github.com/troydota/modlogs/src/redis"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	deletionType = "channel.chat.message_delete"
+	// Twitch sends the moderate notification next to the deletion, so the log waits a moment for it.
+	deletionWait = 3 * time.Second
+	// How long the moderator of a deletion is kept.
+	deletionExpiry = 2 * time.Minute
+)
+
+var deletionsCommand = &discordgo.ApplicationCommand{
+	Name:        "deletions",
+	Description: "Log the chat messages of a broadcaster deleted by moderators.",
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "broadcaster",
+			Description: "The ID or name of the twitch streamer.",
+			Required:    true,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionBoolean,
+			Name:        "enabled",
+			Description: "Posts the deleted message, its sender and the moderator who deleted it.",
+			Required:    true,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionChannel,
+			Name:        "channel",
+			Description: "Text channel where the hook is active.",
+			Required:    false,
+		},
+	},
+}
+
+// ChatDeletion is a chat message deleted on twitch.
+type ChatDeletion struct {
+	MessageID           string
+	BroadcasterID       string
+	BroadcasterUserName string
+	UserID              string
+	UserName            string
+	DeletedAt           time.Time
+}
+
+// Deletions receives the chat messages deleted in the hooked channels.
+var Deletions = make(chan ChatDeletion)
+
+// deletionModerator is who deleted a message, as told by the moderate notification.
+type deletionModerator struct {
+	ModeratorID   string `json:"moderator_id"`
+	ModeratorName string `json:"moderator_name"`
+	Text          string `json:"text"`
+}
+
+func deletionKey(broadcasterID string, messageID string) string {
+	return fmt.Sprintf("temp:deletions:%s:%s", broadcasterID, messageID)
+}
+
+// StoreDeletionModerator keeps who deleted the message until the deletion is logged.
+func StoreDeletionModerator(ctx context.Context, broadcasterID string, messageID string, moderatorID string, moderatorName string, text string) error {
+	data, _ := json.Marshal(deletionModerator{
+		ModeratorID:   moderatorID,
+		ModeratorName: moderatorName,
+		Text:          text,
+	})
+	return redis.Client.Set(ctx, deletionKey(broadcasterID, messageID), data, deletionExpiry).Err()
+}
+
+// deletedMessage finds the deleted message in the chat buffer of the user.
+func deletedMessage(d ChatDeletion) (*ChatMessage, error) {
+	messages, err := chatMessages(d.BroadcasterID, d.UserID, chatBufferSize)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range messages {
+		if m.ID == d.MessageID {
+			return m, nil
+		}
+	}
+	return nil, nil
+}
+
+// subscribeDeletions listens to the deleted messages of the streamer, the chat is read too for their text.
+// The moderate notifications need more scopes, failing them only leaves the moderator out of the logs.
+func subscribeDeletions(guildID string, streamerID string) error {
+	if err := subscribeChat(guildID, streamerID); err != nil {
+		return err
+	}
+
+	ok, err := webhookSubscribed(deletionType, streamerID)
+	if err != nil || ok {
+		return err
+	}
+
+	userID, err := chatReader(guildID, streamerID)
+	if err != nil {
+		return err
+	}
+	if err := api.CreateChatWebhooks(context.Background(), streamerID, userID, api.DeletionHooks...); err != nil {
+		return err
+	}
+
+	// Channels authorized by a moderator already read their actions from channel.moderate.
+	if ok, err := webhookSubscribed(api.ModerateHooks[0].Name, streamerID); err != nil {
+		log.WithError(err).Error("redis")
+	} else if !ok {
+		if err := api.CreateModerateWebhooks(context.Background(), streamerID, userID); err != nil {
+			log.WithError(err).WithField("streamer", streamerID).Warn("moderate")
+		}
+	}
+
+	return nil
+}
+
+// unsubscribeDeletions stops listening to the deleted messages once no hook logs them anymore.
+func unsubscribeDeletions(streamerID string) error {
+	count, err := mongo.Database.Collection("hooks").CountDocuments(context.Background(), bson.M{
+		"streamer_id": streamerID,
+		"deletions":   true,
+	})
+	if err != nil || count != 0 {
+		return err
+	}
+
+	hooks := append([]api.Hook{}, api.DeletionHooks...)
+	// channel.moderate is kept when it sends the actions of a channel authorized by a moderator.
+	banned, err := webhookSubscribed("channel.ban", streamerID)
+	if err != nil {
+		return err
+	}
+	if banned {
+		hooks = append(hooks, api.ModerateHooks...)
+	}
+	if err := api.RevokeWebhook(context.Background(), streamerID, hooks...); err != nil {
+		return err
+	}
+	for _, h := range hooks {
+		if err := redis.Client.Del(context.Background(), fmt.Sprintf("webhook:twitch:%s:%s", h.Name, streamerID)).Err(); err != nil {
+			return err
+		}
+	}
+
+	return unsubscribeChat(streamerID)
+}
+
+func (b *Bot) processDeletion(d ChatDeletion) {
+	time.Sleep(deletionWait)
+
+	moderator := deletionModerator{}
+	data, err := redis.Client.Get(context.Background(), deletionKey(d.BroadcasterID, d.MessageID)).Bytes()
+	if err == nil {
+		if err := json.Unmarshal(data, &moderator); err != nil {
+			log.WithError(err).Error("redis")
+		}
+	} else if err != redis.ErrNil {
+		log.WithError(err).Error("redis")
+	}
+
+	text := moderator.Text
+	if text == "" {
+		msg, err := deletedMessage(d)
+		if err != nil {
+			log.WithError(err).Error("redis")
+		} else if msg != nil {
+			text = msg.Text
+		}
+	}
+
+	hooks := []*mongo.Hook{}
+	cur, err := mongo.Database.Collection("hooks").Find(context.Background(), bson.M{
+		"streamer_id": d.BroadcasterID,
+		"deletions":   true,
+	})
+	if err == nil {
+		err = cur.All(context.Background(), &hooks)
+	}
+	if err != nil {
+		log.WithError(err).Error("mongo")
+		return
+	}
+
+	// The text is written by the chatter, so it is quoted and never looked up in the catalog, only the placeholder is.
+	message := "Unknown"
+	if text != "" {
+		message = codeSpan(text)
+	}
+	fields := []*discordgo.MessageEmbedField{
+		{Name: "Broadcaster", Value: d.BroadcasterUserName},
+		{Name: "User", Value: d.UserName},
+	}
+	if moderator.ModeratorName != "" {
+		fields = append(fields, &discordgo.MessageEmbedField{Name: "Moderator", Value: moderator.ModeratorName})
+	}
+	fields = append(fields, &discordgo.MessageEmbedField{Name: "Message", Value: message})
+
+	embed := &discordgo.MessageEmbed{
+		Title:       "Message Deleted Event",
+		Description: "_ _",
+		Color:       9807270,
+		Timestamp:   d.DeletedAt.Format(time.RFC3339),
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "KomodoHype",
+		},
+		Fields: fields,
+	}
+
+	minimalText := func(lang string) string {
+		executer := tr(lang, "A moderator")
+		if moderator.ModeratorName != "" {
+			executer = fmt.Sprintf("`%s`", strings.ReplaceAll(moderator.ModeratorName, "`", ""))
+		}
+		deleted := message
+		if text == "" {
+			deleted = tr(lang, "Unknown")
+		}
+		line := tr(lang, "**Message Deleted Event: #%s** - %s deleted a message of `%s`: %s", d.BroadcasterUserName, executer, strings.ReplaceAll(d.UserName, "`", ""), deleted)
+		if runes := []rune(line); len(runes) > 1900 {
+			line = string(runes[:1900]) + "...`"
+		}
+		return line
+	}
+
+	event := &mongo.Event{
+		BroadcasterID:       d.BroadcasterID,
+		BroadcasterUserName: d.BroadcasterUserName,
+		ModeratorID:         moderator.ModeratorID,
+		ModeratorUserName:   moderator.ModeratorName,
+		UserID:              d.UserID,
+		UserName:            d.UserName,
+		Action:              deletionType,
+		CreatedAt:           d.DeletedAt,
+	}
+
+	for _, hook := range hooks {
+		if isIgnored(hook.GuildID, hook.ChannelID, event) {
+			continue
+		}
+
+		lang := guildLanguage(hook.GuildID)
+		if hook.Mode == mongo.ModeEmbed {
+			_, err = b.sendEmbed(hook, localizeEmbed(embed, lang))
+		} else {
+			_, err = b.sendMessage(hook, minimalText(lang))
+		}
+		if err != nil {
+			log.WithError(err).WithField("hook", hook).Error("discord")
+		}
+	}
+}
+
+func deletionsHandler(s *discordgo.Session, i *discordgo.InteractionCreate, g *discordgo.Guild) {
+	var broadcaster string
+	var enabled bool
+	var channel *discordgo.Channel
+
+	for _, o := range i.Data.Options {
+		switch o.Name {
+		case "broadcaster":
+			broadcaster = o.StringValue()
+		case "enabled":
+			enabled = o.BoolValue()
+		case "channel":
+			channel = o.ChannelValue(s)
+		}
+	}
+
+	user, err := hookedBroadcaster(g.ID, broadcaster)
+	if err != nil {
+		if err == errNotHooked {
+			respond(s, i, "That broadcaster is not hooked in this discord.", true)
+			return
+		}
+		log.WithError(err).Error("mongo")
+		respond(s, i, "Internal server error. Please try again later.", true)
+		return
+	}
+
+	if enabled {
+		if err := subscribeDeletions(g.ID, user.ID); err != nil {
+			if err == errNoChatToken {
+				respond(s, i, "The bot can't read that chat, the broadcaster or the moderator who added the hook must log in on the website again to allow it.", true)
+				return
+			}
+			log.WithError(err).Error("api")
+			respond(s, i, "Failed to listen to the deleted messages, please try again later.", true)
+			return
+		}
+	}
+
+	filter := bson.M{
+		"guild_id":    g.ID,
+		"streamer_id": user.ID,
+	}
+	if channel != nil {
+		filter["channel_id"] = channel.ID
+	}
+
+	res, err := mongo.Database.Collection("hooks").UpdateMany(context.Background(), filter, bson.M{
+		"$set": bson.M{
+			"deletions": enabled,
+		},
+	})
+	if err != nil {
+		log.WithError(err).Error("mongo")
+		respond(s, i, "Internal server error. Please try again later.", true)
+		return
+	}
+	if res.MatchedCount == 0 {
+		respond(s, i, "That broadcaster is not hooked in that channel.", true)
+		return
+	}
+
+	if !enabled {
+		if err := unsubscribeDeletions(user.ID); err != nil {
+			log.WithError(err).Error("api")
+		}
+		respondf(s, i, false, "Deleted messages of <https://twitch.tv/%s> will no longer be logged.", user.Login)
+		return
+	}
+
+	respondf(s, i, false, "Deleted messages of <https://twitch.tv/%s> will be logged.", user.Login)
+}
@@ -0,0 +1,416 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	log "github.com/sirupsen/logrus"
+	"github.com/troydota/modlogs/src/mongo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var digestCommand = &discordgo.ApplicationCommand{
+	Name:        "digest",
+	Description: "Configure or post a summary of the moderation activity of a broadcaster.",
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "broadcaster",
+			Description: "The ID or name of the twitch streamer.",
+			Required:    true,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "cadence",
+			Description: "How often the digest is posted.",
+			Required:    false,
+			Choices: []*discordgo.ApplicationCommandOptionChoice{
+				{Name: "off", Value: "off"},
+				{Name: "daily", Value: "daily"},
+				{Name: "weekly", Value: "weekly"},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionChannel,
+			Name:        "channel",
+			Description: "Text channel where the hook is active.",
+			Required:    false,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionBoolean,
+			Name:        "now",
+			Description: "Post a digest right away.",
+			Required:    false,
+		},
+	},
+}
+
+// digestPeriod returns how much time a digest covers.
+func digestPeriod(digest int32) time.Duration {
+	if digest == mongo.DigestWeekly {
+		return 7 * 24 * time.Hour
+	}
+	return 24 * time.Hour
+}
+
+// nextDigest returns the next midnight (UTC) for daily digests, or the next monday for weekly digests.
+func nextDigest(digest int32, now time.Time) time.Time {
+	next := now.UTC().Truncate(24 * time.Hour).Add(24 * time.Hour)
+	if digest == mongo.DigestWeekly {
+		for next.Weekday() != time.Monday {
+			next = next.Add(24 * time.Hour)
+		}
+	}
+	return next
+}
+
+func digestHandler(s *discordgo.Session, i *discordgo.InteractionCreate, g *discordgo.Guild) {
+	var broadcaster string
+	var channel *discordgo.Channel
+	var cadence string
+	var now bool
+
+	for _, o := range i.Data.Options {
+		switch o.Name {
+		case "broadcaster":
+			broadcaster = strings.ToLower(o.StringValue())
+		case "cadence":
+			cadence = o.StringValue()
+		case "channel":
+			channel = o.ChannelValue(s)
+		case "now":
+			now = o.BoolValue()
+		}
+	}
+
+	user, err := lookupUser(broadcaster)
+	if err != nil {
+		if err == errUnknownUser {
+			respond(s, i, "That broadcaster is not hooked in this discord.", true)
+			return
+		}
+		log.WithError(err).Error("api")
+		respond(s, i, "Internal server error. Please try again later.", true)
+		return
+	}
+
+	hooks := []*mongo.Hook{}
+	filter := bson.M{
+		"guild_id":    g.ID,
+		"streamer_id": user.ID,
+	}
+	if channel != nil {
+		filter["channel_id"] = channel.ID
+	}
+	cur, err := mongo.Database.Collection("hooks").Find(context.Background(), filter)
+	if err == nil {
+		err = cur.All(context.Background(), &hooks)
+	}
+	if err != nil {
+		log.WithError(err).Error("mongo")
+		respond(s, i, "Internal server error. Please try again later.", true)
+		return
+	}
+	if len(hooks) == 0 {
+		respond(s, i, "That broadcaster is not hooked in this discord.", true)
+		return
+	}
+
+	digest := hooks[0].Digest
+
+	if cadence != "" {
+		update := bson.M{}
+		switch cadence {
+		case "daily":
+			digest = mongo.DigestDaily
+		case "weekly":
+			digest = mongo.DigestWeekly
+		default:
+			digest = mongo.DigestOff
+		}
+		if digest == mongo.DigestOff {
+			update["$set"] = bson.M{"digest": digest}
+			update["$unset"] = bson.M{"digest_at": ""}
+		} else {
+			update["$set"] = bson.M{"digest": digest, "digest_at": nextDigest(digest, time.Now())}
+		}
+		if _, err := mongo.Database.Collection("hooks").UpdateMany(context.Background(), filter, update); err != nil {
+			log.WithError(err).Error("mongo")
+			respond(s, i, "Internal server error. Please try again later.", true)
+			return
+		}
+	}
+
+	if now {
+		period := digestPeriod(digest)
+		until := time.Now()
+		embed, err := buildDigest(user, until.Add(-period), until, guildLanguage(g.ID))
+		if err != nil {
+			log.WithError(err).Error("mongo")
+			respond(s, i, "Internal server error. Please try again later.", true)
+			return
+		}
+		err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionApplicationCommandResponseData{
+				Embeds: []*discordgo.MessageEmbed{embed},
+			},
+		})
+		if err != nil {
+			log.WithError(err).Error("discord")
+		}
+		return
+	}
+
+	switch digest {
+	case mongo.DigestDaily:
+		respondf(s, i, false, "A daily digest for <https://twitch.tv/%s> will be posted every day at 00:00 UTC.", user.Login)
+	case mongo.DigestWeekly:
+		respondf(s, i, false, "A weekly digest for <https://twitch.tv/%s> will be posted every monday at 00:00 UTC.", user.Login)
+	default:
+		respondf(s, i, false, "Digests are disabled for <https://twitch.tv/%s>.", user.Login)
+	}
+}
+
+type modCounts struct {
+	name     string
+	bans     int
+	timeouts int
+	unbans   int
+}
+
+func (c *modCounts) total() int {
+	return c.bans + c.timeouts + c.unbans
+}
+
+type rankedCount struct {
+	name  string
+	count int
+}
+
+func rank(counts map[string]int, limit int) []rankedCount {
+	ranked := []rankedCount{}
+	for k, v := range counts {
+		ranked = append(ranked, rankedCount{name: k, count: v})
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].count == ranked[j].count {
+			return ranked[i].name < ranked[j].name
+		}
+		return ranked[i].count > ranked[j].count
+	})
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	return ranked
+}
+
+// fieldValue joins the lines into an embed field value, cutting it short at discord's limit.
+func fieldValue(lines []string) string {
+	if len(lines) == 0 {
+		return "None"
+	}
+	value := ""
+	for _, l := range lines {
+		if len(value)+len(l)+1 > 1000 {
+			return value + "\n..."
+		}
+		if value != "" {
+			value += "\n"
+		}
+		value += l
+	}
+	return value
+}
+
+func findEvents(filter bson.M) ([]*mongo.Event, error) {
+	events := []*mongo.Event{}
+	cur, err := mongo.Database.Collection("events").Find(context.Background(), filter, options.Find().SetSort(bson.M{"created_at": 1}))
+	if err == nil {
+		err = cur.All(context.Background(), &events)
+	}
+	return events, err
+}
+
+// buildDigest summarises the stored events of the broadcaster between since and until.
+func buildDigest(user *mongo.User, since, until time.Time, lang string) (*discordgo.MessageEmbed, error) {
+	events, err := findEvents(bson.M{
+		"broadcaster_id": user.ID,
+		"created_at": bson.M{
+			"$gte": since,
+			"$lt":  until,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	mods := map[string]*modCounts{}
+	targets := map[string]int{}
+	hours := map[string]int{}
+	team := []string{}
+
+	for _, e := range events {
+		kind := eventKind(e)
+		switch kind {
+		case kindBan, kindTimeout, kindUnban:
+			name := e.ModeratorUserName
+			if name == "" {
+				name = e.BroadcasterUserName
+			}
+			c, ok := mods[name]
+			if !ok {
+				c = &modCounts{name: name}
+				mods[name] = c
+			}
+			switch kind {
+			case kindBan:
+				c.bans++
+				targets[e.UserName]++
+			case kindTimeout:
+				c.timeouts++
+				targets[e.UserName]++
+			case kindUnban:
+				c.unbans++
+			}
+			hours[fmt.Sprintf("%02d:00 UTC", e.CreatedAt.UTC().Hour())]++
+		case kindMod:
+			team = append(team, fmt.Sprintf("+ `%s` (%s)", e.UserName, e.CreatedAt.UTC().Format("Jan _2 15:04")))
+		case kindUnmod:
+			team = append(team, fmt.Sprintf("- `%s` (%s)", e.UserName, e.CreatedAt.UTC().Format("Jan _2 15:04")))
+		}
+	}
+
+	modList := []*modCounts{}
+	for _, c := range mods {
+		modList = append(modList, c)
+	}
+	sort.Slice(modList, func(i, j int) bool {
+		if modList[i].total() == modList[j].total() {
+			return modList[i].name < modList[j].name
+		}
+		return modList[i].total() > modList[j].total()
+	})
+
+	modLines := []string{}
+	for _, c := range modList {
+		modLines = append(modLines, tr(lang, "`%s` - %v bans, %v timeouts, %v unbans", c.name, c.bans, c.timeouts, c.unbans))
+	}
+
+	targetLines := []string{}
+	for _, r := range rank(targets, 5) {
+		targetLines = append(targetLines, tr(lang, "`%s` - %v actions", r.name, r.count))
+	}
+
+	hourLines := []string{}
+	for _, r := range rank(hours, 3) {
+		hourLines = append(hourLines, tr(lang, "%s - %v actions", r.name, r.count))
+	}
+
+	description := tr(lang, "%s to %s", discordTime(since), discordTime(until))
+	if len(events) == 0 {
+		description = fmt.Sprintf("%s\n%s", description, tr(lang, "No moderation activity."))
+	}
+
+	return localizeEmbed(&discordgo.MessageEmbed{
+		Title:       tr(lang, "Moderation Digest: #%s", user.Login),
+		Description: description,
+		Color:       3447003,
+		Timestamp:   until.Format(time.RFC3339),
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "KomodoHype",
+		},
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Actions per moderator", Value: fieldValue(modLines)},
+			{Name: "Most actioned users", Value: fieldValue(targetLines)},
+			{Name: "Busiest hours", Value: fieldValue(hourLines)},
+			{Name: "Mod team changes", Value: fieldValue(team)},
+		},
+	}, lang), nil
+}
+
+// runDigests posts the scheduled digests until the bot is stopped.
+func (b *Bot) runDigests() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-b.stopped:
+			return
+		case <-ticker.C:
+			b.postDigests()
+		}
+	}
+}
+
+func (b *Bot) postDigests() {
+	now := time.Now()
+
+	hooks := []*mongo.Hook{}
+	cur, err := mongo.Database.Collection("hooks").Find(context.Background(), bson.M{
+		"digest": bson.M{
+			"$ne": mongo.DigestOff,
+		},
+		"digest_at": bson.M{
+			"$lte": now,
+		},
+	})
+	if err == nil {
+		err = cur.All(context.Background(), &hooks)
+	}
+	if err != nil {
+		log.WithError(err).Error("mongo")
+		return
+	}
+
+	for _, hook := range hooks {
+		period := digestPeriod(hook.Digest)
+		next := nextDigest(hook.Digest, now)
+
+		// Claim the digest first, so that it is never posted twice.
+		res, err := mongo.Database.Collection("hooks").UpdateOne(context.Background(), bson.M{
+			"guild_id":    hook.GuildID,
+			"channel_id":  hook.ChannelID,
+			"streamer_id": hook.StreamerID,
+			"digest_at":   hook.DigestAt,
+		}, bson.M{
+			"$set": bson.M{
+				"digest_at": next,
+			},
+		})
+		if err != nil {
+			log.WithError(err).WithField("hook", hook).Error("mongo")
+			continue
+		}
+		if res.ModifiedCount == 0 {
+			continue
+		}
+
+		if _, err := b.conn.State.Guild(hook.GuildID); err != nil {
+			continue
+		}
+
+		users, err := resolveUsers([]string{hook.StreamerID}, nil)
+		user, ok := users[hook.StreamerID]
+		if !ok {
+			log.WithError(err).WithField("hook", hook).Error("api")
+			continue
+		}
+
+		until := hook.DigestAt.UTC()
+		embed, err := buildDigest(user, until.Add(-period), until, guildLanguage(hook.GuildID))
+		if err != nil {
+			log.WithError(err).WithField("hook", hook).Error("mongo")
+			continue
+		}
+
+		if _, err := b.sendEmbed(hook, embed); err != nil {
+			log.WithError(err).WithField("hook", hook).Error("discord")
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package bot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/troydota/modlogs/src/mongo"
+)
+
+func TestNextDigest(t *testing.T) {
+	berlin := time.FixedZone("CEST", 2*60*60)
+
+	tests := []struct {
+		name   string
+		digest int32
+		now    time.Time
+		want   time.Time
+	}{
+		{"daily", mongo.DigestDaily, time.Date(2021, 6, 2, 15, 30, 0, 0, time.UTC), time.Date(2021, 6, 3, 0, 0, 0, 0, time.UTC)},
+		{"daily at midnight", mongo.DigestDaily, time.Date(2021, 6, 2, 0, 0, 0, 0, time.UTC), time.Date(2021, 6, 3, 0, 0, 0, 0, time.UTC)},
+		{"daily in another zone", mongo.DigestDaily, time.Date(2021, 6, 3, 1, 0, 0, 0, berlin), time.Date(2021, 6, 3, 0, 0, 0, 0, time.UTC)},
+		{"weekly on a wednesday", mongo.DigestWeekly, time.Date(2021, 6, 2, 15, 30, 0, 0, time.UTC), time.Date(2021, 6, 7, 0, 0, 0, 0, time.UTC)},
+		{"weekly on a sunday", mongo.DigestWeekly, time.Date(2021, 6, 6, 23, 59, 0, 0, time.UTC), time.Date(2021, 6, 7, 0, 0, 0, 0, time.UTC)},
+		{"weekly on a monday", mongo.DigestWeekly, time.Date(2021, 6, 7, 0, 0, 0, 0, time.UTC), time.Date(2021, 6, 14, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nextDigest(tt.digest, tt.now); !got.Equal(tt.want) {
+				t.Errorf("nextDigest(%v) = %v, want %v", tt.now, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package bot
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	jsoniter "github.com/json-iterator/go"
+)
+
+var json = jsoniter.ConfigCompatibleWithStandardLibrary
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// multipartBody encodes the payload and the files the way discord expects message uploads.
+func multipartBody(payload interface{}, files []*discordgo.File) (string, []byte, error) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", nil, err
+	}
+
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Disposition", `form-data; name="payload_json"`)
+	h.Set("Content-Type", "application/json")
+
+	p, err := writer.CreatePart(h)
+	if err != nil {
+		return "", nil, err
+	}
+	if _, err := p.Write(data); err != nil {
+		return "", nil, err
+	}
+
+	for i, file := range files {
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file%d"; filename="%s"`, i, quoteEscaper.Replace(file.Name)))
+		contentType := file.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		h.Set("Content-Type", contentType)
+
+		p, err := writer.CreatePart(h)
+		if err != nil {
+			return "", nil, err
+		}
+		if _, err := io.Copy(p, file.Reader); err != nil {
+			return "", nil, err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return "", nil, err
+	}
+
+	return writer.FormDataContentType(), body.Bytes(), nil
+}
+
+// deferResponse acknowledges the interaction, the answer has to be sent later as a follow up.
+func deferResponse(s *discordgo.Session, i *discordgo.InteractionCreate, ephemeral bool) error {
+	data := &discordgo.InteractionApplicationCommandResponseData{}
+	if ephemeral {
+		// Makes the response ephemeral https://discord.com/developers/docs/interactions/slash-commands#interaction-response
+		data.Flags = 64
+	}
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: data,
+	})
+}
+
+// followupWithFiles sends a follow up message for a deferred interaction with file attachments,
+// the version of discordgo we use can only send them to channels.
+func followupWithFiles(s *discordgo.Session, i *discordgo.InteractionCreate, data *discordgo.WebhookParams, files ...*discordgo.File) error {
+	endpoint := discordgo.EndpointFollowupMessage(s.State.User.ID, i.Token)
+
+	contentType, body, err := multipartBody(data, files)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.RequestWithLockedBucket("POST", endpoint, contentType, body, s.Ratelimiter.LockBucket(endpoint), 0)
+	return err
+}
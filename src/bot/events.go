@@ -0,0 +1,96 @@
+package bot
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/troydota/modlogs/src/mongo"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	kindBan     = "ban"
+	kindTimeout = "timeout"
+	kindUnban   = "unban"
+	kindMod     = "mod"
+	kindUnmod   = "unmod"
+)
+
+// eventKind returns the short name of the action behind an event.
+func eventKind(e *mongo.Event) string {
+	switch e.Action {
+	case "channel.ban":
+		if e.Expires != nil {
+			return kindTimeout
+		}
+		return kindBan
+	case "channel.unban":
+		return kindUnban
+	case "channel.moderator.add":
+		return kindMod
+	case "channel.moderator.remove":
+		return kindUnmod
+	}
+	return e.Action
+}
+
+// callbackEvent converts the callback into the event stored in the database.
+func callbackEvent(cb WebhookRequest) *mongo.Event {
+	return &mongo.Event{
+		ID:                  cb.ID,
+		BroadcasterID:       cb.BroadcasterID,
+		BroadcasterUserName: cb.BroadcasterUserName,
+		ModeratorID:         cb.ModeratorID,
+		ModeratorUserName:   cb.ModeratorUserName,
+		UserID:              cb.UserID,
+		UserName:            cb.UserName,
+		Reason:              cb.Reason,
+		Action:              cb.Action,
+		Expires:             cb.Expires,
+		CreatedAt:           cb.CreatedAt,
+	}
+}
+
+// storeEvent keeps a copy of the event so that reports can be computed later on, and gives it a case number.
+// The number is stored with the event, so the events twitch sends again always find it.
+func storeEvent(event *mongo.Event) {
+	event.Case = 0
+	if storedCase(event) {
+		return
+	}
+
+	c, err := mongo.NextSequence(context.Background(), "cases")
+	if err != nil {
+		log.WithError(err).Error("mongo")
+	}
+	event.Case = c
+
+	if _, err := mongo.Database.Collection("events").InsertOne(context.Background(), event); err != nil {
+		event.Case = 0
+		if !mongo.IsDuplicateKeyError(err) || !storedCase(event) {
+			log.WithError(err).WithField("event", event).Error("mongo")
+		}
+	}
+}
+
+// storedCase sets the case number of the event when twitch sent it before, it is false when the event is new.
+func storedCase(event *mongo.Event) bool {
+	existing := &mongo.Event{}
+	err := mongo.Database.Collection("events").FindOne(context.Background(), bson.M{"id": event.ID}).Decode(existing)
+	if err != nil {
+		if err != mongo.ErrNoDocuments {
+			log.WithError(err).WithField("event", event).Error("mongo")
+		}
+		return false
+	}
+	event.Case = existing.Case
+	return true
+}
+
+// eventExecutor returns the id of the user who executed the action, the broadcaster when no moderator is set.
+func eventExecutor(e *mongo.Event) string {
+	if e.ModeratorID != "" {
+		return e.ModeratorID
+	}
+	return e.BroadcasterID
+}
@@ -0,0 +1,284 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	log "github.com/sirupsen/logrus"
+	"github.com/troydota/modlogs/src/mongo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Claims older than this are considered abandoned, such as when the bot restarted while handling the job.
+const timeoutClaimExpiry = 10 * time.Minute
+
+var expiryCommand = &discordgo.ApplicationCommand{
+	Name:        "expiry",
+	Description: "Configure what happens to the timeout logs of a broadcaster once the timeout ends.",
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "broadcaster",
+			Description: "The ID or name of the twitch streamer.",
+			Required:    true,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "mode",
+			Description: "Edit the timeout message or post a new one, minimal logs always get a new message.",
+			Required:    true,
+			Choices: []*discordgo.ApplicationCommandOptionChoice{
+				{Name: "off", Value: "off"},
+				{Name: "edit", Value: "edit"},
+				{Name: "followup", Value: "followup"},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionChannel,
+			Name:        "channel",
+			Description: "Text channel where the hook is active.",
+			Required:    false,
+		},
+	},
+}
+
+func expiryHandler(s *discordgo.Session, i *discordgo.InteractionCreate, g *discordgo.Guild) {
+	var broadcaster string
+	var channel *discordgo.Channel
+	expiry := mongo.ExpiryOff
+
+	for _, o := range i.Data.Options {
+		switch o.Name {
+		case "broadcaster":
+			broadcaster = strings.ToLower(o.StringValue())
+		case "mode":
+			switch o.StringValue() {
+			case "edit":
+				expiry = mongo.ExpiryEdit
+			case "followup":
+				expiry = mongo.ExpiryFollowup
+			}
+		case "channel":
+			channel = o.ChannelValue(s)
+		}
+	}
+
+	user, err := lookupUser(broadcaster)
+	if err != nil {
+		if err == errUnknownUser {
+			respond(s, i, "That broadcaster is not hooked in this discord.", true)
+			return
+		}
+		log.WithError(err).Error("api")
+		respond(s, i, "Internal server error. Please try again later.", true)
+		return
+	}
+
+	filter := bson.M{
+		"guild_id":    g.ID,
+		"streamer_id": user.ID,
+	}
+	if channel != nil {
+		filter["channel_id"] = channel.ID
+	}
+
+	var matched int64
+	res, err := mongo.Database.Collection("hooks").UpdateMany(context.Background(), filter, bson.M{
+		"$set": bson.M{
+			"expiry": expiry,
+		},
+	})
+	if err == nil {
+		matched = res.MatchedCount
+	}
+	if err != nil {
+		log.WithError(err).Error("mongo")
+		respond(s, i, "Internal server error. Please try again later.", true)
+		return
+	}
+	if matched == 0 {
+		respond(s, i, "That broadcaster is not hooked in this discord.", true)
+		return
+	}
+
+	switch expiry {
+	case mongo.ExpiryEdit:
+		respondf(s, i, false, "Timeout logs of <https://twitch.tv/%s> will be edited once the timeout ends.", user.Login)
+	case mongo.ExpiryFollowup:
+		respondf(s, i, false, "A message will be posted once a timeout of <https://twitch.tv/%s> ends.", user.Login)
+	default:
+		respondf(s, i, false, "Timeout expiry messages are disabled for <https://twitch.tv/%s>.", user.Login)
+	}
+}
+
+// scheduleTimeout stores the job that follows up on the timeout once it expires.
+func scheduleTimeout(e *mongo.Event) {
+	job := &mongo.TimeoutJob{
+		EventID:       e.ID,
+		BroadcasterID: e.BroadcasterID,
+		UserID:        e.UserID,
+		Expires:       *e.Expires,
+	}
+
+	if _, err := mongo.Database.Collection("timeouts").UpdateOne(context.Background(), bson.M{"event_id": e.ID}, bson.M{
+		"$setOnInsert": job,
+	}, options.Update().SetUpsert(true)); err != nil {
+		log.WithError(err).WithField("job", job).Error("mongo")
+	}
+}
+
+// runTimeouts handles the expired timeouts until the bot is stopped.
+func (b *Bot) runTimeouts() {
+	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-b.stopped:
+			return
+		case <-ticker.C:
+			b.processTimeouts()
+		}
+	}
+}
+
+func (b *Bot) processTimeouts() {
+	now := time.Now()
+
+	jobs := []*mongo.TimeoutJob{}
+	cur, err := mongo.Database.Collection("timeouts").Find(context.Background(), bson.M{
+		"expires": bson.M{
+			"$lte": now,
+		},
+	})
+	if err == nil {
+		err = cur.All(context.Background(), &jobs)
+	}
+	if err != nil {
+		log.WithError(err).Error("mongo")
+		return
+	}
+
+	for _, job := range jobs {
+		// Claim the job first, so that it is never handled twice.
+		res, err := mongo.Database.Collection("timeouts").UpdateOne(context.Background(), bson.M{
+			"event_id": job.EventID,
+			"$or": bson.A{
+				bson.M{"claimed_at": bson.M{"$exists": false}},
+				bson.M{"claimed_at": bson.M{"$lt": now.Add(-timeoutClaimExpiry)}},
+			},
+		}, bson.M{
+			"$set": bson.M{
+				"claimed_at": now,
+			},
+		})
+		if err != nil {
+			log.WithError(err).WithField("job", job).Error("mongo")
+			continue
+		}
+		if res.ModifiedCount == 0 {
+			continue
+		}
+
+		b.expireTimeout(job)
+
+		if _, err := mongo.Database.Collection("timeouts").DeleteOne(context.Background(), bson.M{"event_id": job.EventID}); err != nil {
+			log.WithError(err).WithField("job", job).Error("mongo")
+		}
+	}
+}
+
+// timeoutEnd returns the unban or ban which came before the expiry and superseded the timeout, nil when it expired.
+func timeoutEnd(e *mongo.Event) (*mongo.Event, error) {
+	events, err := findEvents(bson.M{
+		"broadcaster_id": e.BroadcasterID,
+		"user_id":        e.UserID,
+		"id": bson.M{
+			"$ne": e.ID,
+		},
+		"action": bson.M{
+			"$in": bson.A{"channel.ban", "channel.unban"},
+		},
+		"created_at": bson.M{
+			"$gt":  e.CreatedAt,
+			"$lte": *e.Expires,
+		},
+	})
+	if err != nil || len(events) == 0 {
+		return nil, err
+	}
+
+	return events[0], nil
+}
+
+// timeoutStatus describes how the timeout ended, next is the event which superseded it.
+func timeoutStatus(e *mongo.Event, next *mongo.Event, lang string) string {
+	if next == nil {
+		return tr(lang, "Expired at %s", discordTime(*e.Expires))
+	}
+
+	moderator := next.ModeratorUserName
+	if moderator == "" {
+		moderator = next.BroadcasterUserName
+	}
+	at := discordTime(next.CreatedAt)
+
+	switch eventKind(next) {
+	case kindUnban:
+		return tr(lang, "Lifted early by %s at %s", moderator, at)
+	case kindBan:
+		return tr(lang, "Superseded by a ban from %s at %s", moderator, at)
+	}
+	return tr(lang, "Superseded by a new timeout from %s at %s", moderator, at)
+}
+
+func (b *Bot) expireTimeout(job *mongo.TimeoutJob) {
+	e := &mongo.Event{}
+	if err := mongo.Database.Collection("events").FindOne(context.Background(), bson.M{"id": job.EventID}).Decode(e); err != nil {
+		log.WithError(err).WithField("job", job).Error("mongo")
+		return
+	}
+	if e.Expires == nil {
+		return
+	}
+
+	next, err := timeoutEnd(e)
+	if err != nil {
+		log.WithError(err).WithField("job", job).Error("mongo")
+		return
+	}
+
+	messages, err := findLogMessages(bson.M{"event_id": e.ID})
+	if err != nil {
+		log.WithError(err).WithField("job", job).Error("mongo")
+		return
+	}
+
+	for _, m := range messages {
+		hook, err := logMessageHook(m)
+		if err != nil {
+			log.WithError(err).WithField("message", m).Error("mongo")
+			continue
+		}
+		if hook == nil || hook.Expiry == mongo.ExpiryOff {
+			continue
+		}
+
+		lang := guildLanguage(hook.GuildID)
+		status := timeoutStatus(e, next, lang)
+
+		if hook.Expiry == mongo.ExpiryEdit && m.Mode == mongo.ModeEmbed {
+			if err := annotateLogMessage(b.conn, hook, m, "Status", status); err != nil {
+				log.WithError(err).WithField("message", m).Error("discord")
+			}
+			continue
+		}
+
+		text := tr(lang, "**User Timeout Ended: #%s** - `%s`: %s (<%s>)", e.BroadcasterUserName, strings.ReplaceAll(e.UserName, "`", ""), status, messageLink(m))
+		if _, err := b.sendMessage(hook, text); err != nil {
+			log.WithError(err).WithField("hook", hook).Error("discord")
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+	"github.com/troydota/modlogs/src/configure"
+	"github.com/troydota/modlogs/src/export"
+	"github.com/troydota/modlogs/src/mongo"
+	"github.com/troydota/modlogs/src/redis"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Discord refuses attachments over 8MB, bigger exports are handed out as a link instead.
+const maxAttachmentSize = 8 * 1024 * 1024
+
+var exportCommand = &discordgo.ApplicationCommand{
+	Name:        "export",
+	Description: "Exports the moderation logs of a broadcaster as a file.",
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "broadcaster",
+			Description: "The ID or name of the twitch streamer.",
+			Required:    true,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "format",
+			Description: "The file format, defaults to csv.",
+			Required:    false,
+			Choices: []*discordgo.ApplicationCommandOptionChoice{
+				{Name: "csv", Value: export.FormatCSV},
+				{Name: "json", Value: export.FormatJSON},
+				{Name: "ndjson", Value: export.FormatNDJSON},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "from",
+			Description: "First day to export, as YYYY-MM-DD.",
+			Required:    false,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "to",
+			Description: "Last day to export, as YYYY-MM-DD.",
+			Required:    false,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionBoolean,
+			Name:        "link",
+			Description: "Respond with a download link instead of an attachment.",
+			Required:    false,
+		},
+	},
+}
+
+func exportHandler(s *discordgo.Session, i *discordgo.InteractionCreate, g *discordgo.Guild) {
+	var broadcaster string
+	var link bool
+	req := &export.Request{
+		Format: export.FormatCSV,
+		To:     time.Now().UTC(),
+	}
+
+	for _, o := range i.Data.Options {
+		switch o.Name {
+		case "broadcaster":
+			broadcaster = strings.ToLower(o.StringValue())
+		case "format":
+			req.Format = o.StringValue()
+		case "from":
+			t, err := time.Parse("2006-01-02", o.StringValue())
+			if err != nil {
+				respond(s, i, "Please enter the dates as YYYY-MM-DD.", true)
+				return
+			}
+			req.From = t
+		case "to":
+			t, err := time.Parse("2006-01-02", o.StringValue())
+			if err != nil {
+				respond(s, i, "Please enter the dates as YYYY-MM-DD.", true)
+				return
+			}
+			req.To = t.Add(24 * time.Hour)
+		case "link":
+			link = o.BoolValue()
+		}
+	}
+
+	if !req.To.After(req.From) {
+		respond(s, i, "The start date has to be before the end date.", true)
+		return
+	}
+
+	user, err := lookupUser(broadcaster)
+	if err != nil {
+		if err == errUnknownUser {
+			respond(s, i, "The specified broadcaster does not exist.", true)
+			return
+		}
+		log.WithError(err).Error("mongo")
+		respond(s, i, "Internal server error. Please try again later.", true)
+		return
+	}
+
+	req.BroadcasterID = user.ID
+
+	ok, err := exportScope(g.ID, req)
+	if err != nil {
+		log.WithError(err).Error("mongo")
+		respond(s, i, "Internal server error. Please try again later.", true)
+		return
+	}
+	if !ok {
+		respond(s, i, "No moderation logs of that broadcaster were posted in this discord.", true)
+		return
+	}
+
+	if err := deferResponse(s, i, true); err != nil {
+		log.WithError(err).Error("discord")
+		return
+	}
+
+	buf := &bytes.Buffer{}
+	if !link {
+		events, err := export.Find(context.Background(), req)
+		if err == nil {
+			err = export.Write(buf, req.Format, events)
+		}
+		if err != nil {
+			log.WithError(err).Error("export")
+			if _, err := s.FollowupMessageCreate(s.State.User.ID, i.Interaction, true, &discordgo.WebhookParams{
+				Content: tr(guildLanguage(i.GuildID), "Internal server error. Please try again later."),
+			}); err != nil {
+				log.WithError(err).Error("discord")
+			}
+			return
+		}
+		link = buf.Len() > maxAttachmentSize
+	}
+
+	if !link {
+		err := followupWithFiles(s, i, &discordgo.WebhookParams{
+			Content: tr(guildLanguage(i.GuildID), "Moderation logs for <https://twitch.tv/%s>.", user.Login),
+		}, &discordgo.File{
+			Name:        export.FileName(user.Login, req),
+			ContentType: export.ContentType(req.Format),
+			Reader:      buf,
+		})
+		if err != nil {
+			log.WithError(err).Error("discord")
+		}
+		return
+	}
+
+	token, _ := uuid.NewRandom()
+	data, _ := json.Marshal(req)
+
+	lang := guildLanguage(i.GuildID)
+	msg := tr(lang, "Your export for <https://twitch.tv/%s> is ready at <%s/export/%s>, the link will expire in an hour.", user.Login, configure.Config.GetString("website_url"), token.String())
+	if err := redis.Client.Set(context.Background(), fmt.Sprintf("temp:exports:%s", token.String()), data, time.Hour).Err(); err != nil {
+		log.WithError(err).Error("redis")
+		msg = tr(lang, "Internal server error. Please try again later.")
+	}
+
+	if _, err := s.FollowupMessageCreate(s.State.User.ID, i.Interaction, true, &discordgo.WebhookParams{
+		Content: msg,
+	}); err != nil {
+		log.WithError(err).Error("discord")
+	}
+}
+
+// exportScope limits the request to the events logged to the guild once the broadcaster isn't hooked in it anymore.
+// It is false when the guild never got any of the events of the broadcaster.
+func exportScope(guildID string, req *export.Request) (bool, error) {
+	count, err := mongo.Database.Collection("hooks").CountDocuments(context.Background(), bson.M{
+		"guild_id":    guildID,
+		"streamer_id": req.BroadcasterID,
+	})
+	if err != nil || count != 0 {
+		return count != 0, err
+	}
+
+	req.GuildID = guildID
+	count, err = mongo.Database.Collection("events").CountDocuments(context.Background(), bson.M{
+		"broadcaster_id": req.BroadcasterID,
+		"guild_ids":      guildID,
+	})
+	return count != 0, err
+}
@@ -0,0 +1,331 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	log "github.com/sirupsen/logrus"
+	"github.com/troydota/modlogs/src/api"
+	"github.com/troydota/modlogs/src/auth"
+	"github.com/troydota/modlogs/src/configure"
+	"github.com/troydota/modlogs/src/mongo"
+	"github.com/troydota/modlogs/src/redis"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var errWebhookCreate = fmt.Errorf("failed to create channel webhook")
+
+var errModerateScopes = fmt.Errorf("moderator is missing the moderate scopes")
+
+type tooManyHooksError struct {
+	count int64
+	max   int64
+}
+
+func (e *tooManyHooksError) Error() string {
+	return fmt.Sprintf("There are too many hooks in this discord. (%v/%v)", e.count, e.max)
+}
+
+// hookModerator returns the moderator the events of the streamer are subscribed as, empty when the streamer authorized
+// the bot. Twitch only sends the ban, unban and moderator events to a streamer who authorized them, the actions are read
+// from channel.moderate for channels authorized by a moderator instead.
+func hookModerator(streamerID string, authorizedBy string) (string, error) {
+	if authorizedBy == "" || authorizedBy == streamerID {
+		return "", nil
+	}
+
+	ctx := context.Background()
+
+	subscribed, err := webhookSubscribed("channel.ban", streamerID)
+	if err != nil || subscribed {
+		return "", err
+	}
+
+	ok, err := auth.HasUserScopes(ctx, streamerID, "channel:moderate", "moderation:read")
+	if err != nil && err != auth.ErrNoUserToken {
+		return "", err
+	}
+	if ok {
+		return "", nil
+	}
+
+	ok, err = auth.HasUserScopes(ctx, authorizedBy, api.ModerateScopes...)
+	if err != nil && err != auth.ErrNoUserToken {
+		return "", err
+	}
+	if !ok {
+		return "", errModerateScopes
+	}
+
+	return authorizedBy, nil
+}
+
+// hookAuthorizers returns the twitch users who authorized the hooks of the streamer in the guild, only their tokens
+// may act for the guild. Hooks made before ownership was tracked were authorized by the streamer.
+func hookAuthorizers(ctx context.Context, guildID string, streamerID string) ([]string, error) {
+	hooks := []*mongo.Hook{}
+	cur, err := mongo.Database.Collection("hooks").Find(ctx, bson.M{
+		"guild_id":    guildID,
+		"streamer_id": streamerID,
+	})
+	if err == nil {
+		err = cur.All(ctx, &hooks)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	authorizers := []string{}
+	seen := map[string]bool{}
+	for _, hook := range hooks {
+		id := hook.AuthorizedBy
+		if id == "" {
+			id = streamerID
+		}
+		if !seen[id] {
+			seen[id] = true
+			authorizers = append(authorizers, id)
+		}
+	}
+
+	return authorizers, nil
+}
+
+// createHook adds or updates the hook of the user, subscribing to the twitch events when it is the first hook of the user.
+// It returns true when an existing hook was updated.
+func createHook(s *discordgo.Session, hook *mongo.Hook, user *mongo.User) (bool, error) {
+	filter := bson.M{
+		"channel_id":  hook.ChannelID,
+		"guild_id":    hook.GuildID,
+		"streamer_id": user.ID,
+	}
+
+	now := time.Now()
+
+	update := bson.M{
+		"$set": bson.M{
+			"channel_id":    hook.ChannelID,
+			"guild_id":      hook.GuildID,
+			"streamer_id":   user.ID,
+			"mode":          hook.Mode,
+			"delivery":      hook.Delivery,
+			"guild_name":    hook.GuildName,
+			"channel_name":  hook.ChannelName,
+			"authorized_by": hook.AuthorizedBy,
+			"added_by":      hook.AddedBy,
+			"authorized_at": now,
+		},
+		"$setOnInsert": bson.M{
+			"created_at": now,
+		},
+	}
+
+	exists, err := mongo.Database.Collection("hooks").CountDocuments(context.Background(), filter)
+	if err != nil {
+		return false, err
+	}
+
+	// The limit and the subscription are checked before anything is created in the channel.
+	var moderatorID string
+	if exists == 0 {
+		moderatorID, err = hookModerator(user.ID, hook.AuthorizedBy)
+		if err != nil {
+			return false, err
+		}
+
+		count, err := mongo.Database.Collection("hooks").CountDocuments(context.Background(), bson.M{
+			"guild_id": hook.GuildID,
+		})
+		if err != nil {
+			return false, err
+		}
+
+		max := configure.Config.GetInt64("max_hooks_per_guild")
+		if max != -1 && count >= max {
+			return false, &tooManyHooksError{count: count, max: max}
+		}
+	}
+
+	var webhookID, webhookToken string
+	if hook.Delivery == mongo.DeliveryWebhook {
+		webhookID, webhookToken, err = channelWebhook(s, hook.ChannelID)
+		if err != nil {
+			log.WithError(err).Error("discord")
+			return false, errWebhookCreate
+		}
+
+		var avatar string
+		if users, err := resolveUsers([]string{user.ID}, nil); err != nil {
+			log.WithError(err).Error("api")
+		} else if u, ok := users[user.ID]; ok {
+			avatar = u.ProfileImageURL
+		}
+
+		update["$set"].(bson.M)["webhook_id"] = webhookID
+		update["$set"].(bson.M)["webhook_token"] = webhookToken
+		update["$set"].(bson.M)["webhook_name"] = webhookDisplayName(user.Name)
+		update["$set"].(bson.M)["webhook_avatar"] = avatar
+	} else {
+		releaseWebhooks(s, filter)
+		update["$unset"] = bson.M{
+			"webhook_id":     "",
+			"webhook_token":  "",
+			"webhook_name":   "",
+			"webhook_avatar": "",
+		}
+	}
+
+	result, err := mongo.Database.Collection("hooks").UpdateOne(context.Background(), filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		discardWebhook(s, webhookID, webhookToken)
+		return false, err
+	}
+
+	if result.UpsertedCount == 0 {
+		go subscribeUnbanRequests(user.ID, hook.AuthorizedBy)
+		return true, nil
+	}
+
+	val, err := redis.Client.Incr(context.Background(), fmt.Sprintf("streamers:%s", user.ID)).Result()
+	if err != nil {
+		removeHook(s, result.UpsertedID, webhookID, webhookToken)
+		return false, err
+	}
+	if val == 1 {
+		if moderatorID == "" {
+			err = api.CreateWebhooks(context.Background(), user.ID)
+		} else {
+			err = api.CreateModerateWebhooks(context.Background(), user.ID, moderatorID)
+		}
+		if err != nil {
+			if err := redis.Client.Decr(context.Background(), fmt.Sprintf("streamers:%s", user.ID)).Err(); err != nil {
+				log.WithError(err).Error("redis")
+			}
+			removeHook(s, result.UpsertedID, webhookID, webhookToken)
+			return false, err
+		}
+	}
+
+	go subscribeUnbanRequests(user.ID, hook.AuthorizedBy)
+
+	return false, nil
+}
+
+// removeHook deletes a hook which failed to be set up, with its discord webhook.
+func removeHook(s *discordgo.Session, id interface{}, webhookID string, webhookToken string) {
+	if _, err := mongo.Database.Collection("hooks").DeleteOne(context.Background(), bson.M{
+		"_id": id,
+	}); err != nil {
+		log.WithError(err).Error("mongo")
+	}
+	discardWebhook(s, webhookID, webhookToken)
+}
+
+// discardWebhook deletes a discord webhook created for a hook which wasn't stored, unless another hook uses it.
+func discardWebhook(s *discordgo.Session, webhookID string, webhookToken string) {
+	if webhookID == "" {
+		return
+	}
+	count, err := mongo.Database.Collection("hooks").CountDocuments(context.Background(), bson.M{
+		"webhook_id": webhookID,
+	})
+	if err != nil {
+		log.WithError(err).Error("mongo")
+		return
+	}
+	if count > 0 {
+		return
+	}
+	if _, err := s.WebhookDeleteWithToken(webhookID, webhookToken); err != nil && !isUnknownWebhook(err) {
+		log.WithError(err).Error("discord")
+	}
+}
+
+// hookErrorMessage turns an error of createHook into a message for discord.
+func hookErrorMessage(err error, lang string) string {
+	if e, ok := err.(*tooManyHooksError); ok {
+		return tr(lang, "There are too many hooks in this discord. (%v/%v)", e.count, e.max)
+	}
+	if err == errModerateScopes {
+		return tr(lang, "The broadcaster has not authorized ModLogs and the moderator who authorized the channel did not grant every permission needed to read its moderation actions, they have to login again.")
+	}
+	if err == errWebhookCreate {
+		return tr(lang, "Failed to create a webhook in that channel, make sure the bot has the Manage Webhooks permission.")
+	}
+	log.WithError(err).Error("hooks")
+	return tr(lang, "Internal server error. Please try again later.")
+}
+
+type RevokeRequest struct {
+	StreamerID string
+	HookID     primitive.ObjectID
+	Result     chan error
+}
+
+// Revokes receives the hooks that streamers removed from the website.
+var Revokes = make(chan RevokeRequest)
+
+func (b *Bot) processRevoke(req RevokeRequest) {
+	filter := bson.M{
+		"_id":         req.HookID,
+		"streamer_id": req.StreamerID,
+	}
+
+	hook := &mongo.Hook{}
+	if err := mongo.Database.Collection("hooks").FindOne(context.Background(), filter).Decode(hook); err != nil {
+		req.Result <- err
+		return
+	}
+
+	releaseWebhooks(b.conn, filter)
+
+	if _, err := mongo.Database.Collection("hooks").DeleteOne(context.Background(), filter); err != nil {
+		req.Result <- err
+		return
+	}
+
+	req.Result <- nil
+
+	if count, err := mongo.Database.Collection("hooks").CountDocuments(context.Background(), bson.M{
+		"guild_id":    hook.GuildID,
+		"streamer_id": hook.StreamerID,
+	}); err != nil {
+		log.WithError(err).Error("mongo")
+	} else if count == 0 {
+		if _, err := mongo.Database.Collection("sinks").DeleteMany(context.Background(), bson.M{
+			"guild_id":    hook.GuildID,
+			"streamer_id": hook.StreamerID,
+		}); err != nil {
+			log.WithError(err).Error("mongo")
+		}
+	}
+
+	val, err := redis.Client.Decr(context.Background(), fmt.Sprintf("streamers:%s", hook.StreamerID)).Result()
+	if err != nil {
+		log.WithError(err).Error("redis")
+	} else if val == 0 {
+		if err := api.RevokeWebhook(context.Background(), hook.StreamerID); err != nil {
+			log.WithError(err).Error("api")
+		}
+	}
+
+	user := &mongo.User{Login: hook.StreamerID}
+	users, err := resolveUsers([]string{hook.StreamerID}, nil)
+	if err != nil {
+		log.WithError(err).Error("api")
+	}
+	if u, ok := users[hook.StreamerID]; ok {
+		user = u
+	}
+
+	if _, err := b.conn.ChannelMessageSendComplex(hook.ChannelID, &discordgo.MessageSend{
+		Content:         tr(guildLanguage(hook.GuildID), "<https://twitch.tv/%s> revoked the ModLogs hook for this channel.", user.Login),
+		AllowedMentions: noMentions(),
+	}); err != nil {
+		log.WithError(err).WithField("hook", hook).Error("discord")
+	}
+}
@@ -0,0 +1,251 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	log "github.com/sirupsen/logrus"
+	"github.com/troydota/modlogs/src/mongo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const defaultLanguage = "en-US"
+
+// languages are the discord locales the bot is translated to, with the name shown to pick them.
+var languages = []struct {
+	Locale string
+	Name   string
+}{
+	{"en-US", "English"},
+	{"de", "Deutsch"},
+	{"fr", "Français"},
+}
+
+var languageCommand = &discordgo.ApplicationCommand{
+	Name:        "language",
+	Description: "Set the language of the bot replies and the logs in this discord.",
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "language",
+			Description: "The language to use.",
+			Required:    true,
+			Choices:     languageChoices(),
+		},
+	},
+}
+
+func languageChoices() []*discordgo.ApplicationCommandOptionChoice {
+	choices := []*discordgo.ApplicationCommandOptionChoice{}
+	for _, l := range languages {
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: l.Name, Value: l.Locale})
+	}
+	return choices
+}
+
+// guildLanguages caches the language of the guilds, it only changes through the language command.
+var guildLanguages = sync.Map{}
+
+// guildLanguage returns the locale picked by the guild, english when it never picked one.
+func guildLanguage(guildID string) string {
+	if guildID == "" {
+		return defaultLanguage
+	}
+	if v, ok := guildLanguages.Load(guildID); ok {
+		return v.(string)
+	}
+
+	lang := &mongo.GuildLanguage{}
+	err := mongo.Database.Collection("languages").FindOne(context.Background(), bson.M{"guild_id": guildID}).Decode(lang)
+	if err != nil {
+		if err != mongo.ErrNoDocuments {
+			log.WithError(err).Error("mongo")
+			return defaultLanguage
+		}
+		lang.Language = defaultLanguage
+	}
+
+	guildLanguages.Store(guildID, lang.Language)
+	return lang.Language
+}
+
+// tr translates the english text or format to the language, the text is used as is when it has no translation.
+func tr(lang string, format string, args ...interface{}) string {
+	if translated, ok := catalog[lang][format]; ok {
+		format = translated
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// respondf replies to the interaction with a message translated to the language of the guild.
+func respondf(s *discordgo.Session, i *discordgo.InteractionCreate, ephemeral bool, format string, args ...interface{}) {
+	respond(s, i, tr(guildLanguage(i.GuildID), format, args...), ephemeral)
+}
+
+// discordTime shows the time in the timezone and the language of each reader.
+func discordTime(t time.Time) string {
+	return fmt.Sprintf("<t:%d:F>", t.Unix())
+}
+
+// localizeEmbed returns a copy of the embed with its title and field names translated.
+func localizeEmbed(embed *discordgo.MessageEmbed, lang string) *discordgo.MessageEmbed {
+	if _, ok := catalog[lang]; !ok {
+		return embed
+	}
+
+	localized := *embed
+	localized.Title = tr(lang, embed.Title)
+	localized.Fields = []*discordgo.MessageEmbedField{}
+	for _, f := range embed.Fields {
+		field := *f
+		field.Name = tr(lang, f.Name)
+		if f.Value == "None Provided" || f.Value == "Unknown" || f.Value == "None" {
+			field.Value = tr(lang, f.Value)
+		}
+		localized.Fields = append(localized.Fields, &field)
+	}
+	return &localized
+}
+
+// localizeComponents returns a copy of the components with their labels translated.
+func localizeComponents(components []*component, lang string) []*component {
+	if _, ok := catalog[lang]; !ok {
+		return components
+	}
+
+	localized := []*component{}
+	for _, c := range components {
+		copied := *c
+		copied.Label = tr(lang, c.Label)
+		if len(c.Components) != 0 {
+			copied.Components = localizeComponents(c.Components, lang)
+		}
+		localized = append(localized, &copied)
+	}
+	return localized
+}
+
+// channelLanguage returns the language of the guild of the channel, for messages which aren't tied to a hook.
+func channelLanguage(s *discordgo.Session, channelID string) string {
+	c, err := s.State.Channel(channelID)
+	if err != nil {
+		return defaultLanguage
+	}
+	return guildLanguage(c.GuildID)
+}
+
+// commandLocalizations returns the translations of a name or a description, keyed by discord locale.
+func commandLocalizations(text string, names bool) map[string]string {
+	localizations := map[string]string{}
+	for _, l := range languages {
+		var translated string
+		var ok bool
+		if names {
+			translated, ok = commandNames[l.Locale][text]
+		} else {
+			translated, ok = catalog[l.Locale][text]
+		}
+		if ok {
+			localizations[l.Locale] = translated
+		}
+	}
+	return localizations
+}
+
+// localizeCommandData adds the localizations discord shows in the command picker to the json of a command or option.
+func localizeCommandData(data map[string]interface{}) {
+	if name, ok := data["name"].(string); ok {
+		if l := commandLocalizations(name, true); len(l) != 0 {
+			data["name_localizations"] = l
+		}
+	}
+	if description, ok := data["description"].(string); ok {
+		if l := commandLocalizations(description, false); len(l) != 0 {
+			data["description_localizations"] = l
+		}
+	}
+
+	if options, ok := data["options"].([]interface{}); ok {
+		for _, o := range options {
+			if option, ok := o.(map[string]interface{}); ok {
+				localizeCommandData(option)
+			}
+		}
+	}
+	if choices, ok := data["choices"].([]interface{}); ok {
+		for _, c := range choices {
+			if choice, ok := c.(map[string]interface{}); ok {
+				if name, ok := choice["name"].(string); ok {
+					if l := commandLocalizations(name, false); len(l) != 0 {
+						choice["name_localizations"] = l
+					}
+				}
+			}
+		}
+	}
+}
+
+// createCommand registers the command with its localizations, the version of discordgo we use doesn't know about them.
+func createCommand(s *discordgo.Session, appID string, cmd *discordgo.ApplicationCommand) (*discordgo.ApplicationCommand, error) {
+	raw, err := json.Marshal(cmd)
+	if err != nil {
+		return nil, err
+	}
+	data := map[string]interface{}{}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, err
+	}
+	localizeCommandData(data)
+
+	endpoint := discordgo.EndpointApplicationGlobalCommands(appID)
+	body, err := s.RequestWithBucketID("POST", endpoint, data, endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	created := &discordgo.ApplicationCommand{}
+	err = json.Unmarshal(body, created)
+	return created, err
+}
+
+func languageHandler(s *discordgo.Session, i *discordgo.InteractionCreate, g *discordgo.Guild) {
+	var language string
+	for _, o := range i.Data.Options {
+		switch o.Name {
+		case "language":
+			language = o.StringValue()
+		}
+	}
+
+	name := ""
+	for _, l := range languages {
+		if l.Locale == language {
+			name = l.Name
+		}
+	}
+	if name == "" {
+		respond(s, i, "Please select a valid language.", true)
+		return
+	}
+
+	if _, err := mongo.Database.Collection("languages").UpdateOne(context.Background(), bson.M{"guild_id": g.ID}, bson.M{
+		"$set": bson.M{
+			"guild_id": g.ID,
+			"language": language,
+		},
+	}, options.Update().SetUpsert(true)); err != nil {
+		log.WithError(err).Error("mongo")
+		respond(s, i, "Internal server error. Please try again later.", true)
+		return
+	}
+	guildLanguages.Store(g.ID, language)
+
+	respondf(s, i, false, "The bot now replies and logs in %s.", name)
+}
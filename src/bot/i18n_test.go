@@ -0,0 +1,95 @@
+package bot
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestTr(t *testing.T) {
+	tests := []struct {
+		name   string
+		lang   string
+		format string
+		args   []interface{}
+		want   string
+	}{
+		{"english", defaultLanguage, "Invalid case.", nil, "Invalid case."},
+		{"translated", "de", "Invalid case.", nil, catalog["de"]["Invalid case."]},
+		{"translated format", "fr", "rule `%s`", []interface{}{"abc"}, "règle `abc`"},
+		{"missing translation", "de", "Not in the catalog %s.", []interface{}{"x"}, "Not in the catalog x."},
+		{"unknown language", "xx", "rule `%s`", []interface{}{"abc"}, "rule `abc`"},
+		{"percent without args", "de", "100%", nil, "100%"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tr(tt.lang, tt.format, tt.args...); got != tt.want {
+				t.Errorf("tr = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+var verbs = regexp.MustCompile(`%[-+# 0-9.]*[a-zA-Z%]`)
+
+// The translations are used with the arguments of the english text, so they need the same verbs in the same order.
+func TestCatalogVerbs(t *testing.T) {
+	for lang, entries := range catalog {
+		for english, translated := range entries {
+			want := strings.Join(verbs.FindAllString(english, -1), " ")
+			if got := strings.Join(verbs.FindAllString(translated, -1), " "); got != want {
+				t.Errorf("%s: %q has the verbs %q, want %q", lang, translated, got, want)
+			}
+		}
+	}
+}
+
+func TestCatalogLanguages(t *testing.T) {
+	for _, l := range languages {
+		if l.Locale == defaultLanguage {
+			continue
+		}
+		if _, ok := catalog[l.Locale]; !ok {
+			t.Errorf("%s has no catalog", l.Locale)
+		}
+	}
+
+	for english := range catalog["de"] {
+		if _, ok := catalog["fr"][english]; !ok {
+			t.Errorf("fr misses %q", english)
+		}
+	}
+	for english := range catalog["fr"] {
+		if _, ok := catalog["de"][english]; !ok {
+			t.Errorf("de misses %q", english)
+		}
+	}
+}
+
+func TestLocalizeEmbed(t *testing.T) {
+	embed := &discordgo.MessageEmbed{
+		Title: "User Ban Event",
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Reason", Value: "None Provided"},
+			{Name: "User", Value: "None"},
+		},
+	}
+
+	if got := localizeEmbed(embed, defaultLanguage); got != embed {
+		t.Error("the english embed was copied")
+	}
+
+	got := localizeEmbed(embed, "de")
+	if got.Title == embed.Title {
+		t.Errorf("the title %q wasn't translated", got.Title)
+	}
+	if got.Title != tr("de", "User Ban Event") || got.Fields[0].Name != tr("de", "Reason") || got.Fields[0].Value != tr("de", "None Provided") || got.Fields[1].Value != tr("de", "None") {
+		t.Errorf("localizeEmbed = %+v", got)
+	}
+	if embed.Title != "User Ban Event" || embed.Fields[0].Name != "Reason" || embed.Fields[1].Value != "None" {
+		t.Error("localizeEmbed changed the original embed")
+	}
+}
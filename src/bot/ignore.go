@@ -0,0 +1,516 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	log "github.com/sirupsen/logrus"
+	"github.com/troydota/modlogs/src/mongo"
+	"github.com/troydota/modlogs/src/redis"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var ignoreActions = []string{kindBan, kindTimeout, kindUnban, kindMod, kindUnmod}
+
+var ignoreCommand = &discordgo.ApplicationCommand{
+	Name:        "ignore",
+	Description: "Ignore a user, such as a bot, or the actions matching a reason.",
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "user",
+			Description: "The id or username of the twitch account.",
+			Required:    false,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "match",
+			Description: "Whether the user is matched as the moderator, the target or both, defaults to the moderator.",
+			Required:    false,
+			Choices: []*discordgo.ApplicationCommandOptionChoice{
+				{Name: "moderator", Value: mongo.MatchExecutor},
+				{Name: "target", Value: mongo.MatchTarget},
+				{Name: "both", Value: mongo.MatchAny},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "actions",
+			Description: "Comma separated actions to ignore, such as timeout,unban. Defaults to every action.",
+			Required:    false,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "reason",
+			Description: "Only ignore actions whose reason matches this regular expression.",
+			Required:    false,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "broadcaster",
+			Description: "Only ignore the actions in the channel of this twitch streamer.",
+			Required:    false,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionChannel,
+			Name:        "channel",
+			Description: "Only ignore the actions posted into this text channel.",
+			Required:    false,
+		},
+	},
+}
+
+var unignoreCommand = &discordgo.ApplicationCommand{
+	Name:        "unignore",
+	Description: "Unignore a user that was previously ignored",
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "user",
+			Description: "The id or username of the twitch account, removes every rule of that user.",
+			Required:    false,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "rule",
+			Description: "The id of the rule as shown by /ignored.",
+			Required:    false,
+		},
+	},
+}
+
+var ignoredCommand = &discordgo.ApplicationCommand{
+	Name:        "ignored",
+	Description: "Shows a list of the ignore rules.",
+}
+
+// ignoreRule is an ignore rule with its reason pattern compiled.
+type ignoreRule struct {
+	*mongo.IgnoreRule
+	reason *regexp.Regexp
+}
+
+func compileIgnoreRule(rule *mongo.IgnoreRule) (*ignoreRule, error) {
+	r := &ignoreRule{IgnoreRule: rule}
+	if rule.Reason != "" {
+		re, err := regexp.Compile(fmt.Sprintf("(?i)%s", rule.Reason))
+		if err != nil {
+			return nil, err
+		}
+		r.reason = re
+	}
+	return r, nil
+}
+
+// guildIgnoreRules caches the compiled ignore rules of the guilds, it is cleared when the rules of a guild change.
+var guildIgnoreRules = sync.Map{}
+
+// ignoreRules returns the compiled ignore rules of the guild.
+func ignoreRules(guildID string) ([]*ignoreRule, error) {
+	if v, ok := guildIgnoreRules.Load(guildID); ok {
+		return v.([]*ignoreRule), nil
+	}
+
+	list := []*mongo.IgnoreRule{}
+	cur, err := mongo.Database.Collection("ignores").Find(context.Background(), bson.M{"guild_id": guildID})
+	if err == nil {
+		err = cur.All(context.Background(), &list)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rules := []*ignoreRule{}
+	for _, v := range list {
+		r, err := compileIgnoreRule(v)
+		if err != nil {
+			log.WithError(err).WithField("rule", v.ID.Hex()).Error("ignore")
+			continue
+		}
+		rules = append(rules, r)
+	}
+
+	guildIgnoreRules.Store(guildID, rules)
+	return rules, nil
+}
+
+// ignoreMatches reports if the rule hides the event from the hook posting into the channel.
+// Sinks have no channel, rules scoped to a channel never apply to them.
+func ignoreMatches(rule *ignoreRule, channelID string, e *mongo.Event) bool {
+	if rule.StreamerID != "" && rule.StreamerID != e.BroadcasterID {
+		return false
+	}
+	if rule.ChannelID != "" && rule.ChannelID != channelID {
+		return false
+	}
+
+	if len(rule.Actions) != 0 {
+		kind := eventKind(e)
+		found := false
+		for _, a := range rule.Actions {
+			if a == kind {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if rule.UserID != "" {
+		executor := eventExecutor(e) == rule.UserID
+		target := e.UserID == rule.UserID
+		switch rule.Match {
+		case mongo.MatchTarget:
+			if !target {
+				return false
+			}
+		case mongo.MatchAny:
+			if !executor && !target {
+				return false
+			}
+		default:
+			if !executor {
+				return false
+			}
+		}
+	}
+
+	if rule.reason != nil && !rule.reason.MatchString(e.Reason) {
+		return false
+	}
+
+	return true
+}
+
+// isIgnored reports if any ignore rule of the guild hides the event.
+func isIgnored(guildID string, channelID string, e *mongo.Event) bool {
+	rules, err := ignoreRules(guildID)
+	if err != nil {
+		log.WithError(err).Error("mongo")
+		return false
+	}
+
+	for _, r := range rules {
+		if ignoreMatches(r, channelID, e) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// migrateIgnoredUsers turns the old ignored-users:<guild> redis sets into ignore rules.
+func migrateIgnoredUsers() {
+	iter := redis.Client.Scan(context.Background(), 0, "ignored-users:*", 100).Iterator()
+	for iter.Next(context.Background()) {
+		key := iter.Val()
+		guildID := strings.TrimPrefix(key, "ignored-users:")
+
+		ids, err := redis.Client.SMembers(context.Background(), key).Result()
+		if err != nil {
+			log.WithError(err).Error("redis")
+			continue
+		}
+
+		failed := false
+		for _, id := range ids {
+			rule := &mongo.IgnoreRule{
+				GuildID:   guildID,
+				UserID:    id,
+				Match:     mongo.MatchExecutor,
+				CreatedAt: time.Now(),
+			}
+			if _, err := mongo.Database.Collection("ignores").UpdateOne(context.Background(), bson.M{
+				"guild_id":    guildID,
+				"user_id":     id,
+				"match":       mongo.MatchExecutor,
+				"streamer_id": bson.M{"$exists": false},
+				"channel_id":  bson.M{"$exists": false},
+				"actions":     bson.M{"$exists": false},
+				"reason":      bson.M{"$exists": false},
+			}, bson.M{"$setOnInsert": rule}, options.Update().SetUpsert(true)); err != nil {
+				log.WithError(err).Error("mongo")
+				failed = true
+			}
+		}
+
+		guildIgnoreRules.Delete(guildID)
+
+		if !failed {
+			if err := redis.Client.Del(context.Background(), key).Err(); err != nil {
+				log.WithError(err).Error("redis")
+			}
+		}
+	}
+	if err := iter.Err(); err != nil {
+		log.WithError(err).Error("redis")
+	}
+}
+
+// describeRule prints the rule as a single line for discord.
+func describeRule(rule *mongo.IgnoreRule, names map[string]string, lang string) string {
+	parts := []string{}
+	if rule.UserID != "" {
+		name := names[rule.UserID]
+		if name == "" {
+			name = rule.UserID
+		}
+		switch rule.Match {
+		case mongo.MatchTarget:
+			parts = append(parts, tr(lang, "actions on `%s`", name))
+		case mongo.MatchAny:
+			parts = append(parts, tr(lang, "actions by or on `%s`", name))
+		default:
+			parts = append(parts, tr(lang, "actions by `%s`", name))
+		}
+	} else {
+		parts = append(parts, tr(lang, "all actions"))
+	}
+	if len(rule.Actions) != 0 {
+		parts = append(parts, tr(lang, "of type %s", strings.Join(rule.Actions, ", ")))
+	}
+	if rule.Reason != "" {
+		parts = append(parts, tr(lang, "with a reason matching `%s`", strings.ReplaceAll(rule.Reason, "`", "")))
+	}
+	if rule.StreamerID != "" {
+		name := names[rule.StreamerID]
+		if name == "" {
+			name = rule.StreamerID
+		}
+		parts = append(parts, tr(lang, "in #%s", name))
+	}
+	if rule.ChannelID != "" {
+		parts = append(parts, tr(lang, "posted into <#%s>", rule.ChannelID))
+	}
+
+	return fmt.Sprintf("`%s` - %s", rule.ID.Hex(), strings.Join(parts, " "))
+}
+
+func ignoreHandler(s *discordgo.Session, i *discordgo.InteractionCreate, g *discordgo.Guild) {
+	var userInput string
+	var broadcaster string
+	var channel *discordgo.Channel
+	rule := &mongo.IgnoreRule{
+		GuildID:   g.ID,
+		Match:     mongo.MatchExecutor,
+		CreatedBy: i.Member.User.ID,
+		CreatedAt: time.Now(),
+	}
+
+	for _, o := range i.Data.Options {
+		switch o.Name {
+		case "user":
+			userInput = o.StringValue()
+		case "match":
+			rule.Match = o.StringValue()
+		case "actions":
+			for _, a := range strings.Split(strings.ToLower(o.StringValue()), ",") {
+				a = strings.TrimSpace(a)
+				if a == "" {
+					continue
+				}
+				valid := false
+				for _, v := range ignoreActions {
+					if v == a {
+						valid = true
+						break
+					}
+				}
+				if !valid {
+					respondf(s, i, true, "Unknown action `%s`, the actions are %s.", strings.ReplaceAll(a, "`", ""), strings.Join(ignoreActions, ", "))
+					return
+				}
+				rule.Actions = append(rule.Actions, a)
+			}
+		case "reason":
+			rule.Reason = o.StringValue()
+		case "broadcaster":
+			broadcaster = strings.ToLower(o.StringValue())
+		case "channel":
+			channel = o.ChannelValue(s)
+		}
+	}
+
+	if userInput == "" && rule.Reason == "" {
+		respond(s, i, "Please enter a valid user or a reason pattern.", true)
+		return
+	}
+
+	if rule.Reason != "" {
+		if _, err := compileIgnoreRule(rule); err != nil {
+			respond(s, i, "The reason is not a valid regular expression.", true)
+			return
+		}
+	}
+
+	names := map[string]string{}
+
+	if userInput != "" {
+		user, err := lookupUser(userInput)
+		if err != nil {
+			if err == errUnknownUser {
+				respond(s, i, "The specified user does not exist.", true)
+				return
+			}
+			log.WithError(err).Error("mongo")
+			respond(s, i, "Internal server error. Please try again later.", true)
+			return
+		}
+		rule.UserID = user.ID
+		names[user.ID] = user.Name
+	}
+
+	if broadcaster != "" {
+		user, err := hookedBroadcaster(g.ID, broadcaster)
+		if err != nil {
+			if err == errNotHooked {
+				respond(s, i, "That broadcaster is not hooked in this discord.", true)
+				return
+			}
+			log.WithError(err).Error("mongo")
+			respond(s, i, "Internal server error. Please try again later.", true)
+			return
+		}
+		rule.StreamerID = user.ID
+		names[user.ID] = user.Login
+	}
+
+	if channel != nil {
+		if channel.GuildID != g.ID {
+			respond(s, i, "Please select a channel in this discord.", true)
+			return
+		}
+		rule.ChannelID = channel.ID
+	}
+
+	res, err := mongo.Database.Collection("ignores").InsertOne(context.Background(), rule)
+	if err != nil {
+		log.WithError(err).Error("mongo")
+		respond(s, i, "Internal server error. Please try again later.", true)
+		return
+	}
+	rule.ID, _ = res.InsertedID.(primitive.ObjectID)
+	guildIgnoreRules.Delete(g.ID)
+
+	respondf(s, i, false, "Successfully ignored %s.", describeRule(rule, names, guildLanguage(g.ID)))
+}
+
+func unignoreHandler(s *discordgo.Session, i *discordgo.InteractionCreate, g *discordgo.Guild) {
+	var userInput string
+	var ruleID string
+
+	for _, o := range i.Data.Options {
+		switch o.Name {
+		case "user":
+			userInput = o.StringValue()
+		case "rule":
+			ruleID = strings.Trim(o.StringValue(), "` ")
+		}
+	}
+
+	filter := bson.M{"guild_id": g.ID}
+	var target string
+
+	if ruleID != "" {
+		id, err := primitive.ObjectIDFromHex(ruleID)
+		if err != nil {
+			respond(s, i, "Please enter a rule id as shown by /ignored.", true)
+			return
+		}
+		filter["_id"] = id
+		target = tr(guildLanguage(g.ID), "rule `%s`", ruleID)
+	} else if userInput != "" {
+		user, err := lookupUser(userInput)
+		if err != nil {
+			if err == errUnknownUser {
+				respond(s, i, "The specified user does not exist.", true)
+				return
+			}
+			log.WithError(err).Error("mongo")
+			respond(s, i, "Internal server error. Please try again later.", true)
+			return
+		}
+		filter["user_id"] = user.ID
+		target = fmt.Sprintf("`%s`", user.Name)
+	} else {
+		respond(s, i, "Please enter a valid user or rule.", true)
+		return
+	}
+
+	res, err := mongo.Database.Collection("ignores").DeleteMany(context.Background(), filter)
+	if err != nil {
+		log.WithError(err).Error("mongo")
+		respond(s, i, "Internal server error. Please try again later.", true)
+		return
+	}
+	guildIgnoreRules.Delete(g.ID)
+
+	if res.DeletedCount == 0 {
+		respondf(s, i, true, "There are no ignore rules for %s.", target)
+		return
+	}
+
+	respondf(s, i, true, "Successfully unignored %s.", target)
+}
+
+func ignoredHandler(s *discordgo.Session, i *discordgo.InteractionCreate, g *discordgo.Guild) {
+	rules := []*mongo.IgnoreRule{}
+	cur, err := mongo.Database.Collection("ignores").Find(context.Background(), bson.M{"guild_id": g.ID}, options.Find().SetSort(bson.M{"created_at": 1}))
+	if err == nil {
+		err = cur.All(context.Background(), &rules)
+	}
+	if err != nil {
+		log.WithError(err).Error("mongo")
+		respond(s, i, "Internal server error. Please try again later.", true)
+		return
+	}
+
+	if len(rules) == 0 {
+		respond(s, i, "There are no ignored users.", true)
+		return
+	}
+
+	ids := []string{}
+	for _, r := range rules {
+		if r.UserID != "" {
+			ids = append(ids, r.UserID)
+		}
+		if r.StreamerID != "" {
+			ids = append(ids, r.StreamerID)
+		}
+	}
+
+	users, err := resolveUsers(ids, nil)
+	if err != nil {
+		log.WithError(err).Error("api")
+	}
+
+	names := map[string]string{}
+	for _, u := range users {
+		names[u.ID] = u.Name
+	}
+
+	lang := guildLanguage(g.ID)
+	lines := []string{tr(lang, "Ignore rules:")}
+	length := len(lines[0])
+	for n, r := range rules {
+		line := describeRule(r, names, lang)
+		if length+len(line)+1 > 1900 {
+			lines = append(lines, tr(lang, "and %v more...", len(rules)-n))
+			break
+		}
+		length += len(line) + 1
+		lines = append(lines, line)
+	}
+
+	respond(s, i, strings.Join(lines, "\n"), false)
+}
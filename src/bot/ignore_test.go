@@ -0,0 +1,63 @@
+package bot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/troydota/modlogs/src/mongo"
+)
+
+func TestIgnoreMatches(t *testing.T) {
+	expires := time.Now().Add(10 * time.Minute)
+	ban := &mongo.Event{BroadcasterID: "10", ModeratorID: "20", UserID: "30", Action: "channel.ban", Reason: "Posted SPAM links"}
+	timeout := &mongo.Event{BroadcasterID: "10", ModeratorID: "20", UserID: "30", Action: "channel.ban", Expires: &expires}
+	byBroadcaster := &mongo.Event{BroadcasterID: "10", UserID: "30", Action: "channel.unban"}
+
+	tests := []struct {
+		name    string
+		rule    mongo.IgnoreRule
+		channel string
+		event   *mongo.Event
+		want    bool
+	}{
+		{"moderator", mongo.IgnoreRule{UserID: "20"}, "c1", ban, true},
+		{"moderator only matches the executor", mongo.IgnoreRule{UserID: "30"}, "c1", ban, false},
+		{"broadcaster is the executor without a moderator", mongo.IgnoreRule{UserID: "10", Match: mongo.MatchExecutor}, "c1", byBroadcaster, true},
+		{"target", mongo.IgnoreRule{UserID: "30", Match: mongo.MatchTarget}, "c1", ban, true},
+		{"target only matches the target", mongo.IgnoreRule{UserID: "20", Match: mongo.MatchTarget}, "c1", ban, false},
+		{"any as executor", mongo.IgnoreRule{UserID: "20", Match: mongo.MatchAny}, "c1", ban, true},
+		{"any as target", mongo.IgnoreRule{UserID: "30", Match: mongo.MatchAny}, "c1", ban, true},
+		{"any as neither", mongo.IgnoreRule{UserID: "40", Match: mongo.MatchAny}, "c1", ban, false},
+		{"same streamer", mongo.IgnoreRule{UserID: "20", StreamerID: "10"}, "c1", ban, true},
+		{"other streamer", mongo.IgnoreRule{UserID: "20", StreamerID: "11"}, "c1", ban, false},
+		{"same channel", mongo.IgnoreRule{UserID: "20", ChannelID: "c1"}, "c1", ban, true},
+		{"other channel", mongo.IgnoreRule{UserID: "20", ChannelID: "c2"}, "c1", ban, false},
+		{"channel rules skip sinks", mongo.IgnoreRule{UserID: "20", ChannelID: "c1"}, "", ban, false},
+		{"matching action", mongo.IgnoreRule{UserID: "20", Actions: []string{kindTimeout}}, "c1", timeout, true},
+		{"other action", mongo.IgnoreRule{UserID: "20", Actions: []string{kindTimeout}}, "c1", ban, false},
+		{"reason ignores case", mongo.IgnoreRule{Reason: "spam"}, "c1", ban, true},
+		{"reason pattern", mongo.IgnoreRule{Reason: "^posted .* links$"}, "c1", ban, true},
+		{"other reason", mongo.IgnoreRule{Reason: "^spam"}, "c1", ban, false},
+		{"reason without a reason", mongo.IgnoreRule{Reason: "spam"}, "c1", timeout, false},
+		{"every field", mongo.IgnoreRule{UserID: "30", Match: mongo.MatchTarget, StreamerID: "10", ChannelID: "c1", Actions: []string{kindBan}, Reason: "links"}, "c1", ban, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := tt.rule
+			r, err := compileIgnoreRule(&rule)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := ignoreMatches(r, tt.channel, tt.event); got != tt.want {
+				t.Errorf("ignoreMatches = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompileIgnoreRule(t *testing.T) {
+	if _, err := compileIgnoreRule(&mongo.IgnoreRule{Reason: "(unclosed"}); err == nil {
+		t.Error("compileIgnoreRule accepted an invalid pattern")
+	}
+}
@@ -0,0 +1,121 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+	"github.com/troydota/modlogs/src/mongo"
+	"github.com/troydota/modlogs/src/redis"
+)
+
+var ErrLinkMismatch = fmt.Errorf("the link was made for another broadcaster")
+
+// Link is a pending hook, created by /add broadcaster, waiting for the streamer to login.
+type Link struct {
+	GuildID     string `json:"guild_id"`
+	ChannelID   string `json:"channel_id"`
+	Mode        int32  `json:"mode"`
+	Delivery    int32  `json:"delivery"`
+	Broadcaster string `json:"broadcaster"`
+	RequestedBy string `json:"requested_by"`
+}
+
+type LinkRequest struct {
+	Link *Link
+	// User is the broadcaster whose logs are hooked.
+	User *mongo.User
+	// Moderator is set when a moderator of the broadcaster authorized the link.
+	Moderator *mongo.User
+	Result    chan error
+}
+
+var Links = make(chan LinkRequest)
+
+func createLink(link *Link) (string, error) {
+	id, _ := uuid.NewRandom()
+
+	data, err := json.Marshal(link)
+	if err != nil {
+		return "", err
+	}
+
+	if err := redis.Client.Set(context.Background(), fmt.Sprintf("temp:links:%s", id.String()), data, 24*time.Hour).Err(); err != nil {
+		return "", err
+	}
+
+	return id.String(), nil
+}
+
+// GetLink returns the pending link, it returns redis.ErrNil when the link does not exist.
+func GetLink(ctx context.Context, id string) (*Link, error) {
+	data, err := redis.Client.Get(ctx, fmt.Sprintf("temp:links:%s", id)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	link := &Link{}
+	if err := json.Unmarshal([]byte(data), link); err != nil {
+		return nil, err
+	}
+
+	return link, nil
+}
+
+// DeleteLink removes the pending link once it has been used.
+func DeleteLink(ctx context.Context, id string) error {
+	return redis.Client.Del(ctx, fmt.Sprintf("temp:links:%s", id)).Err()
+}
+
+func (b *Bot) processLink(req LinkRequest) {
+	hook := &mongo.Hook{
+		GuildID:      req.Link.GuildID,
+		ChannelID:    req.Link.ChannelID,
+		StreamerID:   req.User.ID,
+		Mode:         req.Link.Mode,
+		Delivery:     req.Link.Delivery,
+		AuthorizedBy: req.User.ID,
+		AddedBy:      req.Link.RequestedBy,
+	}
+	lang := guildLanguage(hook.GuildID)
+	authorizer := tr(lang, "the streamer")
+	if req.Moderator != nil {
+		hook.AuthorizedBy = req.Moderator.ID
+		authorizer = tr(lang, "their moderator <https://twitch.tv/%s>", req.Moderator.Login)
+	}
+	if g, err := b.conn.State.Guild(hook.GuildID); err == nil {
+		hook.GuildName = g.Name
+	}
+	if c, err := b.conn.State.Channel(hook.ChannelID); err == nil {
+		hook.ChannelName = c.Name
+	}
+
+	updated, err := createHook(b.conn, hook, req.User)
+	if err != nil {
+		if _, err := b.conn.ChannelMessageSendComplex(hook.ChannelID, &discordgo.MessageSend{
+			Content:         tr(lang, "Failed to add the ModLogs hook for <https://twitch.tv/%s>: %s", req.User.Login, hookErrorMessage(err, lang)),
+			AllowedMentions: noMentions(),
+		}); err != nil {
+			log.WithError(err).Error("discord")
+		}
+		req.Result <- err
+		return
+	}
+
+	action := tr(lang, "added")
+	if updated {
+		action = tr(lang, "updated")
+	}
+
+	if _, err := b.conn.ChannelMessageSendComplex(hook.ChannelID, &discordgo.MessageSend{
+		Content:         tr(lang, "ModLogs hook %s for <https://twitch.tv/%s>, into <#%s>. Requested by <@%s>, authorized by %s.", action, req.User.Login, hook.ChannelID, req.Link.RequestedBy, authorizer),
+		AllowedMentions: noMentions(),
+	}); err != nil {
+		log.WithError(err).Error("discord")
+	}
+
+	req.Result <- nil
+}
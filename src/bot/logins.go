@@ -0,0 +1,305 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	log "github.com/sirupsen/logrus"
+	"github.com/troydota/modlogs/src/mongo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var searchCommand = &discordgo.ApplicationCommand{
+	Name:        "search",
+	Description: "Finds the twitch accounts which used a username, with their previous usernames.",
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "user",
+			Description: "The id, current or old username of the twitch account.",
+			Required:    true,
+		},
+	},
+}
+
+// previousLogins returns the logins the user went by before, from the renames seen by the resolver and the events logged
+// on the given broadcasters.
+func previousLogins(user *mongo.User, streamerIDs []string) ([]string, error) {
+	history := []*mongo.UserLogin{}
+	cur, err := mongo.Database.Collection("userlogins").Find(context.Background(), bson.M{
+		"user_id": user.ID,
+	}, options.Find().SetSort(bson.M{"changed_at": -1}))
+	if err == nil {
+		err = cur.All(context.Background(), &history)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	logins := []string{}
+	seen := map[string]bool{user.Login: true}
+	for _, h := range history {
+		if !seen[h.Login] {
+			seen[h.Login] = true
+			logins = append(logins, h.Login)
+		}
+	}
+
+	// Events store the login the user had at the time, which covers renames from before the history was kept.
+	values, err := mongo.Database.Collection("events").Distinct(context.Background(), "user_name", bson.M{
+		"user_id": user.ID,
+		"broadcaster_id": bson.M{
+			"$in": streamerIDs,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range values {
+		if login, ok := v.(string); ok && login != "" && !seen[strings.ToLower(login)] {
+			seen[strings.ToLower(login)] = true
+			logins = append(logins, strings.ToLower(login))
+		}
+	}
+
+	return logins, nil
+}
+
+// loginHolders returns the ids of the users who went by the login before, events only count on the given broadcasters.
+func loginHolders(login string, streamerIDs []string) ([]string, error) {
+	login = strings.ToLower(login)
+
+	ids := []string{}
+	seen := map[string]bool{}
+
+	values, err := mongo.Database.Collection("userlogins").Distinct(context.Background(), "user_id", bson.M{"login": login})
+	if err != nil {
+		return nil, err
+	}
+	events, err := mongo.Database.Collection("events").Distinct(context.Background(), "user_id", bson.M{
+		"user_name": login,
+		"broadcaster_id": bson.M{
+			"$in": streamerIDs,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, v := range append(values, events...) {
+		if id, ok := v.(string); ok && id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	return ids, nil
+}
+
+// findUser finds a twitch user by id, login or a login they went by before on the given broadcasters.
+func findUser(input string, streamerIDs []string) (*mongo.User, error) {
+	user, err := lookupUser(input)
+	if err != errUnknownUser {
+		return user, err
+	}
+
+	ids, err := loginHolders(input, streamerIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, errUnknownUser
+	}
+
+	users, err := resolveUsers(ids, nil)
+	for _, id := range ids {
+		if u, ok := users[id]; ok {
+			return u, nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, errUnknownUser
+}
+
+// previousLoginsValue lists the previous logins for an embed field, empty when the user was never renamed.
+func previousLoginsValue(user *mongo.User, streamerIDs []string) string {
+	logins, err := previousLogins(user, streamerIDs)
+	if err != nil {
+		log.WithError(err).Error("mongo")
+		return ""
+	}
+	if len(logins) == 0 {
+		return ""
+	}
+
+	lines := []string{}
+	for _, l := range logins {
+		lines = append(lines, fmt.Sprintf("`%s`", strings.ReplaceAll(l, "`", "")))
+	}
+	return strings.Join(lines, ", ")
+}
+
+// trackLogins refreshes the users of the event whose login changed since they were resolved, which records the rename.
+func trackLogins(e *mongo.Event) {
+	logins := map[string]string{
+		e.BroadcasterID: e.BroadcasterUserName,
+		e.UserID:        e.UserName,
+	}
+	if e.ModeratorID != "" {
+		logins[e.ModeratorID] = e.ModeratorUserName
+	}
+
+	ids := []string{}
+	for id := range logins {
+		if id != "" {
+			ids = append(ids, id)
+		}
+	}
+
+	stored := []*mongo.User{}
+	cur, err := mongo.Database.Collection("users").Find(context.Background(), bson.M{"id": bson.M{"$in": ids}})
+	if err == nil {
+		err = cur.All(context.Background(), &stored)
+	}
+	if err != nil {
+		log.WithError(err).Error("mongo")
+		return
+	}
+
+	renamed := []string{}
+	for _, u := range stored {
+		if login := strings.ToLower(logins[u.ID]); login != "" && login != u.Login {
+			renamed = append(renamed, u.ID)
+		}
+	}
+	if len(renamed) == 0 {
+		return
+	}
+
+	if _, err := mongo.Database.Collection("users").UpdateMany(context.Background(), bson.M{"id": bson.M{"$in": renamed}}, bson.M{
+		"$unset": bson.M{"updated_at": ""},
+	}); err != nil {
+		log.WithError(err).Error("mongo")
+		return
+	}
+	for _, id := range renamed {
+		resolvedUsers.remove(id)
+	}
+
+	if _, err := resolveUsers(renamed, nil); err != nil {
+		log.WithError(err).Error("api")
+	}
+}
+
+func searchHandler(s *discordgo.Session, i *discordgo.InteractionCreate, g *discordgo.Guild) {
+	var input string
+	for _, o := range i.Data.Options {
+		switch o.Name {
+		case "user":
+			input = strings.ToLower(strings.TrimSpace(o.StringValue()))
+		}
+	}
+
+	lang := guildLanguage(g.ID)
+
+	streamers, err := guildStreamers([]string{g.ID})
+	if err != nil {
+		log.WithError(err).Error("mongo")
+		respond(s, i, "Internal server error. Please try again later.", true)
+		return
+	}
+	// A nil slice would be stored as null, which $in refuses.
+	streamerIDs := append([]string{}, streamers[g.ID]...)
+
+	ids, err := loginHolders(input, streamerIDs)
+	if err != nil {
+		log.WithError(err).Error("mongo")
+		respond(s, i, "Internal server error. Please try again later.", true)
+		return
+	}
+
+	current, err := lookupUser(input)
+	if err != nil && err != errUnknownUser {
+		log.WithError(err).Error("api")
+		respond(s, i, "Internal server error. Please try again later.", true)
+		return
+	}
+	if current != nil {
+		ids = append([]string{current.ID}, ids...)
+	}
+
+	users, err := resolveUsers(ids, nil)
+	if err != nil {
+		log.WithError(err).Error("api")
+	}
+
+	fields := []*discordgo.MessageEmbedField{}
+	seen := map[string]bool{}
+	for _, id := range ids {
+		u, ok := users[id]
+		if !ok || seen[id] {
+			continue
+		}
+		// Discord rejects embeds with more than 25 fields.
+		if len(fields) == 25 {
+			break
+		}
+		seen[id] = true
+
+		count, err := mongo.Database.Collection("events").CountDocuments(context.Background(), bson.M{
+			"user_id": id,
+			"broadcaster_id": bson.M{
+				"$in": streamerIDs,
+			},
+		})
+		if err != nil {
+			log.WithError(err).Error("mongo")
+			respond(s, i, "Internal server error. Please try again later.", true)
+			return
+		}
+
+		lines := []string{tr(lang, "Current username: [%s](https://twitch.tv/%s)", escapeMarkdown(u.Login), u.Login)}
+		if v := previousLoginsValue(u, streamerIDs); v != "" {
+			lines = append(lines, tr(lang, "Previously known as: %s", v))
+		}
+		lines = append(lines, tr(lang, "Logged actions: %v", count))
+
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name:  fmt.Sprintf("%s (%s)", u.Name, u.ID),
+			Value: fieldValue(lines),
+		})
+	}
+
+	if len(fields) == 0 {
+		respond(s, i, "No twitch account used that username.", true)
+		return
+	}
+
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionApplicationCommandResponseData{
+			Embeds: []*discordgo.MessageEmbed{localizeEmbed(&discordgo.MessageEmbed{
+				Title:       "User Search",
+				Description: tr(lang, "Twitch accounts which used `%s`.", strings.ReplaceAll(input, "`", "")),
+				Color:       3447003,
+				Footer: &discordgo.MessageEmbedFooter{
+					Text: "KomodoHype",
+				},
+				Fields: fields,
+			}, lang)},
+			// Makes the response ephemeral https://discord.com/developers/docs/interactions/slash-commands#interaction-response
+			Flags: 64,
+		},
+	})
+	if err != nil {
+		log.WithError(err).Error("discord")
+	}
+}
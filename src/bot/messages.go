@@ -0,0 +1,152 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	log "github.com/sirupsen/logrus"
+	"github.com/troydota/modlogs/src/mongo"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+var errWebhookReplaced = fmt.Errorf("the webhook of the message was replaced")
+
+// storeLogMessage remembers the discord message posted for the event, so that it can be edited later on.
+func storeLogMessage(hook *mongo.Hook, eventID string, msg *discordgo.Message) {
+	m := &mongo.LogMessage{
+		EventID:    eventID,
+		GuildID:    hook.GuildID,
+		ChannelID:  hook.ChannelID,
+		StreamerID: hook.StreamerID,
+		MessageID:  msg.ID,
+		Mode:       hook.Mode,
+		CreatedAt:  time.Now(),
+	}
+	if hook.Delivery == mongo.DeliveryWebhook {
+		m.WebhookID = hook.WebhookID
+	}
+
+	if _, err := mongo.Database.Collection("messages").InsertOne(context.Background(), m); err != nil {
+		log.WithError(err).WithField("message", m).Error("mongo")
+	}
+}
+
+func findLogMessages(filter bson.M) ([]*mongo.LogMessage, error) {
+	messages := []*mongo.LogMessage{}
+	cur, err := mongo.Database.Collection("messages").Find(context.Background(), filter)
+	if err == nil {
+		err = cur.All(context.Background(), &messages)
+	}
+	return messages, err
+}
+
+// logMessageHook returns the hook the message was posted for, nil when the hook was removed since.
+func logMessageHook(m *mongo.LogMessage) (*mongo.Hook, error) {
+	hook := &mongo.Hook{}
+	err := mongo.Database.Collection("hooks").FindOne(context.Background(), bson.M{
+		"guild_id":    m.GuildID,
+		"channel_id":  m.ChannelID,
+		"streamer_id": m.StreamerID,
+	}).Decode(hook)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	return hook, err
+}
+
+func messageLink(m *mongo.LogMessage) string {
+	return fmt.Sprintf("https://discord.com/channels/%s/%s/%s", m.GuildID, m.ChannelID, m.MessageID)
+}
+
+// fetchLogMessage loads the message from discord, through the webhook when it was posted by one.
+func fetchLogMessage(s *discordgo.Session, hook *mongo.Hook, m *mongo.LogMessage) (*discordgo.Message, error) {
+	if m.WebhookID == "" {
+		return s.ChannelMessage(m.ChannelID, m.MessageID)
+	}
+	if m.WebhookID != hook.WebhookID {
+		return nil, errWebhookReplaced
+	}
+
+	endpoint := discordgo.EndpointWebhookMessage(hook.WebhookID, hook.WebhookToken, m.MessageID)
+	body, err := s.RequestWithBucketID("GET", endpoint, nil, discordgo.EndpointWebhookToken(hook.WebhookID, ""))
+	if err != nil {
+		return nil, err
+	}
+
+	msg := &discordgo.Message{}
+	err = json.Unmarshal(body, msg)
+	return msg, err
+}
+
+// annotateLogMessage sets a field on the embed of the message, replacing the field when it is already there.
+func annotateLogMessage(s *discordgo.Session, hook *mongo.Hook, m *mongo.LogMessage, name string, value string) error {
+	msg, err := fetchLogMessage(s, hook, m)
+	if err != nil {
+		return err
+	}
+	if len(msg.Embeds) == 0 {
+		return nil
+	}
+
+	// The name is translated before it is compared, the embed was posted in the language of the guild.
+	name = tr(guildLanguage(hook.GuildID), name)
+
+	embed := *msg.Embeds[0]
+	fields := []*discordgo.MessageEmbedField{}
+	for _, f := range embed.Fields {
+		if f.Name != name {
+			fields = append(fields, f)
+		}
+	}
+	embed.Fields = append(fields, &discordgo.MessageEmbedField{Name: name, Value: value})
+
+	if m.WebhookID != "" {
+		return s.WebhookMessageEdit(hook.WebhookID, hook.WebhookToken, m.MessageID, &discordgo.WebhookEdit{
+			Embeds: []*discordgo.MessageEmbed{&embed},
+		})
+	}
+
+	_, err = s.ChannelMessageEditEmbed(m.ChannelID, m.MessageID, &embed)
+	return err
+}
+
+// appendLogMessage adds a line to a minimal log message, the line is dropped when the message would get too long.
+func appendLogMessage(s *discordgo.Session, hook *mongo.Hook, m *mongo.LogMessage, line string) error {
+	msg, err := fetchLogMessage(s, hook, m)
+	if err != nil {
+		return err
+	}
+
+	content := fmt.Sprintf("%s\n%s", msg.Content, line)
+	if len(content) >= 2000 {
+		return nil
+	}
+
+	if m.WebhookID != "" {
+		return s.WebhookMessageEdit(hook.WebhookID, hook.WebhookToken, m.MessageID, &discordgo.WebhookEdit{
+			Content: content,
+		})
+	}
+
+	_, err = s.ChannelMessageEdit(m.ChannelID, m.MessageID, content)
+	return err
+}
+
+// editLogMessage replaces the embed and the components of the message, an empty list removes the components.
+func editLogMessage(s *discordgo.Session, hook *mongo.Hook, m *mongo.LogMessage, embed *discordgo.MessageEmbed, components []*component) error {
+	if m.WebhookID == "" {
+		return channelMessageEditComponents(s, m.ChannelID, m.MessageID, embed, components)
+	}
+	if m.WebhookID != hook.WebhookID {
+		return errWebhookReplaced
+	}
+
+	endpoint := discordgo.EndpointWebhookMessage(hook.WebhookID, hook.WebhookToken, m.MessageID)
+	_, err := s.RequestWithBucketID("PATCH", endpoint, map[string]interface{}{
+		"embeds":     []*discordgo.MessageEmbed{embed},
+		"components": components,
+	}, discordgo.EndpointWebhookToken(hook.WebhookID, ""))
+	return err
+}
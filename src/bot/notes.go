@@ -0,0 +1,389 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	log "github.com/sirupsen/logrus"
+	"github.com/troydota/modlogs/src/mongo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var errUnknownCase = fmt.Errorf("unknown case")
+
+var noteCommand = &discordgo.ApplicationCommand{
+	Name:        "note",
+	Description: "Attach context, such as why it happened or a clip, to a logged action.",
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionInteger,
+			Name:        "case",
+			Description: "The case number shown on the log.",
+			Required:    true,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "text",
+			Description: "The note.",
+			Required:    true,
+		},
+	},
+}
+
+var historyCommand = &discordgo.ApplicationCommand{
+	Name:        "history",
+	Description: "Shows the logged actions against a twitch user, with their notes.",
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "user",
+			Description: "The id or username of the twitch account.",
+			Required:    true,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "broadcaster",
+			Description: "The ID or name of the twitch streamer, defaults to every hooked streamer.",
+			Required:    false,
+		},
+	},
+}
+
+func init() {
+	componentHandlers["note"] = componentRoute{permissionManage, noteButtonHandler}
+	componentHandlers["notesubmit"] = componentRoute{permissionManage, noteSubmitHandler}
+}
+
+// noteButton is added to the embed logs, it opens a form to add a note to the case.
+func noteButton(c int64) *component {
+	return &component{
+		Type:     componentButton,
+		Style:    buttonSecondary,
+		Label:    "Add note",
+		CustomID: fmt.Sprintf("note:%v", c),
+	}
+}
+
+// caseEvent returns the event of the case, when its broadcaster is hooked in the guild.
+func caseEvent(guildID string, c int64) (*mongo.Event, error) {
+	e := &mongo.Event{}
+	if err := mongo.Database.Collection("events").FindOne(context.Background(), bson.M{"case": c}).Decode(e); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errUnknownCase
+		}
+		return nil, err
+	}
+
+	count, err := mongo.Database.Collection("hooks").CountDocuments(context.Background(), bson.M{
+		"guild_id":    guildID,
+		"streamer_id": e.BroadcasterID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, errUnknownCase
+	}
+
+	return e, nil
+}
+
+func findNotes(filter bson.M) ([]*mongo.Note, error) {
+	notes := []*mongo.Note{}
+	cur, err := mongo.Database.Collection("notes").Find(context.Background(), filter, options.Find().SetSort(bson.M{"created_at": 1}))
+	if err == nil {
+		err = cur.All(context.Background(), &notes)
+	}
+	return notes, err
+}
+
+// notesValue lists the notes for an embed field, the oldest notes are dropped when they don't fit.
+func notesValue(notes []*mongo.Note) string {
+	lines := []string{}
+	length := 0
+	for n := len(notes) - 1; n >= 0; n-- {
+		line := fmt.Sprintf("<@%s>: %s", notes[n].AuthorID, notes[n].Text)
+		if length+len(line)+1 > 1000 {
+			break
+		}
+		length += len(line) + 1
+		lines = append([]string{line}, lines...)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// addNote stores the note and shows it on the logs of the case posted in the guild.
+func addNote(s *discordgo.Session, guildID string, c int64, authorID string, text string) error {
+	e, err := caseEvent(guildID, c)
+	if err != nil {
+		return err
+	}
+
+	note := &mongo.Note{
+		Case:      c,
+		EventID:   e.ID,
+		GuildID:   guildID,
+		AuthorID:  authorID,
+		Text:      text,
+		CreatedAt: time.Now(),
+	}
+	if _, err := mongo.Database.Collection("notes").InsertOne(context.Background(), note); err != nil {
+		return err
+	}
+
+	notes, err := findNotes(bson.M{"guild_id": guildID, "case": c})
+	if err != nil {
+		log.WithError(err).Error("mongo")
+		return nil
+	}
+
+	messages, err := findLogMessages(bson.M{"event_id": e.ID, "guild_id": guildID})
+	if err != nil {
+		log.WithError(err).Error("mongo")
+		return nil
+	}
+
+	for _, m := range messages {
+		hook, err := logMessageHook(m)
+		if err != nil {
+			log.WithError(err).WithField("message", m).Error("mongo")
+			continue
+		}
+		if hook == nil {
+			continue
+		}
+		if m.Mode == mongo.ModeEmbed {
+			err = annotateLogMessage(s, hook, m, "Notes", notesValue(notes))
+		} else {
+			err = appendLogMessage(s, hook, m, tr(guildLanguage(hook.GuildID), "↳ Note on `#%v`: %s", c, text))
+		}
+		if err != nil {
+			log.WithError(err).WithField("message", m).Error("discord")
+		}
+	}
+
+	return nil
+}
+
+func noteErrorMessage(err error) string {
+	if err == errUnknownCase {
+		return "That case doesn't belong to a broadcaster hooked in this discord."
+	}
+	log.WithError(err).Error("mongo")
+	return "Internal server error. Please try again later."
+}
+
+func noteHandler(s *discordgo.Session, i *discordgo.InteractionCreate, g *discordgo.Guild) {
+	var c int64
+	var text string
+
+	for _, o := range i.Data.Options {
+		switch o.Name {
+		case "case":
+			c = o.IntValue()
+		case "text":
+			text = strings.TrimSpace(o.StringValue())
+		}
+	}
+
+	if text == "" {
+		respond(s, i, "Please enter a note.", true)
+		return
+	}
+
+	if err := addNote(s, g.ID, c, i.Member.User.ID, text); err != nil {
+		respond(s, i, noteErrorMessage(err), true)
+		return
+	}
+
+	respondf(s, i, true, "Added the note to case `#%v`.", c)
+}
+
+func noteButtonHandler(s *discordgo.Session, i *discordgo.InteractionCreate, c *componentInteraction, g *discordgo.Guild, arg string) {
+	lang := guildLanguage(i.GuildID)
+	if err := showModal(s, i, fmt.Sprintf("notesubmit:%s", arg), tr(lang, "Note on case #%s", arg), tr(lang, "Why did it happen? Links to clips?")); err != nil {
+		log.WithError(err).Error("discord")
+	}
+}
+
+func noteSubmitHandler(s *discordgo.Session, i *discordgo.InteractionCreate, c *componentInteraction, g *discordgo.Guild, arg string) {
+	caseID, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil {
+		respond(s, i, "Invalid case.", true)
+		return
+	}
+
+	text := strings.TrimSpace(c.value("text"))
+	if text == "" {
+		respond(s, i, "Please enter a note.", true)
+		return
+	}
+
+	if err := addNote(s, g.ID, caseID, i.Member.User.ID, text); err != nil {
+		respond(s, i, noteErrorMessage(err), true)
+		return
+	}
+
+	respondf(s, i, true, "Added the note to case `#%v`.", caseID)
+}
+
+// historyEmbed lists the latest actions against the user on the given broadcasters, with the notes of the guild.
+func historyEmbed(guildID string, user *mongo.User, streamerIDs []string) (*discordgo.MessageEmbed, error) {
+	lang := guildLanguage(guildID)
+
+	events := []*mongo.Event{}
+	cur, err := mongo.Database.Collection("events").Find(context.Background(), bson.M{
+		"user_id": user.ID,
+		"broadcaster_id": bson.M{
+			"$in": streamerIDs,
+		},
+		"action": bson.M{
+			"$in": bson.A{"channel.ban", "channel.unban"},
+		},
+	}, options.Find().SetSort(bson.M{"created_at": -1}).SetLimit(10))
+	if err == nil {
+		err = cur.All(context.Background(), &events)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	cases := []int64{}
+	for _, e := range events {
+		cases = append(cases, e.Case)
+	}
+	notes, err := findNotes(bson.M{"guild_id": guildID, "case": bson.M{"$in": cases}})
+	if err != nil {
+		return nil, err
+	}
+	caseNotes := map[int64][]*mongo.Note{}
+	for _, n := range notes {
+		caseNotes[n.Case] = append(caseNotes[n.Case], n)
+	}
+
+	fields := []*discordgo.MessageEmbedField{}
+	for _, e := range events {
+		moderator := e.ModeratorUserName
+		if moderator == "" {
+			moderator = e.BroadcasterUserName
+		}
+
+		var action string
+		switch eventKind(e) {
+		case kindBan:
+			action = tr(lang, "Ban")
+		case kindTimeout:
+			action = tr(lang, "Timeout (%s)", formatDuration(e.Expires.Sub(e.CreatedAt)))
+		case kindUnban:
+			action = tr(lang, "Unban")
+		}
+
+		lines := []string{tr(lang, "By %s on %s", moderator, discordTime(e.CreatedAt))}
+		if e.Reason != "" {
+			lines = append(lines, tr(lang, "Reason: %s", e.Reason))
+		}
+		if v := notesValue(caseNotes[e.Case]); v != "" {
+			lines = append(lines, v)
+		}
+
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name:  tr(lang, "#%v %s in #%s", e.Case, action, e.BroadcasterUserName),
+			Value: fieldValue(lines),
+		})
+	}
+
+	description := tr(lang, "Latest actions against %s.", user.Name)
+	if len(fields) == 0 {
+		description = tr(lang, "No actions against %s were logged.", user.Name)
+	}
+	if v := previousLoginsValue(user, streamerIDs); v != "" {
+		description = fmt.Sprintf("%s\n%s", description, tr(lang, "Previously known as %s.", v))
+	}
+
+	return localizeEmbed(&discordgo.MessageEmbed{
+		Title:       "User History",
+		Description: description,
+		Color:       3447003,
+		Timestamp:   time.Now().Format(time.RFC3339),
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "KomodoHype",
+		},
+		Fields: fields,
+	}, lang), nil
+}
+
+func historyHandler(s *discordgo.Session, i *discordgo.InteractionCreate, g *discordgo.Guild) {
+	var userInput string
+	var broadcaster string
+
+	for _, o := range i.Data.Options {
+		switch o.Name {
+		case "user":
+			userInput = o.StringValue()
+		case "broadcaster":
+			broadcaster = strings.ToLower(o.StringValue())
+		}
+	}
+
+	streamers, err := guildStreamers([]string{g.ID})
+	if err != nil {
+		log.WithError(err).Error("mongo")
+		respond(s, i, "Internal server error. Please try again later.", true)
+		return
+	}
+
+	streamerIDs := streamers[g.ID]
+	if broadcaster != "" {
+		user, err := hookedBroadcaster(g.ID, broadcaster)
+		if err != nil {
+			if err == errNotHooked {
+				respond(s, i, "That broadcaster is not hooked in this discord.", true)
+				return
+			}
+			log.WithError(err).Error("mongo")
+			respond(s, i, "Internal server error. Please try again later.", true)
+			return
+		}
+		streamerIDs = []string{user.ID}
+	}
+
+	if len(streamerIDs) == 0 {
+		respond(s, i, "There are no hooks in this discord.", true)
+		return
+	}
+
+	user, err := findUser(userInput, streamerIDs)
+	if err != nil {
+		if err == errUnknownUser {
+			respond(s, i, "The specified user does not exist.", true)
+			return
+		}
+		log.WithError(err).Error("mongo")
+		respond(s, i, "Internal server error. Please try again later.", true)
+		return
+	}
+
+	embed, err := historyEmbed(g.ID, user, streamerIDs)
+	if err != nil {
+		log.WithError(err).Error("mongo")
+		respond(s, i, "Internal server error. Please try again later.", true)
+		return
+	}
+
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionApplicationCommandResponseData{
+			Embeds: []*discordgo.MessageEmbed{embed},
+			// Makes the response ephemeral https://discord.com/developers/docs/interactions/slash-commands#interaction-response
+			Flags: 64,
+		},
+	})
+	if err != nil {
+		log.WithError(err).Error("discord")
+	}
+}
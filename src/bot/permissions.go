@@ -0,0 +1,317 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	log "github.com/sirupsen/logrus"
+	"github.com/troydota/modlogs/src/configure"
+	"github.com/troydota/modlogs/src/mongo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	permissionNone int = iota
+	// Can look at hooks, ignored users, statistics and history.
+	permissionRead
+	// Can change hooks and everything else about the logs.
+	permissionManage
+	// Guild owner, global bot admins and discord administrators, can also change permissions.
+	permissionAdmin
+)
+
+var permissionsCommand = &discordgo.ApplicationCommand{
+	Name:        "permissions",
+	Description: "Manage which roles can use the modlogs commands.",
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "grant",
+			Description: "Allows a role to use the modlogs commands.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionRole,
+					Name:        "role",
+					Description: "The discord role.",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "level",
+					Description: "Managers can change hooks, readers can only look at them.",
+					Required:    true,
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "manager", Value: "manager"},
+						{Name: "reader", Value: "reader"},
+					},
+				},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "revoke",
+			Description: "Removes the modlogs permissions of a role.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionRole,
+					Name:        "role",
+					Description: "The discord role.",
+					Required:    true,
+				},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "list",
+			Description: "Shows the roles which can use the modlogs commands.",
+		},
+	},
+}
+
+// commandOverrides caches the discord application command permission overrides of each guild command.
+var commandOverrides = struct {
+	sync.Mutex
+	entries map[string]*commandOverride
+}{entries: map[string]*commandOverride{}}
+
+type commandOverride struct {
+	permissions []*commandPermission
+	expires     time.Time
+}
+
+type commandPermission struct {
+	ID         string `json:"id"`
+	Type       int    `json:"type"`
+	Permission bool   `json:"permission"`
+}
+
+type guildCommandPermissions struct {
+	ID          string               `json:"id"`
+	Permissions []*commandPermission `json:"permissions"`
+}
+
+// overrideAllows reports if the guild explicitly allowed the member to use the command in the discord integration settings,
+// explicit denies are honored.
+func overrideAllows(s *discordgo.Session, i *discordgo.InteractionCreate) bool {
+	key := fmt.Sprintf("%s:%s", i.GuildID, i.Data.ID)
+
+	commandOverrides.Lock()
+	entry, ok := commandOverrides.entries[key]
+	commandOverrides.Unlock()
+
+	if !ok || entry.expires.Before(time.Now()) {
+		entry = &commandOverride{expires: time.Now().Add(time.Minute)}
+		endpoint := discordgo.EndpointApplicationGuildCommand(s.State.User.ID, i.GuildID, i.Data.ID) + "/permissions"
+		body, err := s.RequestWithBucketID("GET", endpoint, nil, endpoint)
+		if err == nil {
+			perms := &guildCommandPermissions{}
+			if err := json.Unmarshal(body, perms); err == nil {
+				entry.permissions = perms.Permissions
+			}
+		}
+		commandOverrides.Lock()
+		commandOverrides.entries[key] = entry
+		commandOverrides.Unlock()
+	}
+
+	// The override of the member wins over the ones of their roles, and a denied role wins over an allowed one.
+	for _, p := range entry.permissions {
+		if p.Type == 2 && p.ID == i.Member.User.ID {
+			return p.Permission
+		}
+	}
+
+	allowed := false
+	for _, p := range entry.permissions {
+		if p.Type != 1 {
+			continue
+		}
+		for _, r := range i.Member.Roles {
+			if r == p.ID {
+				if !p.Permission {
+					return false
+				}
+				allowed = true
+			}
+		}
+	}
+
+	return allowed
+}
+
+// memberPermission returns the modlogs permission level of the member who created the interaction.
+func memberPermission(s *discordgo.Session, i *discordgo.InteractionCreate, guild *discordgo.Guild) (int, error) {
+	if i.Member.User.ID == guild.OwnerID {
+		return permissionAdmin, nil
+	}
+
+	for _, u := range configure.Config.GetStringSlice("admins") {
+		if u == i.Member.User.ID {
+			return permissionAdmin, nil
+		}
+	}
+
+	for _, r := range guild.Roles {
+		for _, m := range i.Member.Roles {
+			if m == r.ID && r.Permissions&discordgo.PermissionAdministrator != 0 {
+				return permissionAdmin, nil
+			}
+		}
+	}
+
+	perms := &mongo.Permissions{}
+	err := mongo.Database.Collection("permissions").FindOne(context.Background(), bson.M{"guild_id": guild.ID}).Decode(perms)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return permissionNone, err
+	}
+
+	level := permissionNone
+	for _, m := range i.Member.Roles {
+		for _, r := range perms.ManagerRoles {
+			if r == m {
+				return permissionManage, nil
+			}
+		}
+		for _, r := range perms.ReaderRoles {
+			if r == m {
+				level = permissionRead
+			}
+		}
+	}
+
+	return level, nil
+}
+
+// permissionWrapper only runs the command when the member has at least the given modlogs permission level.
+func permissionWrapper(required int, next func(s *discordgo.Session, i *discordgo.InteractionCreate, g *discordgo.Guild)) func(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	return func(s *discordgo.Session, i *discordgo.InteractionCreate) {
+		var guild *discordgo.Guild
+
+		for _, g := range s.State.Guilds {
+			if g.ID == i.GuildID {
+				guild = g
+				break
+			}
+		}
+
+		if guild == nil || i.Member == nil {
+			respond(s, i, "Internal Server Error. Please try again later...", true)
+			return
+		}
+
+		level, err := memberPermission(s, i, guild)
+		if err != nil {
+			log.WithError(err).Error("mongo")
+			respond(s, i, "Internal Server Error. Please try again later...", true)
+			return
+		}
+
+		// Overrides set in the discord integration settings count as manager, never as admin.
+		if level < permissionManage && required <= permissionManage && overrideAllows(s, i) {
+			level = permissionManage
+		}
+
+		if level < required {
+			respond(s, i, "You do not have permission to execute that command.", true)
+			return
+		}
+
+		next(s, i, guild)
+	}
+}
+
+func permissionsHandler(s *discordgo.Session, i *discordgo.InteractionCreate, g *discordgo.Guild) {
+	if len(i.Data.Options) == 0 {
+		respond(s, i, "Please select a sub command.", true)
+		return
+	}
+
+	sub := i.Data.Options[0]
+
+	if sub.Name == "list" {
+		perms := &mongo.Permissions{}
+		err := mongo.Database.Collection("permissions").FindOne(context.Background(), bson.M{"guild_id": g.ID}).Decode(perms)
+		if err != nil && err != mongo.ErrNoDocuments {
+			log.WithError(err).Error("mongo")
+			respond(s, i, "Internal server error. Please try again later.", true)
+			return
+		}
+
+		managers := []string{}
+		for _, r := range perms.ManagerRoles {
+			managers = append(managers, fmt.Sprintf("<@&%s>", r))
+		}
+		readers := []string{}
+		for _, r := range perms.ReaderRoles {
+			readers = append(readers, fmt.Sprintf("<@&%s>", r))
+		}
+		if len(managers) == 0 {
+			managers = append(managers, tr(guildLanguage(g.ID), "None"))
+		}
+		if len(readers) == 0 {
+			readers = append(readers, tr(guildLanguage(g.ID), "None"))
+		}
+
+		respondf(s, i, true, "Managers: %s\nReaders: %s\nThe server owner and administrators can always use every command.", strings.Join(managers, ", "), strings.Join(readers, ", "))
+		return
+	}
+
+	var role *discordgo.Role
+	var level string
+	for _, o := range sub.Options {
+		switch o.Name {
+		case "role":
+			role = o.RoleValue(s, g.ID)
+		case "level":
+			level = o.StringValue()
+		}
+	}
+
+	if role == nil {
+		respond(s, i, "Please select a valid role.", true)
+		return
+	}
+
+	update := bson.M{
+		"$pull": bson.M{
+			"manager_roles": role.ID,
+			"reader_roles":  role.ID,
+		},
+	}
+
+	if _, err := mongo.Database.Collection("permissions").UpdateOne(context.Background(), bson.M{"guild_id": g.ID}, update); err != nil {
+		log.WithError(err).Error("mongo")
+		respond(s, i, "Internal server error. Please try again later.", true)
+		return
+	}
+
+	if sub.Name == "revoke" {
+		respondf(s, i, true, "<@&%s> can no longer use the modlogs commands.", role.ID)
+		return
+	}
+
+	field := "reader_roles"
+	if level == "manager" {
+		field = "manager_roles"
+	}
+
+	if _, err := mongo.Database.Collection("permissions").UpdateOne(context.Background(), bson.M{"guild_id": g.ID}, bson.M{
+		"$set": bson.M{
+			"guild_id": g.ID,
+		},
+		"$addToSet": bson.M{
+			field: role.ID,
+		},
+	}, options.Update().SetUpsert(true)); err != nil {
+		log.WithError(err).Error("mongo")
+		respond(s, i, "Internal server error. Please try again later.", true)
+		return
+	}
+
+	respondf(s, i, true, "<@&%s> is now a modlogs %s.", role.ID, level)
+}
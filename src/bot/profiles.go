@@ -0,0 +1,165 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	log "github.com/sirupsen/logrus"
+	"github.com/troydota/modlogs/src/mongo"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+var profilesCommand = &discordgo.ApplicationCommand{
+	Name:        "profiles",
+	Description: "Show the twitch profiles of the users in the embed logs of a broadcaster.",
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "broadcaster",
+			Description: "The ID or name of the twitch streamer.",
+			Required:    true,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionBoolean,
+			Name:        "enabled",
+			Description: "Adds display names, links, ids, account ages and the profile image of the user.",
+			Required:    true,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionChannel,
+			Name:        "channel",
+			Description: "Text channel where the hook is active.",
+			Required:    false,
+		},
+	},
+}
+
+func profilesHandler(s *discordgo.Session, i *discordgo.InteractionCreate, g *discordgo.Guild) {
+	var broadcaster string
+	var enabled bool
+	var channel *discordgo.Channel
+
+	for _, o := range i.Data.Options {
+		switch o.Name {
+		case "broadcaster":
+			broadcaster = o.StringValue()
+		case "enabled":
+			enabled = o.BoolValue()
+		case "channel":
+			channel = o.ChannelValue(s)
+		}
+	}
+
+	user, err := hookedBroadcaster(g.ID, broadcaster)
+	if err != nil {
+		if err == errNotHooked {
+			respond(s, i, "That broadcaster is not hooked in this discord.", true)
+			return
+		}
+		log.WithError(err).Error("mongo")
+		respond(s, i, "Internal server error. Please try again later.", true)
+		return
+	}
+
+	filter := bson.M{
+		"guild_id":    g.ID,
+		"streamer_id": user.ID,
+	}
+	if channel != nil {
+		filter["channel_id"] = channel.ID
+	}
+
+	res, err := mongo.Database.Collection("hooks").UpdateMany(context.Background(), filter, bson.M{
+		"$set": bson.M{
+			"profiles": enabled,
+		},
+	})
+	if err != nil {
+		log.WithError(err).Error("mongo")
+		respond(s, i, "Internal server error. Please try again later.", true)
+		return
+	}
+	if res.MatchedCount == 0 {
+		respond(s, i, "That broadcaster is not hooked in that channel.", true)
+		return
+	}
+
+	if enabled {
+		respondf(s, i, false, "Embed logs of <https://twitch.tv/%s> will show the twitch profiles of the users.", user.Login)
+	} else {
+		respondf(s, i, false, "Embed logs of <https://twitch.tv/%s> will no longer show the twitch profiles of the users.", user.Login)
+	}
+}
+
+// profileValue links the twitch profile, with the id and the account age when known.
+func profileValue(login string, id string, profile *mongo.User, age bool) string {
+	if profile == nil {
+		return login
+	}
+
+	value := fmt.Sprintf("[%s](https://twitch.tv/%s) (`%s`)", escapeMarkdown(profile.Name), profile.Login, id)
+	if age && profile.CreatedAt != nil {
+		value = fmt.Sprintf("%s\nCreated %s", value, accountAge(profile.CreatedAt))
+	}
+	return value
+}
+
+// enrichEmbed returns a copy of the log embed with the twitch profiles of the users in it, nil when none could be loaded.
+func enrichEmbed(embed *discordgo.MessageEmbed, e *mongo.Event) *discordgo.MessageEmbed {
+	profiles, err := resolveUsers([]string{e.BroadcasterID, e.UserID, e.ModeratorID}, nil)
+	if err != nil {
+		log.WithError(err).Error("api")
+	}
+	if len(profiles) == 0 {
+		return nil
+	}
+
+	enriched := *embed
+	enriched.Fields = []*discordgo.MessageEmbedField{}
+	for _, f := range embed.Fields {
+		field := *f
+		switch f.Name {
+		case "Broadcaster":
+			field.Value = profileValue(f.Value, e.BroadcasterID, profiles[e.BroadcasterID], false)
+		case "User":
+			field.Value = profileValue(f.Value, e.UserID, profiles[e.UserID], true)
+		case "Moderator":
+			if e.ModeratorID != "" {
+				field.Value = profileValue(f.Value, e.ModeratorID, profiles[e.ModeratorID], false)
+			}
+		}
+		enriched.Fields = append(enriched.Fields, &field)
+	}
+
+	if u, ok := profiles[e.UserID]; ok && u.ProfileImageURL != "" {
+		enriched.Thumbnail = &discordgo.MessageEmbedThumbnail{URL: u.ProfileImageURL}
+	}
+
+	return &enriched
+}
+
+// wantsProfiles is true when a hook shows the twitch profiles of the users.
+func wantsProfiles(hooks []*mongo.Hook) bool {
+	for _, hook := range hooks {
+		if hook.Profiles && hook.Mode == mongo.ModeEmbed {
+			return true
+		}
+	}
+	return false
+}
+
+// escapeMarkdown keeps display names from breaking the links of the embed.
+func escapeMarkdown(s string) string {
+	return strings.NewReplacer("[", "\\[", "]", "\\]", "*", "\\*", "_", "\\_", "`", "\\`").Replace(s)
+}
+
+// codeSpan shows text written by twitch users as inline code, so it can't format or mention anything.
+func codeSpan(s string) string {
+	s = strings.TrimSpace(strings.NewReplacer("`", "'", "\r", " ", "\n", " ").Replace(s))
+	if s == "" {
+		return "` `"
+	}
+	return fmt.Sprintf("`%s`", s)
+}
@@ -8,7 +8,8 @@ import (
 	"go.uber.org/ratelimit"
 )
 
-type merger func(content string) bool
+// merger adds the content of a limited message, sent for the id, to a waiting message.
+type merger func(content string, id string) bool
 
 type mergerWrapper struct {
 	id     string
@@ -33,7 +34,7 @@ func newLimiter() *rateLimiter {
 	}
 }
 
-func (r *rateLimiter) Limit(key string, content string, merge merger) bool {
+func (r *rateLimiter) Limit(key string, id string, content string, merge merger) bool {
 	r.mtx.Lock()
 	v, ok := r.limits[key]
 	if ok {
@@ -50,15 +51,15 @@ func (r *rateLimiter) Limit(key string, content string, merge merger) bool {
 	v.mtx.Lock()
 	if len(v.limited) != 0 {
 		for _, vl := range v.limited {
-			if vl.merger(content) {
+			if vl.merger(content, id) {
 				v.mtx.Unlock()
 				return false
 			}
 		}
 	}
-	id, _ := uuid.NewRandom()
+	uid, _ := uuid.NewRandom()
 	mw := &mergerWrapper{
-		id:     id.String(),
+		id:     uid.String(),
 		merger: merge,
 	}
 	v.limited = append(v.limited, mw)
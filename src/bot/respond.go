@@ -0,0 +1,25 @@
+package bot
+
+import (
+	"github.com/bwmarrin/discordgo"
+	log "github.com/sirupsen/logrus"
+)
+
+// respond replies to the interaction with a plain message in the language of the guild, logging any failure.
+func respond(s *discordgo.Session, i *discordgo.InteractionCreate, content string, ephemeral bool) {
+	data := &discordgo.InteractionApplicationCommandResponseData{
+		Content:         tr(guildLanguage(i.GuildID), content),
+		AllowedMentions: noMentions(),
+	}
+	if ephemeral {
+		// Makes the response ephemeral https://discord.com/developers/docs/interactions/slash-commands#interaction-response
+		data.Flags = 64
+	}
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: data,
+	})
+	if err != nil {
+		log.WithError(err).Error("discord")
+	}
+}
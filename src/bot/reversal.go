@@ -0,0 +1,116 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+	log "github.com/sirupsen/logrus"
+	"github.com/troydota/modlogs/src/mongo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// reversedEvent marks the ban or timeout the unban lifted as reversed and returns it, nil when the user had no active
+// ban or timeout or it was already marked.
+func reversedEvent(unban *mongo.Event) (*mongo.Event, error) {
+	last := &mongo.Event{}
+	err := mongo.Database.Collection("events").FindOne(context.Background(), bson.M{
+		"broadcaster_id": unban.BroadcasterID,
+		"user_id":        unban.UserID,
+		"action":         bson.M{"$in": bson.A{"channel.ban", "channel.unban"}},
+		"id":             bson.M{"$ne": unban.ID},
+		"created_at": bson.M{
+			"$lte": unban.CreatedAt,
+		},
+	}, options.FindOne().SetSort(bson.M{"created_at": -1})).Decode(last)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// A later unban or an expired timeout means nothing was active anymore.
+	if last.Action != "channel.ban" || last.ReversedBy != "" || (last.Expires != nil && !last.Expires.After(unban.CreatedAt)) {
+		return nil, nil
+	}
+
+	res, err := mongo.Database.Collection("events").UpdateOne(context.Background(), bson.M{
+		"id":          last.ID,
+		"reversed_by": bson.M{"$exists": false},
+	}, bson.M{
+		"$set": bson.M{"reversed_by": unban.ID},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if res.ModifiedCount == 0 {
+		return nil, nil
+	}
+
+	return last, nil
+}
+
+// markReversed edits the messages of the ban or timeout the unban lifts, linking to the unban message of the same
+// channel when one was posted. unbanMessages are the messages of the unban, keyed by guild and channel.
+func (b *Bot) markReversed(unban *mongo.Event, unbanMessages map[string]*discordgo.Message) {
+	ban, err := reversedEvent(unban)
+	if err != nil {
+		log.WithError(err).Error("mongo")
+		return
+	}
+	if ban == nil {
+		return
+	}
+
+	messages, err := findLogMessages(bson.M{"event_id": ban.ID})
+	if err != nil {
+		log.WithError(err).Error("mongo")
+		return
+	}
+
+	moderator := unban.ModeratorUserName
+	if moderator == "" {
+		moderator = unban.BroadcasterUserName
+	}
+	at := discordTime(unban.CreatedAt)
+
+	for _, m := range messages {
+		hook, err := logMessageHook(m)
+		if err != nil {
+			log.WithError(err).WithField("message", m).Error("mongo")
+			continue
+		}
+		if hook == nil {
+			continue
+		}
+
+		var link string
+		if msg, ok := unbanMessages[reversalKey(m.GuildID, m.ChannelID)]; ok {
+			link = messageLink(&mongo.LogMessage{GuildID: m.GuildID, ChannelID: m.ChannelID, MessageID: msg.ID})
+		}
+
+		lang := guildLanguage(hook.GuildID)
+		if m.Mode == mongo.ModeEmbed {
+			value := tr(lang, "Reversed by %s at %s", moderator, at)
+			if link != "" {
+				value = fmt.Sprintf("%s\n%s", value, tr(lang, "[Unban message](%s)", link))
+			}
+			err = annotateLogMessage(b.conn, hook, m, "Reversed", value)
+		} else {
+			line := tr(lang, "↳ Reversed by `%s` at %s", moderator, at)
+			if link != "" {
+				line = fmt.Sprintf("%s (<%s>)", line, link)
+			}
+			err = appendLogMessage(b.conn, hook, m, line)
+		}
+		if err != nil {
+			log.WithError(err).WithField("message", m).Error("discord")
+		}
+	}
+}
+
+func reversalKey(guildID string, channelID string) string {
+	return fmt.Sprintf("%s:%s", guildID, channelID)
+}
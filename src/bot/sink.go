@@ -0,0 +1,285 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	log "github.com/sirupsen/logrus"
+	"github.com/troydota/modlogs/src/mongo"
+	"github.com/troydota/modlogs/src/sinks"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var sinkCommand = &discordgo.ApplicationCommand{
+	Name:        "sink",
+	Description: "Manage Slack and Matrix outputs for the hooks in this discord.",
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "slack",
+			Description: "Sends the logs of a hooked broadcaster to a Slack incoming webhook.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "broadcaster",
+					Description: "The ID or name of the twitch streamer.",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "url",
+					Description: "The Slack incoming webhook url.",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionBoolean,
+					Name:        "minimal",
+					Description: "Minimal mode.",
+					Required:    false,
+				},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "matrix",
+			Description: "Sends the logs of a hooked broadcaster to a Matrix room.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "broadcaster",
+					Description: "The ID or name of the twitch streamer.",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "homeserver",
+					Description: "The homeserver url, such as https://matrix.org.",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "room",
+					Description: "The room ID, such as !abcdef:matrix.org.",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "token",
+					Description: "Access token of the account posting into the room.",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionBoolean,
+					Name:        "minimal",
+					Description: "Minimal mode.",
+					Required:    false,
+				},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "list",
+			Description: "Shows the Slack and Matrix outputs of this discord.",
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "delete",
+			Description: "Removes the Slack and Matrix outputs of a broadcaster.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "broadcaster",
+					Description: "The ID or name of the twitch streamer.",
+					Required:    true,
+				},
+			},
+		},
+	},
+}
+
+func sinkHandler(s *discordgo.Session, i *discordgo.InteractionCreate, g *discordgo.Guild) {
+	if len(i.Data.Options) == 0 {
+		respond(s, i, "Please select a sub command.", true)
+		return
+	}
+
+	sub := i.Data.Options[0]
+
+	if sub.Name == "list" {
+		sinkList(s, i, g)
+		return
+	}
+
+	var broadcaster string
+	sink := &mongo.Sink{
+		GuildID: g.ID,
+		Mode:    mongo.ModeEmbed,
+	}
+
+	for _, o := range sub.Options {
+		switch o.Name {
+		case "broadcaster":
+			broadcaster = strings.ToLower(o.StringValue())
+		case "url", "homeserver":
+			sink.URL = o.StringValue()
+		case "room":
+			sink.RoomID = o.StringValue()
+		case "token":
+			sink.Token = o.StringValue()
+		case "minimal":
+			if o.BoolValue() {
+				sink.Mode = mongo.ModeMinimal
+			}
+		}
+	}
+
+	// Only broadcasters which are already hooked in this discord can be routed elsewhere.
+	user, err := hookedBroadcaster(g.ID, broadcaster)
+	if err != nil {
+		if err == errNotHooked {
+			respond(s, i, "That broadcaster is not hooked in this discord, use /add first.", true)
+			return
+		}
+		log.WithError(err).Error("mongo")
+		respond(s, i, "Internal server error. Please try again later.", true)
+		return
+	}
+
+	sink.StreamerID = user.ID
+
+	if sub.Name == "delete" {
+		delres, err := mongo.Database.Collection("sinks").DeleteMany(context.Background(), bson.M{
+			"guild_id":    g.ID,
+			"streamer_id": user.ID,
+		})
+		if err != nil {
+			log.WithError(err).Error("mongo")
+			respond(s, i, "Internal server error. Please try again later.", true)
+			return
+		}
+		if delres.DeletedCount == 0 {
+			respond(s, i, "That broadcaster has no outputs.", true)
+			return
+		}
+		respondf(s, i, false, "Removed %v output(s) for <https://twitch.tv/%s>.", delres.DeletedCount, user.Login)
+		return
+	}
+
+	var name string
+	filter := bson.M{
+		"guild_id":    g.ID,
+		"streamer_id": user.ID,
+	}
+	if sub.Name == "slack" {
+		sink.Type = mongo.SinkSlack
+		name = "Slack"
+		filter["url"] = sink.URL
+	} else {
+		sink.Type = mongo.SinkMatrix
+		name = "Matrix"
+		filter["url"] = sink.URL
+		filter["room_id"] = sink.RoomID
+	}
+	filter["type"] = sink.Type
+
+	if err := sinks.ValidateURL(context.Background(), sink.Type, sink.URL); err != nil {
+		if sink.Type == mongo.SinkSlack {
+			respond(s, i, "Please enter a Slack incoming webhook url, such as https://hooks.slack.com/services/....", true)
+			return
+		}
+		respond(s, i, "Please enter a valid https url of a public homeserver.", true)
+		return
+	}
+
+	if _, err := mongo.Database.Collection("sinks").UpdateOne(context.Background(), filter, bson.M{"$set": sink}, options.Update().SetUpsert(true)); err != nil {
+		log.WithError(err).Error("mongo")
+		respond(s, i, "Internal server error. Please try again later.", true)
+		return
+	}
+
+	respondf(s, i, true, "%s output added for <https://twitch.tv/%s>.", name, user.Login)
+}
+
+func sinkList(s *discordgo.Session, i *discordgo.InteractionCreate, g *discordgo.Guild) {
+	list := []*mongo.Sink{}
+	cur, err := mongo.Database.Collection("sinks").Find(context.Background(), bson.M{"guild_id": g.ID})
+	if err == nil {
+		err = cur.All(context.Background(), &list)
+	}
+	if err != nil {
+		log.WithError(err).Error("mongo")
+		respond(s, i, "Internal server error. Please try again later.", true)
+		return
+	}
+
+	ids := []string{}
+	for _, v := range list {
+		ids = append(ids, v.StreamerID)
+	}
+
+	users, err := resolveUsers(ids, nil)
+	if err != nil {
+		log.WithError(err).Error("api")
+		respond(s, i, "Internal server error. Please try again later.", true)
+		return
+	}
+
+	logins := map[string]string{}
+	for _, u := range users {
+		logins[u.ID] = u.Login
+	}
+
+	lines := []string{}
+	lang := guildLanguage(g.ID)
+	for _, v := range list {
+		mode := tr(lang, "minimal")
+		if v.Mode == mongo.ModeEmbed {
+			mode = tr(lang, "rich")
+		}
+		target := tr(lang, "Slack webhook")
+		if v.Type == mongo.SinkMatrix {
+			target = tr(lang, "Matrix room `%s`", v.RoomID)
+		}
+		login, ok := logins[v.StreamerID]
+		if !ok {
+			login = v.StreamerID
+		}
+		lines = append(lines, fmt.Sprintf("<https://twitch.tv/%s> -> %s - %s", login, target, mode))
+	}
+
+	if len(lines) == 0 {
+		lines = append(lines, tr(lang, "No outputs were found"))
+	}
+
+	respond(s, i, strings.Join(lines, "\n"), true)
+}
+
+// sendSinks delivers the entry to every slack and matrix output of the broadcaster.
+func (b *Bot) sendSinks(event *mongo.Event, entry *sinks.Entry) {
+	list := []*mongo.Sink{}
+	cur, err := mongo.Database.Collection("sinks").Find(context.Background(), bson.M{"streamer_id": event.BroadcasterID})
+	if err == nil {
+		err = cur.All(context.Background(), &list)
+	}
+	if err != nil {
+		log.WithError(err).Error("mongo")
+		return
+	}
+
+	for _, v := range list {
+		if isIgnored(v.GuildID, "", event) {
+			continue
+		}
+		sink, err := sinks.New(v)
+		if err != nil {
+			log.WithError(err).WithField("guild_id", v.GuildID).WithField("streamer_id", v.StreamerID).WithField("type", v.Type).Error("sinks")
+			continue
+		}
+		if err := sink.Send(context.Background(), entry); err != nil {
+			log.WithError(err).WithField("guild_id", v.GuildID).WithField("streamer_id", v.StreamerID).Error("sinks")
+		}
+	}
+}
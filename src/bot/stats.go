@@ -0,0 +1,300 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	log "github.com/sirupsen/logrus"
+	"github.com/troydota/modlogs/src/mongo"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+var statsCommand = &discordgo.ApplicationCommand{
+	Name:        "stats",
+	Description: "Shows moderator activity statistics for the hooked broadcasters.",
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "broadcaster",
+			Description: "The ID or name of the twitch streamer, defaults to every hooked streamer.",
+			Required:    false,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "moderator",
+			Description: "The ID or name of a twitch moderator.",
+			Required:    false,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "period",
+			Description: "The period to compute the statistics over.",
+			Required:    false,
+			Choices: []*discordgo.ApplicationCommandOptionChoice{
+				{Name: "day", Value: "day"},
+				{Name: "week", Value: "week"},
+				{Name: "month", Value: "month"},
+				{Name: "all", Value: "all"},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionBoolean,
+			Name:        "chart",
+			Description: "Attach a chart of the actions per moderator.",
+			Required:    false,
+		},
+	},
+}
+
+type modStats struct {
+	modCounts
+	timeoutTotal time.Duration
+}
+
+func (m *modStats) averageTimeout() time.Duration {
+	if m.timeouts == 0 {
+		return 0
+	}
+	return m.timeoutTotal / time.Duration(m.timeouts)
+}
+
+func (m *modStats) unbanRatio() float64 {
+	if m.bans+m.timeouts == 0 {
+		return 0
+	}
+	return float64(m.unbans) / float64(m.bans+m.timeouts)
+}
+
+// formatDuration prints durations the way twitch shows timeouts, such as 1h 5m 3s.
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	if d < time.Second {
+		return "0s"
+	}
+	parts := []string{}
+	if days := d / (24 * time.Hour); days > 0 {
+		parts = append(parts, fmt.Sprintf("%dd", days))
+		d -= days * 24 * time.Hour
+	}
+	if hours := d / time.Hour; hours > 0 {
+		parts = append(parts, fmt.Sprintf("%dh", hours))
+		d -= hours * time.Hour
+	}
+	if minutes := d / time.Minute; minutes > 0 {
+		parts = append(parts, fmt.Sprintf("%dm", minutes))
+		d -= minutes * time.Minute
+	}
+	if d > 0 {
+		parts = append(parts, fmt.Sprintf("%ds", d/time.Second))
+	}
+	return strings.Join(parts, " ")
+}
+
+func statsPeriod(period string) (time.Duration, string) {
+	switch period {
+	case "day":
+		return 24 * time.Hour, "the last day"
+	case "month":
+		return 30 * 24 * time.Hour, "the last 30 days"
+	case "all":
+		return 0, "all time"
+	}
+	return 7 * 24 * time.Hour, "the last 7 days"
+}
+
+func statsHandler(s *discordgo.Session, i *discordgo.InteractionCreate, g *discordgo.Guild) {
+	var broadcaster string
+	var moderator string
+	var period string
+	var chart bool
+
+	for _, o := range i.Data.Options {
+		switch o.Name {
+		case "broadcaster":
+			broadcaster = strings.ToLower(o.StringValue())
+		case "moderator":
+			moderator = strings.ToLower(o.StringValue())
+		case "period":
+			period = o.StringValue()
+		case "chart":
+			chart = o.BoolValue()
+		}
+	}
+
+	hooks := []*mongo.Hook{}
+	cur, err := mongo.Database.Collection("hooks").Find(context.Background(), bson.M{"guild_id": g.ID})
+	if err == nil {
+		err = cur.All(context.Background(), &hooks)
+	}
+	if err != nil {
+		log.WithError(err).Error("mongo")
+		respond(s, i, "Internal server error. Please try again later.", true)
+		return
+	}
+
+	streamerIDs := []string{}
+	seen := map[string]bool{}
+	for _, h := range hooks {
+		if !seen[h.StreamerID] {
+			seen[h.StreamerID] = true
+			streamerIDs = append(streamerIDs, h.StreamerID)
+		}
+	}
+
+	lang := guildLanguage(g.ID)
+
+	target := tr(lang, "all hooked channels")
+	if broadcaster != "" {
+		user, err := hookedBroadcaster(g.ID, broadcaster)
+		if err != nil {
+			if err == errNotHooked {
+				respond(s, i, "That broadcaster is not hooked in this discord.", true)
+				return
+			}
+			log.WithError(err).Error("mongo")
+			respond(s, i, "Internal server error. Please try again later.", true)
+			return
+		}
+		streamerIDs = []string{user.ID}
+		target = fmt.Sprintf("#%s", user.Login)
+	}
+
+	if len(streamerIDs) == 0 {
+		respond(s, i, "There are no hooks in this discord.", true)
+		return
+	}
+
+	filter := bson.M{
+		"broadcaster_id": bson.M{
+			"$in": streamerIDs,
+		},
+		"action": bson.M{
+			"$in": bson.A{"channel.ban", "channel.unban"},
+		},
+	}
+
+	duration, periodName := statsPeriod(period)
+	if duration != 0 {
+		filter["created_at"] = bson.M{
+			"$gte": time.Now().Add(-duration),
+		}
+	}
+
+	if moderator != "" {
+		filter["$or"] = bson.A{
+			bson.M{"moderator_id": moderator},
+			bson.M{"moderator_user_name": moderator},
+		}
+	}
+
+	events, err := findEvents(filter)
+	if err != nil {
+		log.WithError(err).Error("mongo")
+		respond(s, i, "Internal server error. Please try again later.", true)
+		return
+	}
+
+	mods := map[string]*modStats{}
+	for _, e := range events {
+		name := e.ModeratorUserName
+		if name == "" {
+			name = e.BroadcasterUserName
+		}
+		m, ok := mods[name]
+		if !ok {
+			m = &modStats{modCounts: modCounts{name: name}}
+			mods[name] = m
+		}
+		switch eventKind(e) {
+		case kindBan:
+			m.bans++
+		case kindTimeout:
+			m.timeouts++
+			m.timeoutTotal += e.Expires.Sub(e.CreatedAt)
+		case kindUnban:
+			m.unbans++
+		}
+	}
+
+	list := []*modStats{}
+	for _, m := range mods {
+		list = append(list, m)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].total() == list[j].total() {
+			return list[i].name < list[j].name
+		}
+		return list[i].total() > list[j].total()
+	})
+	// Embeds can only hold 25 fields.
+	if len(list) > 25 {
+		list = list[:25]
+	}
+
+	fields := []*discordgo.MessageEmbedField{}
+	chartValues := [][]int{}
+	for n, m := range list {
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name:   fmt.Sprintf("%v. %s", n+1, m.name),
+			Value:  tr(lang, "Bans: %v\nTimeouts: %v (avg %s)\nUnbans: %v\nUnban ratio: %.0f%%", m.bans, m.timeouts, formatDuration(m.averageTimeout()), m.unbans, m.unbanRatio()*100),
+			Inline: true,
+		})
+		chartValues = append(chartValues, []int{m.bans, m.timeouts, m.unbans})
+	}
+
+	description := tr(lang, "Moderation actions on %s over %s.", target, tr(lang, periodName))
+	if len(fields) == 0 {
+		description = fmt.Sprintf("%s\n%s", description, tr(lang, "No moderation activity."))
+	}
+
+	embed := localizeEmbed(&discordgo.MessageEmbed{
+		Title:       "Moderator Statistics",
+		Description: description,
+		Color:       3447003,
+		Timestamp:   time.Now().Format(time.RFC3339),
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "KomodoHype",
+		},
+		Fields: fields,
+	}, lang)
+
+	if !chart || len(fields) == 0 {
+		err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionApplicationCommandResponseData{
+				Embeds: []*discordgo.MessageEmbed{embed},
+			},
+		})
+		if err != nil {
+			log.WithError(err).Error("discord")
+		}
+		return
+	}
+
+	if err := deferResponse(s, i, false); err != nil {
+		log.WithError(err).Error("discord")
+		return
+	}
+
+	img, err := stackedBarChart(chartValues)
+	if err != nil {
+		log.WithError(err).Error("chart")
+	} else {
+		embed.Description = fmt.Sprintf("%s\n%s", description, tr(lang, "The chart follows the order above, bans in red, timeouts in orange and unbans in green."))
+		embed.Image = &discordgo.MessageEmbedImage{URL: "attachment://stats.png"}
+	}
+
+	files := []*discordgo.File{}
+	if img != nil {
+		files = append(files, &discordgo.File{Name: "stats.png", ContentType: "image/png", Reader: bytes.NewReader(img)})
+	}
+
+	if err := followupWithFiles(s, i, &discordgo.WebhookParams{Embeds: []*discordgo.MessageEmbed{embed}}, files...); err != nil {
+		log.WithError(err).Error("discord")
+	}
+}
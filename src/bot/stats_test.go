@@ -0,0 +1,45 @@
+package bot
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "0s"},
+		{400 * time.Millisecond, "0s"},
+		{600 * time.Millisecond, "1s"},
+		{45 * time.Second, "45s"},
+		{10 * time.Minute, "10m"},
+		{time.Hour + 5*time.Minute + 3*time.Second, "1h 5m 3s"},
+		{24 * time.Hour, "1d"},
+		{14*24*time.Hour + 30*time.Second, "14d 30s"},
+	}
+
+	for _, tt := range tests {
+		if got := formatDuration(tt.d); got != tt.want {
+			t.Errorf("formatDuration(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestUnbanRatio(t *testing.T) {
+	tests := []struct {
+		stats modStats
+		want  float64
+	}{
+		{modStats{}, 0},
+		{modStats{modCounts: modCounts{bans: 3, timeouts: 1, unbans: 1}}, 0.25},
+		{modStats{modCounts: modCounts{unbans: 2}}, 0},
+	}
+
+	for _, tt := range tests {
+		if got := tt.stats.unbanRatio(); got != tt.want {
+			t.Errorf("unbanRatio(%+v) = %v, want %v", tt.stats, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,280 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	log "github.com/sirupsen/logrus"
+	"github.com/troydota/modlogs/src/api"
+	"github.com/troydota/modlogs/src/mongo"
+	"github.com/troydota/modlogs/src/redis"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// How long an action taken from discord waits for its eventsub notification.
+const discordActionExpiry = 2 * time.Minute
+
+var errNotHooked = fmt.Errorf("broadcaster is not hooked")
+
+var twitchCommand = &discordgo.ApplicationCommand{
+	Name:        "twitch",
+	Description: "Ban, timeout or unban a user on twitch.",
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "ban",
+			Description: "Bans a user from the channel of a hooked broadcaster.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "broadcaster",
+					Description: "The ID or name of the twitch streamer.",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "user",
+					Description: "The id or username of the twitch account.",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "reason",
+					Description: "The reason of the ban.",
+					Required:    false,
+				},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "timeout",
+			Description: "Times out a user in the channel of a hooked broadcaster.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "broadcaster",
+					Description: "The ID or name of the twitch streamer.",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "user",
+					Description: "The id or username of the twitch account.",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "duration",
+					Description: "How long the timeout lasts, such as 10m or 2h.",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "reason",
+					Description: "The reason of the timeout.",
+					Required:    false,
+				},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "unban",
+			Description: "Lifts the ban or timeout of a user in the channel of a hooked broadcaster.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "broadcaster",
+					Description: "The ID or name of the twitch streamer.",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "user",
+					Description: "The id or username of the twitch account.",
+					Required:    true,
+				},
+			},
+		},
+	},
+}
+
+// discordAction is who issued a twitch action from discord, kept until the eventsub notification of the action arrives.
+type discordAction struct {
+	UserID  string `json:"user_id"`
+	GuildID string `json:"guild_id"`
+}
+
+func discordActionKey(broadcasterID string, userID string, kind string) string {
+	return fmt.Sprintf("temp:actions:%s:%s:%s", broadcasterID, userID, kind)
+}
+
+// moderate runs the action on twitch as an account which authorized a hook of the broadcaster in the guild, and
+// remembers the discord member who issued it.
+// A zero duration bans the user, kindUnban lifts a ban or a timeout.
+func moderate(ctx context.Context, guildID string, memberID string, broadcasterID string, userID string, kind string, duration time.Duration, reason string) error {
+	token, moderatorID, err := moderationToken(ctx, guildID, broadcasterID)
+	if err != nil {
+		return err
+	}
+
+	// Twitch can send the notification before it answers, so the issuer is stored first.
+	key := discordActionKey(broadcasterID, userID, kind)
+	data, _ := json.Marshal(discordAction{UserID: memberID, GuildID: guildID})
+	if err := redis.Client.Set(ctx, key, data, discordActionExpiry).Err(); err != nil {
+		log.WithError(err).Error("redis")
+	}
+
+	if kind == kindUnban {
+		err = api.UnbanUser(ctx, token, broadcasterID, moderatorID, userID)
+	} else {
+		err = api.BanUser(ctx, token, broadcasterID, moderatorID, userID, duration, reason)
+	}
+	if err != nil {
+		if err := redis.Client.Del(ctx, key).Err(); err != nil {
+			log.WithError(err).Error("redis")
+		}
+	}
+
+	return err
+}
+
+// isNotBannedError is true when twitch refused an unban because the user isn't banned.
+func isNotBannedError(err error) bool {
+	twitchErr, ok := err.(*api.TwitchError)
+	return ok && twitchErr.Status == 400 && strings.Contains(strings.ToLower(twitchErr.Message), "not banned")
+}
+
+// claimDiscordAction sets who issued the event from discord, when it was.
+func claimDiscordAction(e *mongo.Event) {
+	kind := eventKind(e)
+	if kind == "" || e.UserID == "" {
+		return
+	}
+
+	key := discordActionKey(e.BroadcasterID, e.UserID, kind)
+	data, err := redis.Client.Get(context.Background(), key).Bytes()
+	if err != nil {
+		if err != redis.ErrNil {
+			log.WithError(err).Error("redis")
+		}
+		return
+	}
+
+	action := discordAction{}
+	if err := json.Unmarshal(data, &action); err != nil {
+		log.WithError(err).Error("redis")
+		return
+	}
+
+	if err := redis.Client.Del(context.Background(), key).Err(); err != nil {
+		log.WithError(err).Error("redis")
+	}
+
+	e.IssuedBy = action.UserID
+	e.IssuedGuildID = action.GuildID
+}
+
+// hookedBroadcaster returns the broadcaster matching the id or login, when it is hooked in the guild.
+func hookedBroadcaster(guildID string, input string) (*mongo.User, error) {
+	user, err := lookupUser(input)
+	if err != nil {
+		if err == errUnknownUser {
+			return nil, errNotHooked
+		}
+		return nil, err
+	}
+
+	count, err := mongo.Database.Collection("hooks").CountDocuments(context.Background(), bson.M{
+		"guild_id":    guildID,
+		"streamer_id": user.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, errNotHooked
+	}
+
+	return user, nil
+}
+
+func twitchHandler(s *discordgo.Session, i *discordgo.InteractionCreate, g *discordgo.Guild) {
+	if len(i.Data.Options) == 0 {
+		respond(s, i, "Please select a sub command.", true)
+		return
+	}
+
+	sub := i.Data.Options[0]
+
+	var broadcasterInput string
+	var userInput string
+	var durationInput string
+	var reason string
+	for _, o := range sub.Options {
+		switch o.Name {
+		case "broadcaster":
+			broadcasterInput = o.StringValue()
+		case "user":
+			userInput = o.StringValue()
+		case "duration":
+			durationInput = strings.TrimSpace(o.StringValue())
+		case "reason":
+			reason = o.StringValue()
+		}
+	}
+
+	kind := kindBan
+	var duration time.Duration
+	switch sub.Name {
+	case "timeout":
+		kind = kindTimeout
+		d, err := time.ParseDuration(durationInput)
+		if err != nil || d < time.Second || d > maxTimeout {
+			respond(s, i, "Invalid duration, use a value such as 10m or 2h, up to two weeks.", true)
+			return
+		}
+		duration = d
+	case "unban":
+		kind = kindUnban
+	}
+
+	broadcaster, err := hookedBroadcaster(g.ID, broadcasterInput)
+	if err != nil {
+		if err == errNotHooked {
+			respond(s, i, "That broadcaster is not hooked in this discord.", true)
+			return
+		}
+		log.WithError(err).Error("mongo")
+		respond(s, i, "Internal server error. Please try again later.", true)
+		return
+	}
+
+	user, err := lookupUser(userInput)
+	if err != nil {
+		if err == errUnknownUser {
+			respond(s, i, "The specified user does not exist.", true)
+			return
+		}
+		log.WithError(err).Error("mongo")
+		respond(s, i, "Internal server error. Please try again later.", true)
+		return
+	}
+
+	if err := moderate(context.Background(), g.ID, i.Member.User.ID, broadcaster.ID, user.ID, kind, duration, reason); err != nil {
+		respond(s, i, moderationErrorMessage(err), true)
+		return
+	}
+
+	name := strings.ReplaceAll(user.Login, "`", "")
+	switch kind {
+	case kindBan:
+		respondf(s, i, true, "Banned `%s` from <https://twitch.tv/%s>.", name, broadcaster.Login)
+	case kindTimeout:
+		respondf(s, i, true, "Timed out `%s` in <https://twitch.tv/%s> for %s.", name, broadcaster.Login, formatDuration(duration))
+	default:
+		respondf(s, i, true, "Unbanned `%s` from <https://twitch.tv/%s>.", name, broadcaster.Login)
+	}
+}
@@ -0,0 +1,330 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	log "github.com/sirupsen/logrus"
+	"github.com/troydota/modlogs/src/api"
+	"github.com/troydota/modlogs/src/mongo"
+	"github.com/troydota/modlogs/src/redis"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	unbanRequestCreate  = "channel.unban_request.create"
+	unbanRequestResolve = "channel.unban_request.resolve"
+)
+
+var errUnknownUnbanRequest = fmt.Errorf("unknown unban request")
+
+type UnbanRequestEvent struct {
+	Action  string
+	Request *mongo.UnbanRequest
+}
+
+// UnbanRequests receives the unban requests created and resolved on twitch.
+var UnbanRequests = make(chan UnbanRequestEvent)
+
+func init() {
+	componentHandlers["unbanrequest"] = componentRoute{permissionManage, unbanRequestButtonHandler}
+	componentHandlers["unbanrequestreply"] = componentRoute{permissionManage, unbanRequestReplyHandler}
+}
+
+// subscribeUnbanRequests subscribes to the unban requests of the streamer when it wasn't done yet,
+// twitch only sends them to a moderator who allowed it, so failing is not an error for the hook.
+func subscribeUnbanRequests(streamerID string, moderatorID string) {
+	id, err := redis.Client.HGet(context.Background(), fmt.Sprintf("webhook:twitch:%s:%s", unbanRequestCreate, streamerID), "id").Result()
+	if err != nil && err != redis.ErrNil {
+		log.WithError(err).Error("redis")
+		return
+	}
+	if id != "" {
+		return
+	}
+
+	if moderatorID == "" {
+		moderatorID = streamerID
+	}
+
+	if err := api.CreateUnbanRequestWebhooks(context.Background(), streamerID, moderatorID); err != nil {
+		log.WithError(err).WithField("streamer", streamerID).Warn("unban requests")
+	}
+}
+
+// accountAge describes how long ago the account was created.
+func accountAge(created *time.Time) string {
+	if created == nil {
+		return "Unknown"
+	}
+	return fmt.Sprintf("<t:%d:D> (<t:%d:R>)", created.Unix(), created.Unix())
+}
+
+func unbanRequestStatus(status string) string {
+	switch status {
+	case "approved":
+		return "Approved"
+	case "denied":
+		return "Denied"
+	}
+	return "Canceled"
+}
+
+// unbanRequestEmbed shows the request, with how it was resolved once it is.
+func unbanRequestEmbed(r *mongo.UnbanRequest, lang string) *discordgo.MessageEmbed {
+	text := r.Text
+	if text == "" {
+		text = "None Provided"
+	}
+
+	fields := []*discordgo.MessageEmbedField{
+		{Name: "Broadcaster", Value: r.BroadcasterUserName, Inline: true},
+		{Name: "User", Value: r.UserName, Inline: true},
+		{Name: "Account Created", Value: accountAge(r.AccountCreatedAt), Inline: true},
+		{Name: "Request", Value: text},
+	}
+
+	title := "Unban Request"
+	color := 16312092
+	if r.Status != "pending" {
+		title = fmt.Sprintf("Unban Request %s", unbanRequestStatus(r.Status))
+		switch r.Status {
+		case "approved":
+			color = 8311585
+		case "denied":
+			color = 13632027
+		default:
+			color = 9807270
+		}
+
+		moderator := r.ModeratorUserName
+		if r.IssuedBy != "" {
+			moderator = tr(lang, "%s (issued from discord by <@%s>)", moderator, r.IssuedBy)
+		}
+		if moderator != "" {
+			fields = append(fields, &discordgo.MessageEmbedField{Name: "Resolved By", Value: moderator})
+		}
+		if r.Resolution != "" {
+			fields = append(fields, &discordgo.MessageEmbedField{Name: "Resolution", Value: r.Resolution})
+		}
+	}
+
+	return localizeEmbed(&discordgo.MessageEmbed{
+		Title:       title,
+		Description: "_ _",
+		Color:       color,
+		Timestamp:   r.CreatedAt.Format(time.RFC3339),
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "KomodoHype",
+		},
+		Fields: fields,
+	}, lang)
+}
+
+func unbanRequestButtons(r *mongo.UnbanRequest) []*component {
+	return []*component{actionRow(
+		&component{Type: componentButton, Style: buttonSuccess, Label: "Approve", CustomID: fmt.Sprintf("unbanrequest:approve:%s", r.ID)},
+		&component{Type: componentButton, Style: buttonDanger, Label: "Deny", CustomID: fmt.Sprintf("unbanrequest:deny:%s", r.ID)},
+	)}
+}
+
+func (b *Bot) processUnbanRequest(ev UnbanRequestEvent) {
+	if ev.Action == unbanRequestResolve {
+		b.resolveUnbanRequest(ev.Request)
+		return
+	}
+
+	r := ev.Request
+	if users, err := resolveUsers([]string{r.UserID}, nil); err != nil {
+		log.WithError(err).Error("api")
+	} else if u, ok := users[r.UserID]; ok {
+		r.AccountCreatedAt = u.CreatedAt
+	}
+
+	res, err := mongo.Database.Collection("unbanrequests").UpdateOne(context.Background(), bson.M{"id": r.ID}, bson.M{
+		"$setOnInsert": r,
+	}, options.Update().SetUpsert(true))
+	if err != nil {
+		log.WithError(err).Error("mongo")
+		return
+	}
+	if res.UpsertedCount == 0 {
+		// Twitch sent the request again.
+		return
+	}
+
+	hooks := []*mongo.Hook{}
+	cur, err := mongo.Database.Collection("hooks").Find(context.Background(), bson.M{"streamer_id": r.BroadcasterID})
+	if err == nil {
+		err = cur.All(context.Background(), &hooks)
+	}
+	if err != nil {
+		log.WithError(err).Error("mongo")
+		return
+	}
+
+	event := &mongo.Event{BroadcasterID: r.BroadcasterID, UserID: r.UserID, Action: unbanRequestCreate}
+
+	for _, hook := range hooks {
+		if isIgnored(hook.GuildID, hook.ChannelID, event) {
+			continue
+		}
+
+		lang := guildLanguage(hook.GuildID)
+		var msg *discordgo.Message
+		if hook.Mode == mongo.ModeEmbed {
+			msg, err = b.sendEmbed(hook, unbanRequestEmbed(r, lang), localizeComponents(unbanRequestButtons(r), lang)...)
+		} else {
+			msg, err = b.sendMessage(hook, tr(lang, "**Unban Request: #%s** - `%s` (created %s): %s", r.BroadcasterUserName, strings.ReplaceAll(r.UserName, "`", ""), tr(lang, accountAge(r.AccountCreatedAt)), codeSpan(r.Text)))
+		}
+		if err != nil {
+			log.WithError(err).WithField("hook", hook).Error("discord")
+			continue
+		}
+		storeLogMessage(hook, r.ID, msg)
+	}
+}
+
+// resolveUnbanRequest updates the messages of the request once it is resolved on twitch.
+func (b *Bot) resolveUnbanRequest(resolved *mongo.UnbanRequest) {
+	now := time.Now()
+	r := &mongo.UnbanRequest{}
+	err := mongo.Database.Collection("unbanrequests").FindOneAndUpdate(context.Background(), bson.M{"id": resolved.ID}, bson.M{
+		"$set": bson.M{
+			"status":              resolved.Status,
+			"moderator_user_name": resolved.ModeratorUserName,
+			"resolution":          resolved.Resolution,
+			"resolved_at":         now,
+		},
+	}, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(r)
+	if err == mongo.ErrNoDocuments {
+		return
+	}
+	if err != nil {
+		log.WithError(err).Error("mongo")
+		return
+	}
+
+	messages, err := findLogMessages(bson.M{"event_id": r.ID})
+	if err != nil {
+		log.WithError(err).Error("mongo")
+		return
+	}
+
+	for _, m := range messages {
+		hook, err := logMessageHook(m)
+		if err != nil {
+			log.WithError(err).WithField("message", m).Error("mongo")
+			continue
+		}
+		if hook == nil {
+			continue
+		}
+		lang := guildLanguage(hook.GuildID)
+		if m.Mode == mongo.ModeEmbed {
+			err = editLogMessage(b.conn, hook, m, unbanRequestEmbed(r, lang), []*component{})
+		} else {
+			moderator := r.ModeratorUserName
+			if moderator == "" {
+				moderator = tr(lang, "the user")
+			}
+			err = appendLogMessage(b.conn, hook, m, tr(lang, "↳ %s by `%s` at %s", tr(lang, unbanRequestStatus(r.Status)), moderator, discordTime(now)))
+		}
+		if err != nil {
+			log.WithError(err).WithField("message", m).Error("discord")
+		}
+	}
+}
+
+// guildUnbanRequest returns the pending request, when its broadcaster is hooked in the guild.
+func guildUnbanRequest(guildID string, id string) (*mongo.UnbanRequest, error) {
+	r := &mongo.UnbanRequest{}
+	if err := mongo.Database.Collection("unbanrequests").FindOne(context.Background(), bson.M{"id": id}).Decode(r); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errUnknownUnbanRequest
+		}
+		return nil, err
+	}
+
+	count, err := mongo.Database.Collection("hooks").CountDocuments(context.Background(), bson.M{
+		"guild_id":    guildID,
+		"streamer_id": r.BroadcasterID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, errUnknownUnbanRequest
+	}
+
+	return r, nil
+}
+
+func unbanRequestButtonHandler(s *discordgo.Session, i *discordgo.InteractionCreate, c *componentInteraction, g *discordgo.Guild, arg string) {
+	title := "Approve the unban request"
+	if strings.HasPrefix(arg, "deny:") {
+		title = "Deny the unban request"
+	}
+
+	lang := guildLanguage(i.GuildID)
+	if err := showModal(s, i, fmt.Sprintf("unbanrequestreply:%s", arg), tr(lang, title), tr(lang, "Resolution shown to the user")); err != nil {
+		log.WithError(err).Error("discord")
+	}
+}
+
+func unbanRequestReplyHandler(s *discordgo.Session, i *discordgo.InteractionCreate, c *componentInteraction, g *discordgo.Guild, arg string) {
+	parts := strings.SplitN(arg, ":", 2)
+	if len(parts) != 2 || (parts[0] != "approve" && parts[0] != "deny") {
+		respond(s, i, "Invalid unban request.", true)
+		return
+	}
+
+	r, err := guildUnbanRequest(g.ID, parts[1])
+	if err != nil {
+		if err == errUnknownUnbanRequest {
+			respond(s, i, "That unban request doesn't belong to a broadcaster hooked in this discord.", true)
+			return
+		}
+		log.WithError(err).Error("mongo")
+		respond(s, i, "Internal server error. Please try again later.", true)
+		return
+	}
+	if r.Status != "pending" {
+		respond(s, i, "That unban request was already resolved.", true)
+		return
+	}
+
+	token, moderatorID, err := moderationToken(context.Background(), g.ID, r.BroadcasterID)
+	if err != nil {
+		respond(s, i, moderationErrorMessage(err), true)
+		return
+	}
+
+	// Twitch can send the resolution before it answers, so the issuer is stored first.
+	if _, err := mongo.Database.Collection("unbanrequests").UpdateOne(context.Background(), bson.M{"id": r.ID}, bson.M{
+		"$set": bson.M{"issued_by": i.Member.User.ID},
+	}); err != nil {
+		log.WithError(err).Error("mongo")
+	}
+
+	status := "denied"
+	if parts[0] == "approve" {
+		status = "approved"
+	}
+
+	if err := api.ResolveUnbanRequest(context.Background(), token, r.BroadcasterID, moderatorID, r.ID, status, strings.TrimSpace(c.value("text"))); err != nil {
+		if _, err := mongo.Database.Collection("unbanrequests").UpdateOne(context.Background(), bson.M{"id": r.ID}, bson.M{
+			"$unset": bson.M{"issued_by": ""},
+		}); err != nil {
+			log.WithError(err).Error("mongo")
+		}
+		respond(s, i, moderationErrorMessage(err), true)
+		return
+	}
+
+	respondf(s, i, true, "The unban request of `%s` was %s.", strings.ReplaceAll(r.UserName, "`", ""), status)
+}
@@ -0,0 +1,359 @@
+package bot
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/troydota/modlogs/src/api"
+	"github.com/troydota/modlogs/src/mongo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// Resolved users older than this are fetched again, so renames are picked up.
+	userExpiry = 24 * time.Hour
+	// How many users are kept in memory.
+	userCacheSize = 5000
+	// How often the hooked broadcasters are refreshed in the background.
+	userRefreshInterval = time.Hour
+)
+
+var errUnknownUser = fmt.Errorf("unknown twitch user")
+
+// userCache is a least recently used cache of the resolved users, it is indexed by id and by login.
+type userCache struct {
+	mtx     sync.Mutex
+	size    int
+	order   *list.List
+	byID    map[string]*list.Element
+	byLogin map[string]string
+}
+
+func newUserCache(size int) *userCache {
+	return &userCache{
+		size:    size,
+		order:   list.New(),
+		byID:    map[string]*list.Element{},
+		byLogin: map[string]string{},
+	}
+}
+
+var resolvedUsers = newUserCache(userCacheSize)
+
+func (c *userCache) get(id string) *mongo.User {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	el, ok := c.byID[id]
+	if !ok {
+		return nil
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*mongo.User)
+}
+
+func (c *userCache) getLogin(login string) *mongo.User {
+	c.mtx.Lock()
+	id, ok := c.byLogin[login]
+	c.mtx.Unlock()
+	if !ok {
+		return nil
+	}
+	return c.get(id)
+}
+
+func (c *userCache) add(u *mongo.User) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if el, ok := c.byID[u.ID]; ok {
+		if old := el.Value.(*mongo.User); c.byLogin[old.Login] == u.ID {
+			delete(c.byLogin, old.Login)
+		}
+		el.Value = u
+		c.order.MoveToFront(el)
+	} else {
+		c.byID[u.ID] = c.order.PushFront(u)
+	}
+	c.byLogin[u.Login] = u.ID
+
+	for c.order.Len() > c.size {
+		el := c.order.Back()
+		old := el.Value.(*mongo.User)
+		c.order.Remove(el)
+		delete(c.byID, old.ID)
+		if c.byLogin[old.Login] == old.ID {
+			delete(c.byLogin, old.Login)
+		}
+	}
+}
+
+func (c *userCache) remove(id string) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	el, ok := c.byID[id]
+	if !ok {
+		return
+	}
+	old := el.Value.(*mongo.User)
+	c.order.Remove(el)
+	delete(c.byID, id)
+	if c.byLogin[old.Login] == id {
+		delete(c.byLogin, old.Login)
+	}
+}
+
+// freshUser is true when the user was fetched from twitch recently enough to be trusted.
+func freshUser(u *mongo.User) bool {
+	return u != nil && u.UpdatedAt != nil && time.Since(*u.UpdatedAt) < userExpiry
+}
+
+// recordLogin adds a login to the history of the user.
+func recordLogin(ctx context.Context, userID string, login string, name string) error {
+	_, err := mongo.Database.Collection("userlogins").UpdateOne(ctx, bson.M{
+		"user_id": userID,
+		"login":   login,
+	}, bson.M{
+		"$set": bson.M{
+			"name":       name,
+			"changed_at": time.Now(),
+		},
+	}, options.Update().SetUpsert(true))
+	return err
+}
+
+// StoreUser saves the user, recording the previous login when the user was renamed.
+// Users without UpdatedAt only update the names and are fetched again on the next lookup.
+func StoreUser(ctx context.Context, u *mongo.User) error {
+	resolvedUsers.remove(u.ID)
+
+	// Logins are unique, when another user held this one they were renamed too.
+	// Their login is replaced by their id until they are fetched again.
+	holder := &mongo.User{}
+	err := mongo.Database.Collection("users").FindOne(ctx, bson.M{
+		"login": u.Login,
+		"id":    bson.M{"$ne": u.ID},
+	}).Decode(holder)
+	if err == nil {
+		resolvedUsers.remove(holder.ID)
+		if _, err := mongo.Database.Collection("users").UpdateOne(ctx, bson.M{"id": holder.ID}, bson.M{
+			"$set":   bson.M{"login": "#" + holder.ID},
+			"$unset": bson.M{"updated_at": ""},
+		}); err != nil {
+			return err
+		}
+		if err := recordLogin(ctx, holder.ID, holder.Login, holder.Name); err != nil {
+			return err
+		}
+	} else if err != mongo.ErrNoDocuments {
+		return err
+	}
+
+	previous := &mongo.User{}
+	err = mongo.Database.Collection("users").FindOneAndUpdate(ctx, bson.M{"id": u.ID}, bson.M{
+		"$set": u,
+	}, options.FindOneAndUpdate().SetUpsert(true)).Decode(previous)
+	if err == mongo.ErrNoDocuments {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if previous.Login != u.Login && !strings.HasPrefix(previous.Login, "#") {
+		log.WithField("id", u.ID).WithField("from", previous.Login).WithField("to", u.Login).Info("twitch rename")
+		return recordLogin(ctx, u.ID, previous.Login, previous.Name)
+	}
+
+	return nil
+}
+
+// resolveUsers returns the twitch users matching the ids and logins, keyed by id.
+// Users are read from memory, then mongo, the missing or stale ones are fetched from twitch in one batch.
+// When twitch can't be reached the stale users are returned with the error.
+func resolveUsers(ids []string, logins []string) (map[string]*mongo.User, error) {
+	ctx := context.Background()
+	resolved := map[string]*mongo.User{}
+
+	pendingIDs := []string{}
+	seen := map[string]bool{}
+	for _, id := range ids {
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		if u := resolvedUsers.get(id); freshUser(u) {
+			resolved[u.ID] = u
+		} else {
+			pendingIDs = append(pendingIDs, id)
+		}
+	}
+
+	pendingLogins := []string{}
+	for _, login := range logins {
+		login = strings.ToLower(login)
+		if login == "" || seen["login:"+login] {
+			continue
+		}
+		seen["login:"+login] = true
+		if u := resolvedUsers.getLogin(login); freshUser(u) {
+			resolved[u.ID] = u
+		} else {
+			pendingLogins = append(pendingLogins, login)
+		}
+	}
+
+	if len(pendingIDs) == 0 && len(pendingLogins) == 0 {
+		return resolved, nil
+	}
+
+	stored := []*mongo.User{}
+	cur, err := mongo.Database.Collection("users").Find(ctx, bson.M{
+		"$or": bson.A{
+			bson.M{"id": bson.M{"$in": pendingIDs}},
+			bson.M{"login": bson.M{"$in": pendingLogins}},
+		},
+	})
+	if err == nil {
+		err = cur.All(ctx, &stored)
+	}
+	if err != nil {
+		return resolved, err
+	}
+
+	stale := map[string]*mongo.User{}
+	for _, u := range stored {
+		if freshUser(u) {
+			resolved[u.ID] = u
+			resolvedUsers.add(u)
+		} else {
+			stale[u.ID] = u
+		}
+	}
+
+	missingIDs := []string{}
+	for _, id := range pendingIDs {
+		if _, ok := resolved[id]; !ok {
+			missingIDs = append(missingIDs, id)
+		}
+	}
+	missingLogins := []string{}
+	for _, login := range pendingLogins {
+		found := false
+		for _, u := range resolved {
+			if u.Login == login {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missingLogins = append(missingLogins, login)
+		}
+	}
+	if len(missingIDs) == 0 && len(missingLogins) == 0 {
+		return resolved, nil
+	}
+
+	users, err := api.GetUsers(ctx, "", missingIDs, missingLogins)
+	if err != nil {
+		for _, u := range stale {
+			resolved[u.ID] = u
+		}
+		return resolved, err
+	}
+
+	now := time.Now()
+	for _, v := range users {
+		created := v.CreatedAt
+		u := &mongo.User{
+			ID:              v.ID,
+			Name:            v.DisplayName,
+			Login:           v.Login,
+			ProfileImageURL: v.ProfileImageURL,
+			CreatedAt:       &created,
+			UpdatedAt:       &now,
+		}
+		if err := StoreUser(ctx, u); err != nil {
+			log.WithError(err).WithField("user", u).Error("mongo")
+		}
+		resolved[u.ID] = u
+		resolvedUsers.add(u)
+	}
+
+	// Suspended and deleted accounts are no longer returned by twitch, their last known names are kept.
+	for _, id := range missingIDs {
+		if u, ok := stale[id]; ok {
+			if _, ok := resolved[id]; !ok {
+				resolved[id] = u
+			}
+		}
+	}
+
+	return resolved, nil
+}
+
+// lookupUser finds a twitch user by id or login.
+func lookupUser(input string) (*mongo.User, error) {
+	input = strings.ToLower(strings.TrimSpace(input))
+
+	ids := []string{}
+	if _, err := strconv.ParseInt(input, 10, 64); err == nil {
+		ids = append(ids, input)
+	}
+
+	users, err := resolveUsers(ids, []string{input})
+	if u, ok := users[input]; ok {
+		return u, nil
+	}
+	for _, u := range users {
+		if u.Login == input {
+			return u, nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, errUnknownUser
+}
+
+// runUserRefresh keeps the hooked broadcasters up to date, so renames show up without a lookup.
+func (b *Bot) runUserRefresh() {
+	ticker := time.NewTicker(userRefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-b.stopped:
+			return
+		case <-ticker.C:
+			b.refreshUsers()
+		}
+	}
+}
+
+func (b *Bot) refreshUsers() {
+	values, err := mongo.Database.Collection("hooks").Distinct(context.Background(), "streamer_id", bson.M{})
+	if err != nil {
+		log.WithError(err).Error("mongo")
+		return
+	}
+
+	ids := []string{}
+	for _, v := range values {
+		if id, ok := v.(string); ok {
+			ids = append(ids, id)
+		}
+	}
+
+	if _, err := resolveUsers(ids, nil); err != nil {
+		log.WithError(err).Error("api")
+	}
+}
@@ -0,0 +1,262 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/bwmarrin/discordgo"
+	log "github.com/sirupsen/logrus"
+	"github.com/troydota/modlogs/src/mongo"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const webhookName = "ModLogs"
+
+// noMentions keeps the logs from pinging anyone, they can hold text written by twitch users.
+func noMentions() *discordgo.MessageAllowedMentions {
+	return &discordgo.MessageAllowedMentions{Parse: []discordgo.AllowedMentionType{}}
+}
+
+// sendEmbed posts an embed for the hook, either as the bot or through the channel webhook.
+func (b *Bot) sendEmbed(hook *mongo.Hook, embed *discordgo.MessageEmbed, components ...*component) (*discordgo.Message, error) {
+	if hook.Delivery == mongo.DeliveryWebhook {
+		return b.executeWebhook(hook, &discordgo.WebhookParams{
+			Embeds: []*discordgo.MessageEmbed{embed},
+		}, components...)
+	}
+	if len(components) == 0 {
+		return b.conn.ChannelMessageSendComplex(hook.ChannelID, &discordgo.MessageSend{
+			Embed:           embed,
+			AllowedMentions: noMentions(),
+		})
+	}
+	return channelMessageSendComponents(b.conn, hook.ChannelID, embed, components)
+}
+
+// sendMessage posts a plain text message for the hook, either as the bot or through the channel webhook.
+func (b *Bot) sendMessage(hook *mongo.Hook, content string) (*discordgo.Message, error) {
+	if hook.Delivery == mongo.DeliveryWebhook {
+		return b.executeWebhook(hook, &discordgo.WebhookParams{
+			Content: content,
+		})
+	}
+	return b.conn.ChannelMessageSendComplex(hook.ChannelID, &discordgo.MessageSend{
+		Content:         content,
+		AllowedMentions: noMentions(),
+	})
+}
+
+// sendFile posts a message with a file attachment for the hook, either as the bot or through the channel webhook.
+func (b *Bot) sendFile(hook *mongo.Hook, content string, file *discordgo.File) (*discordgo.Message, error) {
+	if hook.Delivery != mongo.DeliveryWebhook || hook.WebhookID == "" {
+		return b.conn.ChannelMessageSendComplex(hook.ChannelID, &discordgo.MessageSend{
+			Content:         content,
+			Files:           []*discordgo.File{file},
+			AllowedMentions: noMentions(),
+		})
+	}
+
+	// The version of discordgo we use can't upload files through webhooks.
+	contentType, body, err := multipartBody(&discordgo.WebhookParams{
+		Content:         content,
+		Username:        hook.WebhookName,
+		AvatarURL:       hook.WebhookAvatar,
+		AllowedMentions: noMentions(),
+	}, []*discordgo.File{file})
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := discordgo.EndpointWebhookToken(hook.WebhookID, hook.WebhookToken)
+	resp, err := b.conn.RequestWithLockedBucket("POST", endpoint+"?wait=true", contentType, body, b.conn.Ratelimiter.LockBucket(endpoint), 0)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := &discordgo.Message{}
+	err = json.Unmarshal(resp, msg)
+	return msg, err
+}
+
+// webhookPayload adds message components to the webhook params.
+type webhookPayload struct {
+	*discordgo.WebhookParams
+	Components []*component `json:"components,omitempty"`
+}
+
+// webhookExecute runs the webhook, webhooks made by the bot can also send components.
+func (b *Bot) webhookExecute(hook *mongo.Hook, params *discordgo.WebhookParams, components []*component) (*discordgo.Message, error) {
+	if len(components) == 0 {
+		return b.conn.WebhookExecute(hook.WebhookID, hook.WebhookToken, true, params)
+	}
+
+	endpoint := discordgo.EndpointWebhookToken(hook.WebhookID, hook.WebhookToken)
+	body, err := b.conn.RequestWithBucketID("POST", endpoint+"?wait=true", &webhookPayload{params, components}, endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := &discordgo.Message{}
+	err = json.Unmarshal(body, msg)
+	return msg, err
+}
+
+func (b *Bot) executeWebhook(hook *mongo.Hook, params *discordgo.WebhookParams, components ...*component) (*discordgo.Message, error) {
+	params.Username = hook.WebhookName
+	params.AvatarURL = hook.WebhookAvatar
+	if params.AllowedMentions == nil {
+		params.AllowedMentions = noMentions()
+	}
+
+	if hook.WebhookID == "" {
+		if err := b.recreateWebhook(hook); err != nil {
+			return nil, err
+		}
+	}
+
+	msg, err := b.webhookExecute(hook, params, components)
+	if err == nil || !isUnknownWebhook(err) {
+		return msg, err
+	}
+
+	// The webhook was deleted from the channel, so we make a new one and try again.
+	if err := b.recreateWebhook(hook); err != nil {
+		return nil, err
+	}
+
+	return b.webhookExecute(hook, params, components)
+}
+
+// recreateWebhook replaces the webhook of the hook, and every other hook sharing it.
+func (b *Bot) recreateWebhook(hook *mongo.Hook) error {
+	b.webhookMtx.Lock()
+	defer b.webhookMtx.Unlock()
+
+	filter := bson.M{
+		"guild_id":    hook.GuildID,
+		"channel_id":  hook.ChannelID,
+		"streamer_id": hook.StreamerID,
+	}
+
+	// Another event may have already replaced the webhook while we were waiting.
+	current := &mongo.Hook{}
+	res := mongo.Database.Collection("hooks").FindOne(context.Background(), filter)
+	err := res.Err()
+	if err == nil {
+		err = res.Decode(current)
+	}
+	if err != nil {
+		return err
+	}
+	if current.WebhookID != "" && current.WebhookID != hook.WebhookID {
+		hook.WebhookID = current.WebhookID
+		hook.WebhookToken = current.WebhookToken
+		return nil
+	}
+
+	wh, err := b.conn.WebhookCreate(hook.ChannelID, webhookName, "")
+	if err != nil {
+		return err
+	}
+
+	if hook.WebhookID != "" {
+		filter = bson.M{
+			"webhook_id": hook.WebhookID,
+		}
+	}
+
+	_, err = mongo.Database.Collection("hooks").UpdateMany(context.Background(), filter, bson.M{
+		"$set": bson.M{
+			"webhook_id":    wh.ID,
+			"webhook_token": wh.Token,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	hook.WebhookID = wh.ID
+	hook.WebhookToken = wh.Token
+
+	return nil
+}
+
+// channelWebhook returns a webhook for the channel, reusing the one of another hook in the channel when possible.
+func channelWebhook(s *discordgo.Session, channelID string) (string, string, error) {
+	existing := &mongo.Hook{}
+	res := mongo.Database.Collection("hooks").FindOne(context.Background(), bson.M{
+		"channel_id": channelID,
+		"webhook_id": bson.M{
+			"$exists": true,
+		},
+	})
+	err := res.Err()
+	if err == nil {
+		err = res.Decode(existing)
+	}
+	if err == nil && existing.WebhookID != "" {
+		if _, err := s.WebhookWithToken(existing.WebhookID, existing.WebhookToken); err == nil {
+			return existing.WebhookID, existing.WebhookToken, nil
+		}
+	} else if err != nil && err != mongo.ErrNoDocuments {
+		return "", "", err
+	}
+
+	wh, err := s.WebhookCreate(channelID, webhookName, "")
+	if err != nil {
+		return "", "", err
+	}
+
+	return wh.ID, wh.Token, nil
+}
+
+// releaseWebhooks deletes the discord webhooks of the hooks matching the filter which no other hook uses.
+func releaseWebhooks(s *discordgo.Session, filter bson.M) {
+	hooks := []*mongo.Hook{}
+	cur, err := mongo.Database.Collection("hooks").Find(context.Background(), filter)
+	if err == nil {
+		err = cur.All(context.Background(), &hooks)
+	}
+	if err != nil {
+		log.WithError(err).Error("mongo")
+		return
+	}
+
+	released := map[string]bool{}
+	for _, h := range hooks {
+		if h.WebhookID == "" || released[h.WebhookID] {
+			continue
+		}
+		released[h.WebhookID] = true
+		count, err := mongo.Database.Collection("hooks").CountDocuments(context.Background(), bson.M{
+			"webhook_id": h.WebhookID,
+			"$nor":       bson.A{filter},
+		})
+		if err != nil {
+			log.WithError(err).Error("mongo")
+			continue
+		}
+		if count > 0 {
+			continue
+		}
+		if _, err := s.WebhookDeleteWithToken(h.WebhookID, h.WebhookToken); err != nil && !isUnknownWebhook(err) {
+			log.WithError(err).WithField("hook", h).Error("discord")
+		}
+	}
+}
+
+func isUnknownWebhook(err error) bool {
+	restErr, ok := err.(*discordgo.RESTError)
+	if !ok {
+		return false
+	}
+	if restErr.Message != nil && restErr.Message.Code == discordgo.ErrCodeUnknownWebhook {
+		return true
+	}
+	return restErr.Response != nil && restErr.Response.StatusCode == http.StatusNotFound
+}
+
+func webhookDisplayName(streamer string) string {
+	return fmt.Sprintf("%s ModLogs", streamer)
+}
@@ -3,6 +3,7 @@ package configure
 import (
 	"bytes"
 	"encoding/json"
+	"os"
 	"strings"
 
 	"github.com/kr/pretty"
@@ -39,6 +40,9 @@ var defaultConf = ServerCfg{
 
 var Config = viper.New()
 
+// Testing is true in the test binaries, they only get the default config and don't connect to mongo or redis.
+var Testing = strings.HasSuffix(strings.TrimSuffix(os.Args[0], ".exe"), ".test")
+
 func initLog() {
 	if l, err := log.ParseLevel(Config.GetString("level")); err == nil {
 		log.SetLevel(l)
@@ -60,6 +64,9 @@ func init() {
 	viper.SetConfigType("json")
 	checkErr(viper.ReadConfig(defaultConfig))
 	checkErr(Config.MergeConfigMap(viper.AllSettings()))
+	if Testing {
+		return
+	}
 
 	// Flags
 	pflag.String("config_file", "config.yaml", "configure filename")
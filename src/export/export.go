@@ -0,0 +1,105 @@
+package export
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/troydota/modlogs/src/mongo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var json = jsoniter.ConfigCompatibleWithStandardLibrary
+
+const (
+	FormatCSV    = "csv"
+	FormatJSON   = "json"
+	FormatNDJSON = "ndjson"
+)
+
+var ErrUnknownFormat = fmt.Errorf("unknown export format")
+
+// Request describes the events to export, it is what gets stored behind an export link.
+// A guild only gets the events which were logged to it when it is set.
+type Request struct {
+	BroadcasterID string    `json:"broadcaster_id"`
+	GuildID       string    `json:"guild_id,omitempty"`
+	Format        string    `json:"format"`
+	From          time.Time `json:"from"`
+	To            time.Time `json:"to"`
+}
+
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv"
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	}
+	return "application/json"
+}
+
+func FileName(login string, r *Request) string {
+	return fmt.Sprintf("modlogs-%s-%s-%s.%s", login, r.From.UTC().Format("20060102"), r.To.UTC().Format("20060102"), r.Format)
+}
+
+// Find returns the stored events of the request, oldest first.
+func Find(ctx context.Context, r *Request) ([]*mongo.Event, error) {
+	filter := bson.M{
+		"broadcaster_id": r.BroadcasterID,
+		"created_at": bson.M{
+			"$gte": r.From,
+			"$lt":  r.To,
+		},
+	}
+	if r.GuildID != "" {
+		filter["guild_ids"] = r.GuildID
+	}
+
+	events := []*mongo.Event{}
+	cur, err := mongo.Database.Collection("events").Find(ctx, filter, options.Find().SetSort(bson.M{"created_at": 1}))
+	if err == nil {
+		err = cur.All(ctx, &events)
+	}
+	return events, err
+}
+
+var csvHeader = []string{"id", "created_at", "action", "broadcaster_id", "broadcaster_user_name", "moderator_id", "moderator_user_name", "user_id", "user_name", "reason", "expires"}
+
+func Write(w io.Writer, format string, events []*mongo.Event) error {
+	switch format {
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(csvHeader); err != nil {
+			return err
+		}
+		for _, e := range events {
+			var expires string
+			if e.Expires != nil {
+				expires = e.Expires.UTC().Format(time.RFC3339)
+			}
+			if err := cw.Write([]string{e.ID, e.CreatedAt.UTC().Format(time.RFC3339), e.Action, e.BroadcasterID, e.BroadcasterUserName, e.ModeratorID, e.ModeratorUserName, e.UserID, e.UserName, e.Reason, expires}); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(events)
+	case FormatNDJSON:
+		enc := json.NewEncoder(w)
+		for _, e := range events {
+			if err := enc.Encode(e); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return ErrUnknownFormat
+}
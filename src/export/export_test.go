@@ -0,0 +1,136 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/troydota/modlogs/src/mongo"
+)
+
+func testEvents() []*mongo.Event {
+	expires := time.Date(2021, 6, 1, 12, 10, 0, 0, time.UTC)
+	return []*mongo.Event{
+		{
+			ID:                  "1",
+			BroadcasterID:       "10",
+			BroadcasterUserName: "streamer",
+			ModeratorID:         "20",
+			ModeratorUserName:   "mod",
+			UserID:              "30",
+			UserName:            "user",
+			Reason:              "spam, \"links\"\nand more",
+			Action:              "channel.ban",
+			Expires:             &expires,
+			CreatedAt:           time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC),
+			GuildIDs:            []string{"40"},
+		},
+		{
+			ID:                  "2",
+			BroadcasterID:       "10",
+			BroadcasterUserName: "streamer",
+			UserID:              "30",
+			UserName:            "user",
+			Action:              "channel.unban",
+			CreatedAt:           time.Date(2021, 6, 1, 12, 5, 0, 0, time.UTC),
+		},
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := Write(buf, FormatCSV, testEvents()); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := csv.NewReader(buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := [][]string{
+		csvHeader,
+		{"1", "2021-06-01T12:00:00Z", "channel.ban", "10", "streamer", "20", "mod", "30", "user", "spam, \"links\"\nand more", "2021-06-01T12:10:00Z"},
+		{"2", "2021-06-01T12:05:00Z", "channel.unban", "10", "streamer", "", "", "30", "user", "", ""},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("got %d records, want %d", len(records), len(want))
+	}
+	for i := range want {
+		if strings.Join(records[i], "|") != strings.Join(want[i], "|") {
+			t.Errorf("record %d = %q, want %q", i, records[i], want[i])
+		}
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	tests := []struct {
+		format string
+		decode func(data []byte) ([]*mongo.Event, error)
+	}{
+		{FormatJSON, func(data []byte) ([]*mongo.Event, error) {
+			events := []*mongo.Event{}
+			err := json.Unmarshal(data, &events)
+			return events, err
+		}},
+		{FormatNDJSON, func(data []byte) ([]*mongo.Event, error) {
+			events := []*mongo.Event{}
+			for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+				e := &mongo.Event{}
+				if err := json.Unmarshal([]byte(line), e); err != nil {
+					return nil, err
+				}
+				events = append(events, e)
+			}
+			return events, nil
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			if err := Write(buf, tt.format, testEvents()); err != nil {
+				t.Fatal(err)
+			}
+			if strings.Contains(buf.String(), "guild_ids") {
+				t.Errorf("export contains the guilds the events were logged to")
+			}
+
+			events, err := tt.decode(buf.Bytes())
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := testEvents()
+			if len(events) != len(want) {
+				t.Fatalf("got %d events, want %d", len(events), len(want))
+			}
+			for i, e := range events {
+				if e.ID != want[i].ID || e.Action != want[i].Action || e.Reason != want[i].Reason || !e.CreatedAt.Equal(want[i].CreatedAt) {
+					t.Errorf("event %d = %+v, want %+v", i, e, want[i])
+				}
+				if (e.Expires == nil) != (want[i].Expires == nil) || (e.Expires != nil && !e.Expires.Equal(*want[i].Expires)) {
+					t.Errorf("event %d expires = %v, want %v", i, e.Expires, want[i].Expires)
+				}
+			}
+		})
+	}
+}
+
+func TestWriteUnknownFormat(t *testing.T) {
+	if err := Write(&bytes.Buffer{}, "xml", testEvents()); err != ErrUnknownFormat {
+		t.Errorf("Write = %v, want %v", err, ErrUnknownFormat)
+	}
+}
+
+func TestFileName(t *testing.T) {
+	r := &Request{
+		Format: FormatNDJSON,
+		From:   time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC),
+		To:     time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC),
+	}
+	if got := FileName("streamer", r); got != "modlogs-streamer-20210601-20210701.ndjson" {
+		t.Errorf("FileName = %q", got)
+	}
+}
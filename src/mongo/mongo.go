@@ -16,7 +16,13 @@ var Database *mongo.Database
 
 var ErrNoDocuments = mongo.ErrNoDocuments
 
+var IsDuplicateKeyError = mongo.IsDuplicateKeyError
+
 func init() {
+	if configure.Testing {
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
 	defer cancel()
 	clientOptions := options.Client().ApplyURI(configure.Config.GetString("mongo_uri"))
@@ -37,6 +43,109 @@ func init() {
 		{Keys: bson.M{"channel_id": 1}},
 		{Keys: bson.M{"guild_id": 1}},
 		{Keys: bson.M{"streamer_id": 1}},
+		{Keys: bson.D{{Key: "digest", Value: 1}, {Key: "digest_at", Value: 1}}},
+	})
+	if err != nil {
+		log.WithError(err).Fatal("mongo")
+	}
+
+	_, err = Database.Collection("events").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.M{"id": 1}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "broadcaster_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.M{"moderator_id": 1}},
+		{Keys: bson.M{"user_id": 1}},
+		{Keys: bson.M{"user_name": 1}},
+	})
+	if err != nil {
+		log.WithError(err).Fatal("mongo")
+	}
+
+	_, err = Database.Collection("sinks").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.M{"guild_id": 1}},
+		{Keys: bson.M{"streamer_id": 1}},
+	})
+	if err != nil {
+		log.WithError(err).Fatal("mongo")
+	}
+
+	_, err = Database.Collection("permissions").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.M{"guild_id": 1}, Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		log.WithError(err).Fatal("mongo")
+	}
+
+	_, err = Database.Collection("alerts").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.M{"guild_id": 1}, Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		log.WithError(err).Fatal("mongo")
+	}
+
+	_, err = Database.Collection("messages").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.M{"event_id": 1}},
+		{Keys: bson.D{{Key: "guild_id", Value: 1}, {Key: "channel_id", Value: 1}}},
+		// Old log messages are no longer edited, so they are dropped after a while.
+		{Keys: bson.M{"created_at": 1}, Options: options.Index().SetExpireAfterSeconds(LogMessageExpiry)},
+	})
+	if err != nil {
+		log.WithError(err).Fatal("mongo")
+	}
+
+	_, err = Database.Collection("timeouts").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.M{"event_id": 1}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.M{"expires": 1}},
+	})
+	if err != nil {
+		log.WithError(err).Fatal("mongo")
+	}
+
+	_, err = Database.Collection("ignores").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.M{"guild_id": 1},
+	})
+	if err != nil {
+		log.WithError(err).Fatal("mongo")
+	}
+
+	// The case index used to allow duplicates, it is replaced by a unique one. A missing collection or index is fine.
+	if _, err := Database.Collection("events").Indexes().DropOne(ctx, "case_1"); err != nil {
+		if cmdErr, ok := err.(mongo.CommandError); !ok || (cmdErr.Code != 26 && cmdErr.Code != 27) {
+			log.WithError(err).Fatal("mongo")
+		}
+	}
+
+	_, err = Database.Collection("events").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.M{"case": 1}, Options: options.Index().SetName("case_unique").SetUnique(true).SetSparse(true),
+	})
+	if err != nil {
+		log.WithError(err).Fatal("mongo")
+	}
+
+	_, err = Database.Collection("notes").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "guild_id", Value: 1}, {Key: "case", Value: 1}},
+	})
+	if err != nil {
+		log.WithError(err).Fatal("mongo")
+	}
+
+	_, err = Database.Collection("appealchannels").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "guild_id", Value: 1}, {Key: "streamer_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.M{"streamer_id": 1}},
+	})
+	if err != nil {
+		log.WithError(err).Fatal("mongo")
+	}
+
+	_, err = Database.Collection("appeals").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.M{"event_id": 1}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.M{"user_id": 1}},
+	})
+	if err != nil {
+		log.WithError(err).Fatal("mongo")
+	}
+
+	_, err = Database.Collection("unbanrequests").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.M{"id": 1}, Options: options.Index().SetUnique(true),
 	})
 	if err != nil {
 		log.WithError(err).Fatal("mongo")
@@ -50,4 +159,33 @@ func init() {
 	if err != nil {
 		log.WithError(err).Fatal("mongo")
 	}
+
+	_, err = Database.Collection("userlogins").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "login", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.M{"login": 1}},
+	})
+
+	if err != nil {
+		log.WithError(err).Fatal("mongo")
+	}
+
+	_, err = Database.Collection("languages").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.M{"guild_id": 1}, Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		log.WithError(err).Fatal("mongo")
+	}
+}
+
+type counter struct {
+	Seq int64 `bson:"seq"`
+}
+
+// NextSequence increments the named counter and returns its new value.
+func NextSequence(ctx context.Context, name string) (int64, error) {
+	c := &counter{}
+	err := Database.Collection("counters").FindOneAndUpdate(ctx, bson.M{"_id": name}, bson.M{
+		"$inc": bson.M{"seq": 1},
+	}, options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)).Decode(c)
+	return c.Seq, err
 }
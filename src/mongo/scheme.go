@@ -1,10 +1,37 @@
 package mongo
 
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
 type Hook struct {
-	GuildID    string `json:"guild_id" bson:"guild_id"`
-	ChannelID  string `json:"channel_id" bson:"channel_id"`
-	StreamerID string `json:"streamer_id" bson:"streamer_id"`
-	Mode       int32  `json:"mode" bson:"mode"`
+	ID            primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	GuildID       string             `json:"guild_id" bson:"guild_id"`
+	ChannelID     string             `json:"channel_id" bson:"channel_id"`
+	StreamerID    string             `json:"streamer_id" bson:"streamer_id"`
+	Mode          int32              `json:"mode" bson:"mode"`
+	Delivery      int32              `json:"delivery" bson:"delivery"`
+	WebhookID     string             `json:"webhook_id,omitempty" bson:"webhook_id,omitempty"`
+	WebhookToken  string             `json:"-" bson:"webhook_token,omitempty"`
+	WebhookName   string             `json:"webhook_name,omitempty" bson:"webhook_name,omitempty"`
+	WebhookAvatar string             `json:"webhook_avatar,omitempty" bson:"webhook_avatar,omitempty"`
+	Digest        int32              `json:"digest" bson:"digest"`
+	DigestAt      *time.Time         `json:"digest_at,omitempty" bson:"digest_at,omitempty"`
+	GuildName     string             `json:"guild_name,omitempty" bson:"guild_name,omitempty"`
+	ChannelName   string             `json:"channel_name,omitempty" bson:"channel_name,omitempty"`
+	AuthorizedBy  string             `json:"authorized_by,omitempty" bson:"authorized_by,omitempty"`
+	AddedBy       string             `json:"added_by,omitempty" bson:"added_by,omitempty"`
+	AuthorizedAt  *time.Time         `json:"authorized_at,omitempty" bson:"authorized_at,omitempty"`
+	CreatedAt     *time.Time         `json:"created_at,omitempty" bson:"created_at,omitempty"`
+	Expiry        int32              `json:"expiry" bson:"expiry"`
+	Profiles      bool               `json:"profiles" bson:"profiles"`
+	// How many chat messages of the user are added to ban and timeout logs, zero disables it.
+	ChatContext    int32 `json:"chat_context" bson:"chat_context"`
+	ChatAttachment bool  `json:"chat_attachment" bson:"chat_attachment"`
+	// Logs the chat messages deleted by moderators.
+	Deletions bool `json:"deletions" bson:"deletions"`
 }
 
 const (
@@ -12,8 +39,218 @@ const (
 	ModeEmbed
 )
 
+const (
+	DeliveryBot int32 = iota
+	DeliveryWebhook
+)
+
+const (
+	ExpiryOff int32 = iota
+	// Edits the timeout message once the timeout ends.
+	ExpiryEdit
+	// Posts a new message once the timeout ends.
+	ExpiryFollowup
+)
+
+const (
+	DigestOff int32 = iota
+	DigestDaily
+	DigestWeekly
+)
+
 type User struct {
 	ID    string `json:"id" bson:"id"`
 	Name  string `json:"name" bson:"name"`
 	Login string `json:"login" bson:"login"`
+	// The twitch profile, cached for the log enrichment.
+	ProfileImageURL string     `json:"profile_image_url,omitempty" bson:"profile_image_url,omitempty"`
+	CreatedAt       *time.Time `json:"created_at,omitempty" bson:"created_at,omitempty"`
+	UpdatedAt       *time.Time `json:"updated_at,omitempty" bson:"updated_at,omitempty"`
+}
+
+// UserLogin is a login a twitch user went by before being renamed.
+type UserLogin struct {
+	UserID    string    `json:"user_id" bson:"user_id"`
+	Login     string    `json:"login" bson:"login"`
+	Name      string    `json:"name" bson:"name"`
+	ChangedAt time.Time `json:"changed_at" bson:"changed_at"`
+}
+
+// GuildLanguage is the language a guild reads the bot in, as a discord locale.
+type GuildLanguage struct {
+	GuildID  string `json:"guild_id" bson:"guild_id"`
+	Language string `json:"language" bson:"language"`
+}
+
+type Sink struct {
+	GuildID    string `json:"guild_id" bson:"guild_id"`
+	StreamerID string `json:"streamer_id" bson:"streamer_id"`
+	Type       int32  `json:"type" bson:"type"`
+	Mode       int32  `json:"mode" bson:"mode"`
+	URL        string `json:"-" bson:"url"`
+	RoomID     string `json:"room_id,omitempty" bson:"room_id,omitempty"`
+	Token      string `json:"-" bson:"token,omitempty"`
+}
+
+const (
+	SinkSlack int32 = iota
+	SinkMatrix
+)
+
+type Event struct {
+	ID                  string     `json:"id" bson:"id"`
+	BroadcasterID       string     `json:"broadcaster_id" bson:"broadcaster_id"`
+	BroadcasterUserName string     `json:"broadcaster_user_name" bson:"broadcaster_user_name"`
+	ModeratorID         string     `json:"moderator_id,omitempty" bson:"moderator_id,omitempty"`
+	ModeratorUserName   string     `json:"moderator_user_name,omitempty" bson:"moderator_user_name,omitempty"`
+	UserID              string     `json:"user_id" bson:"user_id"`
+	UserName            string     `json:"user_name" bson:"user_name"`
+	Reason              string     `json:"reason,omitempty" bson:"reason,omitempty"`
+	Action              string     `json:"action" bson:"action"`
+	Expires             *time.Time `json:"expires,omitempty" bson:"expires,omitempty"`
+	CreatedAt           time.Time  `json:"created_at" bson:"created_at"`
+	Case                int64      `json:"case,omitempty" bson:"case,omitempty"`
+	// The discord member who issued the action through the bot, and their guild.
+	IssuedBy      string `json:"issued_by,omitempty" bson:"issued_by,omitempty"`
+	IssuedGuildID string `json:"issued_guild_id,omitempty" bson:"issued_guild_id,omitempty"`
+	// The unban which lifted this ban or timeout.
+	ReversedBy string `json:"reversed_by,omitempty" bson:"reversed_by,omitempty"`
+	// The guilds the event was logged to, so they can still export it once their hooks are gone.
+	GuildIDs []string `json:"-" bson:"guild_ids,omitempty"`
+}
+
+type Permissions struct {
+	GuildID      string   `json:"guild_id" bson:"guild_id"`
+	ManagerRoles []string `json:"manager_roles" bson:"manager_roles"`
+	ReaderRoles  []string `json:"reader_roles" bson:"reader_roles"`
+}
+
+// IgnoreRule hides the events it matches from the hooks of a guild.
+// Empty fields match everything, so a rule with only a user ignores that user everywhere.
+type IgnoreRule struct {
+	ID         primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	GuildID    string             `json:"guild_id" bson:"guild_id"`
+	UserID     string             `json:"user_id,omitempty" bson:"user_id,omitempty"`
+	Match      string             `json:"match" bson:"match"`
+	StreamerID string             `json:"streamer_id,omitempty" bson:"streamer_id,omitempty"`
+	ChannelID  string             `json:"channel_id,omitempty" bson:"channel_id,omitempty"`
+	Actions    []string           `json:"actions,omitempty" bson:"actions,omitempty"`
+	Reason     string             `json:"reason,omitempty" bson:"reason,omitempty"`
+	CreatedBy  string             `json:"created_by,omitempty" bson:"created_by,omitempty"`
+	CreatedAt  time.Time          `json:"created_at" bson:"created_at"`
+}
+
+const (
+	MatchExecutor = "executor"
+	MatchTarget   = "target"
+	MatchAny      = "any"
+)
+
+// AlertSettings holds the activity thresholds of a guild, alerts are posted into the hook channels.
+type AlertSettings struct {
+	GuildID string      `json:"guild_id" bson:"guild_id"`
+	RoleID  string      `json:"role_id,omitempty" bson:"role_id,omitempty"`
+	Rules   []AlertRule `json:"rules" bson:"rules"`
+}
+
+// AlertRule triggers when Count matching actions happen within Minutes.
+type AlertRule struct {
+	Kind    string `json:"kind" bson:"kind"`
+	Count   int32  `json:"count" bson:"count"`
+	Minutes int32  `json:"minutes" bson:"minutes"`
+}
+
+const (
+	// Bans in a channel.
+	AlertBans = "bans"
+	// Bans in a channel by a single moderator.
+	AlertModBans = "mod_bans"
+	// Timeouts of the same user in a channel.
+	AlertTimeouts = "timeouts"
+)
+
+// LogMessageExpiry is how many seconds the log messages are kept for.
+const LogMessageExpiry = 90 * 24 * 60 * 60
+
+// LogMessage is a discord message the bot posted for an event.
+type LogMessage struct {
+	EventID    string    `json:"event_id" bson:"event_id"`
+	GuildID    string    `json:"guild_id" bson:"guild_id"`
+	ChannelID  string    `json:"channel_id" bson:"channel_id"`
+	StreamerID string    `json:"streamer_id" bson:"streamer_id"`
+	MessageID  string    `json:"message_id" bson:"message_id"`
+	WebhookID  string    `json:"webhook_id,omitempty" bson:"webhook_id,omitempty"`
+	Mode       int32     `json:"mode" bson:"mode"`
+	CreatedAt  time.Time `json:"created_at" bson:"created_at"`
+}
+
+// TimeoutJob schedules the follow-up of a timeout for when it expires.
+type TimeoutJob struct {
+	EventID       string     `json:"event_id" bson:"event_id"`
+	BroadcasterID string     `json:"broadcaster_id" bson:"broadcaster_id"`
+	UserID        string     `json:"user_id" bson:"user_id"`
+	Expires       time.Time  `json:"expires" bson:"expires"`
+	ClaimedAt     *time.Time `json:"claimed_at,omitempty" bson:"claimed_at,omitempty"`
+}
+
+// Note is the context a discord moderator attached to a case.
+type Note struct {
+	Case      int64     `json:"case" bson:"case"`
+	EventID   string    `json:"event_id" bson:"event_id"`
+	GuildID   string    `json:"guild_id" bson:"guild_id"`
+	AuthorID  string    `json:"author_id" bson:"author_id"`
+	Text      string    `json:"text" bson:"text"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+}
+
+// AppealChannel is where a guild reviews the ban appeals of a broadcaster.
+type AppealChannel struct {
+	GuildID    string `json:"guild_id" bson:"guild_id"`
+	StreamerID string `json:"streamer_id" bson:"streamer_id"`
+	ChannelID  string `json:"channel_id" bson:"channel_id"`
+}
+
+type Appeal struct {
+	ID                  primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	EventID             string             `json:"event_id" bson:"event_id"`
+	BroadcasterID       string             `json:"broadcaster_id" bson:"broadcaster_id"`
+	BroadcasterUserName string             `json:"broadcaster_user_name" bson:"broadcaster_user_name"`
+	UserID              string             `json:"user_id" bson:"user_id"`
+	UserName            string             `json:"user_name" bson:"user_name"`
+	Text                string             `json:"text" bson:"text"`
+	Status              int32              `json:"status" bson:"status"`
+	Response            string             `json:"response,omitempty" bson:"response,omitempty"`
+	ResolvedBy          string             `json:"resolved_by,omitempty" bson:"resolved_by,omitempty"`
+	ResolvedAt          *time.Time         `json:"resolved_at,omitempty" bson:"resolved_at,omitempty"`
+	Messages            []AppealMessage    `json:"messages,omitempty" bson:"messages,omitempty"`
+	CreatedAt           time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// AppealMessage is an appeal posted in a review channel.
+type AppealMessage struct {
+	ChannelID string `json:"channel_id" bson:"channel_id"`
+	MessageID string `json:"message_id" bson:"message_id"`
+}
+
+const (
+	AppealPending int32 = iota
+	AppealApproved
+	AppealDenied
+)
+
+// UnbanRequest is an unban request made on twitch.
+type UnbanRequest struct {
+	ID                  string     `json:"id" bson:"id"`
+	BroadcasterID       string     `json:"broadcaster_id" bson:"broadcaster_id"`
+	BroadcasterUserName string     `json:"broadcaster_user_name" bson:"broadcaster_user_name"`
+	UserID              string     `json:"user_id" bson:"user_id"`
+	UserName            string     `json:"user_name" bson:"user_name"`
+	Text                string     `json:"text" bson:"text"`
+	AccountCreatedAt    *time.Time `json:"account_created_at,omitempty" bson:"account_created_at,omitempty"`
+	Status              string     `json:"status" bson:"status"`
+	ModeratorUserName   string     `json:"moderator_user_name,omitempty" bson:"moderator_user_name,omitempty"`
+	Resolution          string     `json:"resolution,omitempty" bson:"resolution,omitempty"`
+	IssuedBy            string     `json:"issued_by,omitempty" bson:"issued_by,omitempty"`
+	CreatedAt           time.Time  `json:"created_at" bson:"created_at"`
+	ResolvedAt          *time.Time `json:"resolved_at,omitempty" bson:"resolved_at,omitempty"`
 }
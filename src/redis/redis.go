@@ -14,6 +14,10 @@ var (
 )
 
 func init() {
+	if configure.Testing {
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
 	defer cancel()
 	options, err := redis.ParseURL(configure.Config.GetString("redis_uri"))
@@ -38,4 +42,6 @@ type StringCmd = redis.StringCmd
 
 type StringStringMapCmd = redis.StringStringMapCmd
 
+type Z = redis.Z
+
 const ErrNil = redis.Nil
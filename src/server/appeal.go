@@ -0,0 +1,238 @@
+package server
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	log "github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/troydota/modlogs/src/bot"
+	"github.com/troydota/modlogs/src/mongo"
+	"github.com/troydota/modlogs/src/redis"
+)
+
+const maxAppealLength = 1000
+
+// appealableBans returns the latest ban of the user in each channel which reviews appeals, unless the user was unbanned since.
+func appealableBans(c *fiber.Ctx, userID string) ([]*mongo.Event, error) {
+	events := []*mongo.Event{}
+	cur, err := mongo.Database.Collection("events").Find(c.Context(), bson.M{
+		"user_id": userID,
+		"action": bson.M{
+			"$in": bson.A{"channel.ban", "channel.unban"},
+		},
+	}, options.Find().SetSort(bson.M{"created_at": -1}))
+	if err == nil {
+		err = cur.All(c.Context(), &events)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	bans := []*mongo.Event{}
+	seen := map[string]bool{}
+	for _, e := range events {
+		if seen[e.BroadcasterID] {
+			continue
+		}
+		seen[e.BroadcasterID] = true
+		if e.Action != "channel.ban" || e.Expires != nil {
+			continue
+		}
+
+		count, err := mongo.Database.Collection("appealchannels").CountDocuments(c.Context(), bson.M{"streamer_id": e.BroadcasterID})
+		if err != nil {
+			return nil, err
+		}
+		if count != 0 {
+			bans = append(bans, e)
+		}
+	}
+
+	return bans, nil
+}
+
+func Appeal(app fiber.Router) fiber.Router {
+	app.Get("/appeal", func(c *fiber.Ctx) error {
+		userID, csrfToken, err := getSession(c)
+		if err != nil {
+			if err != redis.ErrNil {
+				log.WithError(err).Error("redis")
+				return c.Status(500).JSON(&fiber.Map{
+					"status":  500,
+					"message": "Internal server error.",
+				})
+			}
+			return c.Redirect("/login?next=appeal")
+		}
+
+		bans, err := appealableBans(c, userID)
+		if err != nil {
+			log.WithError(err).Error("mongo")
+			return c.Status(500).JSON(&fiber.Map{
+				"status":  500,
+				"message": "Failed to load your bans.",
+			})
+		}
+
+		sections := []string{}
+		for _, e := range bans {
+			reason := e.Reason
+			if reason == "" {
+				reason = "None Provided"
+			}
+			section := fmt.Sprintf(`<h3>#%s</h3><p>Banned on %s, reason: %s</p>`, html.EscapeString(e.BroadcasterUserName), e.CreatedAt.UTC().Format("Mon Jan _2 15:04:05 2006"), html.EscapeString(reason))
+
+			appeal := &mongo.Appeal{}
+			err := mongo.Database.Collection("appeals").FindOne(c.Context(), bson.M{"event_id": e.ID}).Decode(appeal)
+			if err != nil && err != mongo.ErrNoDocuments {
+				log.WithError(err).Error("mongo")
+				return c.Status(500).JSON(&fiber.Map{
+					"status":  500,
+					"message": "Failed to load your appeals.",
+				})
+			}
+
+			if err == mongo.ErrNoDocuments {
+				section += fmt.Sprintf(
+					`<form method="POST" action="/appeal"><input type="hidden" name="csrf" value="%s"><input type="hidden" name="event" value="%s"><textarea name="text" rows="6" cols="60" maxlength="%v" required></textarea><br><button type="submit">Submit appeal</button></form>`,
+					html.EscapeString(csrfToken), html.EscapeString(e.ID), maxAppealLength,
+				)
+			} else {
+				switch appeal.Status {
+				case mongo.AppealPending:
+					section += "<p>Your appeal is waiting for a review.</p>"
+				case mongo.AppealApproved:
+					section += "<p>Your appeal was approved.</p>"
+				case mongo.AppealDenied:
+					section += "<p>Your appeal was denied.</p>"
+				}
+				section += fmt.Sprintf("<p>Your appeal: %s</p>", html.EscapeString(appeal.Text))
+				if appeal.Response != "" {
+					section += fmt.Sprintf("<p>Response from the moderators: %s</p>", html.EscapeString(appeal.Response))
+				}
+			}
+
+			sections = append(sections, section)
+		}
+
+		if len(sections) == 0 {
+			sections = append(sections, "<p>You have no bans which can be appealed.</p>")
+		}
+
+		c.Set("Content-Type", "text/html")
+
+		return c.Status(200).SendString(fmt.Sprintf(`<style>
+body {
+	font-family: monospace;
+}
+</style>
+<h2>Ban appeals</h2>%s`, strings.Join(sections, "")))
+	})
+
+	app.Post("/appeal", func(c *fiber.Ctx) error {
+		userID, csrfToken, err := getSession(c)
+		if err != nil {
+			if err != redis.ErrNil {
+				log.WithError(err).Error("redis")
+				return c.Status(500).JSON(&fiber.Map{
+					"status":  500,
+					"message": "Internal server error.",
+				})
+			}
+			return c.Redirect("/login?next=appeal")
+		}
+
+		if c.FormValue("csrf") != csrfToken {
+			return c.Status(400).JSON(&fiber.Map{
+				"status":  400,
+				"message": "Invalid request, csrf token missmatch.",
+			})
+		}
+
+		text := strings.TrimSpace(c.FormValue("text"))
+		if text == "" || len(text) > maxAppealLength {
+			return c.Status(400).JSON(&fiber.Map{
+				"status":  400,
+				"message": fmt.Sprintf("The appeal must be between 1 and %v characters.", maxAppealLength),
+			})
+		}
+
+		bans, err := appealableBans(c, userID)
+		if err != nil {
+			log.WithError(err).Error("mongo")
+			return c.Status(500).JSON(&fiber.Map{
+				"status":  500,
+				"message": "Failed to load your bans.",
+			})
+		}
+
+		var ban *mongo.Event
+		for _, e := range bans {
+			if e.ID == c.FormValue("event") {
+				ban = e
+			}
+		}
+		if ban == nil {
+			return c.Status(404).JSON(&fiber.Map{
+				"status":  404,
+				"message": "That ban can't be appealed.",
+			})
+		}
+
+		appeal := &mongo.Appeal{
+			EventID:             ban.ID,
+			BroadcasterID:       ban.BroadcasterID,
+			BroadcasterUserName: ban.BroadcasterUserName,
+			UserID:              ban.UserID,
+			UserName:            ban.UserName,
+			Text:                text,
+			Status:              mongo.AppealPending,
+			CreatedAt:           time.Now(),
+		}
+		res, err := mongo.Database.Collection("appeals").InsertOne(c.Context(), appeal)
+		if err != nil {
+			if mongo.IsDuplicateKeyError(err) {
+				return c.Status(409).JSON(&fiber.Map{
+					"status":  409,
+					"message": "You already appealed that ban.",
+				})
+			}
+			log.WithError(err).Error("mongo")
+			return c.Status(500).JSON(&fiber.Map{
+				"status":  500,
+				"message": "Failed to save the appeal.",
+			})
+		}
+		appeal.ID = res.InsertedID.(primitive.ObjectID)
+
+		result := make(chan error, 1)
+		bot.Appeals <- bot.AppealRequest{
+			Appeal: appeal,
+			Result: result,
+		}
+		if err := <-result; err != nil {
+			// Removing the appeal lets the user submit it again later.
+			if _, err := mongo.Database.Collection("appeals").DeleteOne(c.Context(), bson.M{"_id": appeal.ID}); err != nil {
+				log.WithError(err).Error("mongo")
+			}
+			if err != bot.ErrNoAppealChannel {
+				log.WithError(err).Error("mongo")
+			}
+			return c.Status(500).JSON(&fiber.Map{
+				"status":  500,
+				"message": "Failed to send the appeal to the moderators, please try again later.",
+			})
+		}
+
+		return c.Redirect("/appeal")
+	})
+
+	return app
+}
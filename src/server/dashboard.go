@@ -0,0 +1,192 @@
+package server
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	log "github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/troydota/modlogs/src/bot"
+	"github.com/troydota/modlogs/src/configure"
+	"github.com/troydota/modlogs/src/mongo"
+	"github.com/troydota/modlogs/src/redis"
+	"github.com/troydota/modlogs/src/utils"
+)
+
+const sessionExpiry = time.Hour
+
+// createSession logs the twitch user into the website.
+func createSession(c *fiber.Ctx, userID string) error {
+	token, err := utils.GenerateRandomString(64)
+	if err != nil {
+		return err
+	}
+	csrfToken, err := utils.GenerateRandomString(32)
+	if err != nil {
+		return err
+	}
+
+	key := fmt.Sprintf("sessions:%s", token)
+	pipe := redis.Client.TxPipeline()
+	pipe.HSet(c.Context(), key, "user_id", userID, "csrf", csrfToken)
+	pipe.Expire(c.Context(), key, sessionExpiry)
+	if _, err := pipe.Exec(c.Context()); err != nil {
+		return err
+	}
+
+	c.Cookie(&fiber.Cookie{Name: "session", Value: token, Domain: configure.Config.GetString("cookie_domain"), Expires: time.Now().Add(sessionExpiry), HTTPOnly: true, SameSite: "Lax"})
+
+	return nil
+}
+
+// getSession returns the twitch user id and the csrf token of the session, it returns redis.ErrNil when there is no session.
+func getSession(c *fiber.Ctx) (string, string, error) {
+	token := c.Cookies("session")
+	if token == "" {
+		return "", "", redis.ErrNil
+	}
+
+	vals, err := redis.Client.HGetAll(c.Context(), fmt.Sprintf("sessions:%s", token)).Result()
+	if err != nil {
+		return "", "", err
+	}
+	if vals["user_id"] == "" {
+		return "", "", redis.ErrNil
+	}
+
+	return vals["user_id"], vals["csrf"], nil
+}
+
+func Dashboard(app fiber.Router) fiber.Router {
+	app.Get("/dashboard", func(c *fiber.Ctx) error {
+		userID, csrfToken, err := getSession(c)
+		if err != nil {
+			if err != redis.ErrNil {
+				log.WithError(err).Error("redis")
+				return c.Status(500).JSON(&fiber.Map{
+					"status":  500,
+					"message": "Internal server error.",
+				})
+			}
+			return c.Redirect("/login?next=dashboard")
+		}
+
+		user := &mongo.User{}
+		if err := mongo.Database.Collection("users").FindOne(c.Context(), bson.M{"id": userID}).Decode(user); err != nil {
+			log.WithError(err).Error("mongo")
+			return c.Status(500).JSON(&fiber.Map{
+				"status":  500,
+				"message": "Failed to load user data.",
+			})
+		}
+
+		hooks := []*mongo.Hook{}
+		cur, err := mongo.Database.Collection("hooks").Find(c.Context(), bson.M{"streamer_id": userID})
+		if err == nil {
+			err = cur.All(c.Context(), &hooks)
+		}
+		if err != nil {
+			log.WithError(err).Error("mongo")
+			return c.Status(500).JSON(&fiber.Map{
+				"status":  500,
+				"message": "Failed to load hooks.",
+			})
+		}
+
+		rows := []string{}
+		for _, h := range hooks {
+			guild := h.GuildName
+			if guild == "" {
+				guild = h.GuildID
+			}
+			channel := h.ChannelName
+			if channel == "" {
+				channel = h.ChannelID
+			}
+			var since string
+			if h.CreatedAt != nil {
+				since = h.CreatedAt.UTC().Format("Mon Jan _2 2006")
+			}
+			rows = append(rows, fmt.Sprintf(
+				`<tr><td>%s</td><td>#%s</td><td>%s</td><td><form method="POST" action="/dashboard/revoke"><input type="hidden" name="csrf" value="%s"><input type="hidden" name="hook" value="%s"><button type="submit">Revoke</button></form></td></tr>`,
+				html.EscapeString(guild), html.EscapeString(channel), html.EscapeString(since), html.EscapeString(csrfToken), h.ID.Hex(),
+			))
+		}
+
+		if len(rows) == 0 {
+			rows = append(rows, `<tr><td colspan="4">No discord servers receive your logs.</td></tr>`)
+		}
+
+		c.Set("Content-Type", "text/html")
+
+		return c.Status(200).SendString(fmt.Sprintf(`<style>
+body {
+	font-family: monospace;
+}
+td, th {
+	padding: 4px 12px;
+	text-align: left;
+}
+</style>
+<h3>Discord servers receiving the moderation logs of %s</h3>
+<table><tr><th>Server</th><th>Channel</th><th>Since</th><th></th></tr>%s</table>`, html.EscapeString(user.Name), strings.Join(rows, "")))
+	})
+
+	app.Post("/dashboard/revoke", func(c *fiber.Ctx) error {
+		userID, csrfToken, err := getSession(c)
+		if err != nil {
+			if err != redis.ErrNil {
+				log.WithError(err).Error("redis")
+				return c.Status(500).JSON(&fiber.Map{
+					"status":  500,
+					"message": "Internal server error.",
+				})
+			}
+			return c.Redirect("/login?next=dashboard")
+		}
+
+		if c.FormValue("csrf") != csrfToken {
+			return c.Status(400).JSON(&fiber.Map{
+				"status":  400,
+				"message": "Invalid request, csrf token missmatch.",
+			})
+		}
+
+		hookID, err := primitive.ObjectIDFromHex(c.FormValue("hook"))
+		if err != nil {
+			return c.Status(400).JSON(&fiber.Map{
+				"status":  400,
+				"message": "Invalid hook.",
+			})
+		}
+
+		result := make(chan error, 1)
+		bot.Revokes <- bot.RevokeRequest{
+			StreamerID: userID,
+			HookID:     hookID,
+			Result:     result,
+		}
+		if err := <-result; err != nil {
+			if err == mongo.ErrNoDocuments {
+				return c.Status(404).JSON(&fiber.Map{
+					"status":  404,
+					"message": "That hook doesn't exist.",
+				})
+			}
+			log.WithError(err).Error("mongo")
+			return c.Status(500).JSON(&fiber.Map{
+				"status":  500,
+				"message": "Failed to revoke the hook.",
+			})
+		}
+
+		return c.Redirect("/dashboard")
+	})
+
+	return app
+}
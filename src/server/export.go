@@ -0,0 +1,73 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	log "github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/troydota/modlogs/src/export"
+	"github.com/troydota/modlogs/src/mongo"
+	"github.com/troydota/modlogs/src/redis"
+)
+
+func Export(app fiber.Router) fiber.Router {
+	app.Get("/export/:token", func(c *fiber.Ctx) error {
+		data, err := redis.Client.Get(c.Context(), fmt.Sprintf("temp:exports:%s", c.Params("token"))).Result()
+		if err != nil {
+			if err != redis.ErrNil {
+				log.WithError(err).Error("redis")
+				return c.Status(500).JSON(&fiber.Map{
+					"status":  500,
+					"message": "Internal server error.",
+				})
+			}
+			return c.Status(404).JSON(&fiber.Map{
+				"status":  404,
+				"message": "The export link is expired or invalid, please run the command again.",
+			})
+		}
+
+		req := &export.Request{}
+		if err := json.Unmarshal([]byte(data), req); err != nil {
+			log.WithError(err).Error("export")
+			return c.Status(500).JSON(&fiber.Map{
+				"status":  500,
+				"message": "Internal server error.",
+			})
+		}
+
+		user := &mongo.User{}
+		if err := mongo.Database.Collection("users").FindOne(c.Context(), bson.M{"id": req.BroadcasterID}).Decode(user); err != nil {
+			log.WithError(err).Error("mongo")
+			user.Login = req.BroadcasterID
+		}
+
+		events, err := export.Find(c.Context(), req)
+		if err != nil {
+			log.WithError(err).Error("mongo")
+			return c.Status(500).JSON(&fiber.Map{
+				"status":  500,
+				"message": "Failed to load the moderation logs.",
+			})
+		}
+
+		buf := &bytes.Buffer{}
+		if err := export.Write(buf, req.Format, events); err != nil {
+			log.WithError(err).Error("export")
+			return c.Status(500).JSON(&fiber.Map{
+				"status":  500,
+				"message": "Failed to write the moderation logs.",
+			})
+		}
+
+		c.Set("Content-Type", export.ContentType(req.Format))
+		c.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, export.FileName(user.Login, req)))
+
+		return c.Status(200).Send(buf.Bytes())
+	})
+
+	return app
+}
@@ -63,6 +63,12 @@ func New() *Server {
 
 	Twitch(server.app)
 
+	Export(server.app)
+
+	Dashboard(server.app)
+
+	Appeal(server.app)
+
 	server.app.Use(func(c *fiber.Ctx) error {
 		return c.Status(404).JSON(&fiber.Map{
 			"status":  404,
@@ -1,20 +1,19 @@
 package server
 
 import (
+	"context"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"html"
 	"io/ioutil"
-	"math"
 	"net/http"
 	"net/url"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
-	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"github.com/troydota/modlogs/src/bot"
 	"github.com/troydota/modlogs/src/mongo"
@@ -22,6 +21,7 @@ import (
 	"github.com/troydota/modlogs/src/utils"
 
 	"github.com/troydota/modlogs/src/api"
+	"github.com/troydota/modlogs/src/auth"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/troydota/modlogs/src/configure"
@@ -76,10 +76,39 @@ func Twitch(app fiber.Router) fiber.Router {
 
 		scopes := []string{}
 
-		scopes = append(scopes, "channel:moderate", "moderation:read")
+		// Banned users only login to appeal, so they don't have to grant anything.
+		if c.Query("next") != "appeal" {
+			scopes = append(scopes, "channel:moderate", "moderation:read", "user:read:moderated_channels", "moderator:manage:banned_users", "moderator:manage:unban_requests", "user:read:chat", "user:bot",
+				"moderator:read:blocked_terms", "moderator:read:chat_settings", "moderator:read:chat_messages", "moderator:read:moderators", "moderator:read:vips", "moderator:read:warnings")
+		}
 
 		c.Cookie(&fiber.Cookie{Name: "crsf_token", Value: csrfToken, Domain: configure.Config.GetString("cookie_domain"), Expires: time.Now().Add(time.Second * 300)})
 
+		if linkID := c.Query("link"); linkID != "" {
+			if _, err := bot.GetLink(c.Context(), linkID); err != nil {
+				if err != redis.ErrNil {
+					log.WithError(err).Error("redis")
+					return c.Status(500).JSON(&fiber.Map{
+						"message": "Internal server error.",
+						"status":  500,
+					})
+				}
+				return c.Status(404).JSON(&fiber.Map{
+					"message": "The link is expired or invalid, please ask for a new one.",
+					"status":  404,
+				})
+			}
+			c.Cookie(&fiber.Cookie{Name: "link_token", Value: linkID, Domain: configure.Config.GetString("cookie_domain"), Expires: time.Now().Add(time.Second * 300)})
+		} else {
+			c.ClearCookie("link_token")
+		}
+
+		if next := c.Query("next"); next == "dashboard" || next == "appeal" {
+			c.Cookie(&fiber.Cookie{Name: "login_next", Value: next, Domain: configure.Config.GetString("cookie_domain"), Expires: time.Now().Add(time.Second * 300)})
+		} else {
+			c.ClearCookie("login_next")
+		}
+
 		params, _ := qs.Marshal(map[string]string{
 			"client_id":     configure.Config.GetString("twitch_client_id"),
 			"redirect_uri":  configure.Config.GetString("twitch_redirect_uri"),
@@ -165,8 +194,6 @@ func Twitch(app fiber.Router) fiber.Router {
 			})
 		}
 
-		data, _ = json.Marshal(tokenResp)
-
 		users, err := api.GetUsers(c.Context(), tokenResp.AccessToken, nil, nil)
 		if err != nil || len(users) != 1 {
 			log.WithError(err).WithField("resp", users).WithField("token", tokenResp).Error("twitch")
@@ -178,18 +205,16 @@ func Twitch(app fiber.Router) fiber.Router {
 
 		user := users[0]
 
-		exp := int64(math.Floor(float64(tokenResp.ExpiresIn) * 0.7))
-
-		opts := options.Update().SetUpsert(true)
+		now := time.Now()
 		mUser := &mongo.User{
-			ID:    user.ID,
-			Name:  user.DisplayName,
-			Login: user.Login,
-		}
-		if _, err := mongo.Database.Collection("users").UpdateOne(c.Context(), bson.M{"$or": bson.A{
-			bson.M{"id": user.ID},
-			bson.M{"login": user.Login},
-		}}, bson.M{"$set": mUser}, opts); err != nil {
+			ID:              user.ID,
+			Name:            user.DisplayName,
+			Login:           user.Login,
+			ProfileImageURL: user.ProfileImageURL,
+			CreatedAt:       &user.CreatedAt,
+			UpdatedAt:       &now,
+		}
+		if err := bot.StoreUser(c.Context(), mUser); err != nil {
 			log.WithError(err).Error("mongo")
 			return c.Status(500).JSON(&fiber.Map{
 				"status":  500,
@@ -197,12 +222,104 @@ func Twitch(app fiber.Router) fiber.Router {
 			})
 		}
 
-		if err := redis.Client.HSet(c.Context(), "oauth:streamer", user.ID, fmt.Sprintf("%v %s", time.Now().Unix()+exp, string(data))).Err(); err != nil {
-			log.WithError(err).Error("redis")
-			return c.Status(500).JSON(&fiber.Map{
-				"status":  500,
-				"message": "Failed to save OAuth token.",
-			})
+		// The token of an appeal login has no scopes, it must not replace the token of a streamer or a moderator.
+		next := c.Cookies("login_next")
+		if next != "appeal" {
+			if err := auth.SaveUserToken(c.Context(), user.ID, &auth.UserToken{
+				AccessToken:  tokenResp.AccessToken,
+				RefreshToken: tokenResp.RefreshToken,
+				ExpiresIn:    tokenResp.ExpiresIn,
+				Scope:        tokenResp.Scope,
+				TokenType:    tokenResp.TokenType,
+			}); err != nil {
+				log.WithError(err).Error("redis")
+				return c.Status(500).JSON(&fiber.Map{
+					"status":  500,
+					"message": "Failed to save OAuth token.",
+				})
+			}
+		}
+
+		if next == "dashboard" || next == "appeal" {
+			c.ClearCookie("login_next")
+
+			if err := createSession(c, user.ID); err != nil {
+				log.WithError(err).Error("redis")
+				return c.Status(500).JSON(&fiber.Map{
+					"status":  500,
+					"message": "Failed to create session.",
+				})
+			}
+
+			return c.Redirect(fmt.Sprintf("/%s", next))
+		}
+
+		if linkID := c.Cookies("link_token"); linkID != "" {
+			c.ClearCookie("link_token")
+
+			link, err := bot.GetLink(c.Context(), linkID)
+			if err != nil {
+				if err != redis.ErrNil {
+					log.WithError(err).Error("redis")
+					return c.Status(500).JSON(&fiber.Map{
+						"status":  500,
+						"message": "Failed to load the link.",
+					})
+				}
+				return c.Status(404).JSON(&fiber.Map{
+					"status":  404,
+					"message": "The link is expired or invalid, please ask for a new one.",
+				})
+			}
+
+			linkReq := bot.LinkRequest{
+				Link:   link,
+				User:   mUser,
+				Result: make(chan error, 1),
+			}
+
+			if !strings.EqualFold(link.Broadcaster, user.Login) && link.Broadcaster != user.ID {
+				channels, err := api.GetModeratedChannels(c.Context(), tokenResp.AccessToken, user.ID)
+				if err != nil {
+					log.WithError(err).Error("api")
+				}
+
+				channel := findModeratedChannel(channels, link.Broadcaster)
+				if channel == nil {
+					return c.Status(403).JSON(&fiber.Map{
+						"status":  403,
+						"message": fmt.Sprintf("This link was made for %s, please login with that account or one of its moderators.", link.Broadcaster),
+					})
+				}
+
+				broadcaster, err := saveModeratedChannel(c, channel)
+				if err != nil {
+					log.WithError(err).Error("mongo")
+					return c.Status(500).JSON(&fiber.Map{
+						"status":  500,
+						"message": "Failed to save user data.",
+					})
+				}
+
+				linkReq.User = broadcaster
+				linkReq.Moderator = mUser
+			}
+
+			bot.Links <- linkReq
+			if err := <-linkReq.Result; err != nil {
+				return c.Status(500).JSON(&fiber.Map{
+					"status":  500,
+					"message": "Failed to add the hook, the reason was posted in the discord channel.",
+				})
+			}
+
+			if err := bot.DeleteLink(c.Context(), linkID); err != nil {
+				log.WithError(err).Error("redis")
+			}
+
+			c.Set("Content-Type", "text/html")
+
+			return c.Status(200).Send(jsonPage(200, "Everything went as planned, the logs of your channel will now be posted into the discord channel that requested them.", ""))
 		}
 
 		authCode, _ := uuid.NewRandom()
@@ -215,35 +332,87 @@ func Twitch(app fiber.Router) fiber.Router {
 			})
 		}
 
+		page := jsonPage(200, "Everything went as planned, to add the bot to your discord you can use the invite link, and then type the command in the channel you want the logs to appear in, the command will expire in 300 seconds.", fmt.Sprintf("/add token: %s", authCode.String()))
+
+		channels, err := api.GetModeratedChannels(c.Context(), tokenResp.AccessToken, user.ID)
+		if err != nil {
+			log.WithError(err).Error("api")
+		}
+
+		if len(channels) != 0 {
+			pickCode, _ := uuid.NewRandom()
+			data, _ := json.Marshal(moderatorPick{
+				ModeratorID: user.ID,
+				Channels:    channels,
+			})
+
+			if err := redis.Client.Set(c.Context(), fmt.Sprintf("temp:picks:%s", pickCode), data, time.Second*300).Err(); err != nil {
+				log.WithError(err).Error("redis")
+			} else {
+				page = append(page, moderatedChannelsList(pickCode.String(), channels)...)
+			}
+		}
+
 		c.Set("Content-Type", "text/html")
 
-		jsonData, _ := json.MarshalIndent(fiber.Map{
-			"`status*":  `status_code`,
-			"`message*": "message_content",
-			"`command*": "command_content",
-			"`link*":    "link_url",
-		}, "", "  ")
+		return c.Status(200).Send(page)
+	})
 
-		status_code := `<span class="json-value">200</span>`
+	app.Get("/login/channel", func(c *fiber.Ctx) error {
+		data, err := redis.Client.Get(c.Context(), fmt.Sprintf("temp:picks:%s", c.Query("pick"))).Bytes()
+		if err != nil {
+			if err != redis.ErrNil {
+				log.WithError(err).Error("redis")
+				return c.Status(500).JSON(&fiber.Map{
+					"status":  500,
+					"message": "Internal server error.",
+				})
+			}
+			return c.Status(404).JSON(&fiber.Map{
+				"status":  404,
+				"message": "The selection is expired or invalid, please login again.",
+			})
+		}
 
-		message := `<span class="json-string">Everything went as planned, to add the bot to your discord you can use the invite link, and then type the command in the channel you want the logs to appear in, the command will expire in 300 seconds.</span>`
+		pick := moderatorPick{}
+		if err := json.Unmarshal(data, &pick); err != nil {
+			log.WithError(err).Error("json")
+			return c.Status(500).JSON(&fiber.Map{
+				"status":  500,
+				"message": "Internal server error.",
+			})
+		}
 
-		command := fmt.Sprintf(`<span class="json-string">/add token: %s</span>`, authCode.String())
+		channel := findModeratedChannel(pick.Channels, c.Query("id"))
+		if channel == nil {
+			return c.Status(403).JSON(&fiber.Map{
+				"status":  403,
+				"message": "You are not a moderator of that channel.",
+			})
+		}
 
-		css := `
-.json-key {
-	color: brown;
-}
-.json-value {
-	color: green;
-}
-.json-string {
-	color: teal;
-}`
+		broadcaster, err := saveModeratedChannel(c, channel)
+		if err != nil {
+			log.WithError(err).Error("mongo")
+			return c.Status(500).JSON(&fiber.Map{
+				"status":  500,
+				"message": "Failed to save user data.",
+			})
+		}
 
-		jsonStr := strings.Replace(strings.Replace(strings.Replace(strings.Replace(strings.ReplaceAll(strings.ReplaceAll(string(jsonData), "\"`", `<span class="json-key">"`), "*\"", "\"</span>"), "\"status_code\"", status_code, 1), "message_content", message, 1), "command_content", command, 1), "link_url", fmt.Sprintf(`<a href="%s">%s</a>`, configure.Config.GetString("website_url"), configure.Config.GetString("website_url")), 1)
+		authCode, _ := uuid.NewRandom()
 
-		return c.Status(200).Send([]byte(fmt.Sprintf(`<style>%s</style><pre><code>%s</code></pre>`, css, jsonStr)))
+		if err := redis.Client.SetNX(c.Context(), fmt.Sprintf("temp:codes:%s", authCode), fmt.Sprintf("%s %s", broadcaster.ID, pick.ModeratorID), time.Second*300).Err(); err != nil {
+			log.WithError(err).Error("redis")
+			return c.Status(500).JSON(&fiber.Map{
+				"status":  500,
+				"message": "Failed to save temp secret.",
+			})
+		}
+
+		c.Set("Content-Type", "text/html")
+
+		return c.Status(200).Send(jsonPage(200, fmt.Sprintf("Everything went as planned, to log the channel of %s type the command in the discord channel you want the logs to appear in, the command will expire in 300 seconds.", broadcaster.Name), fmt.Sprintf("/add token: %s", authCode.String())))
 	})
 
 	app.Post("/webhook/:type/:id", func(c *fiber.Ctx) error {
@@ -338,7 +507,91 @@ func Twitch(app fiber.Router) fiber.Router {
 			return cleanUp(200, callback.Challenge)
 		}
 
+		if callback.Subscription.Type == "channel.unban_request.create" || callback.Subscription.Type == "channel.unban_request.resolve" {
+			request, ok := unbanRequest(callback)
+			if !ok {
+				log.WithField("event", callback.Event).Error("bad event")
+				return cleanUp(400, "")
+			}
+			bot.UnbanRequests <- bot.UnbanRequestEvent{
+				Action:  callback.Subscription.Type,
+				Request: request,
+			}
+			return cleanUp(200, "")
+		}
+
+		if callback.Subscription.Type == "channel.chat.message" {
+			userID, _ := callback.Event["chatter_user_id"].(string)
+			messageID, _ := callback.Event["message_id"].(string)
+			message, _ := callback.Event["message"].(map[string]interface{})
+			text, _ := message["text"].(string)
+			if userID == "" || messageID == "" {
+				log.WithField("event", callback.Event).Error("bad event")
+				return cleanUp(400, "")
+			}
+			if err := bot.StoreChatMessage(c.Context(), c.Params("id"), userID, &bot.ChatMessage{
+				ID:     messageID,
+				Text:   text,
+				SentAt: t,
+			}); err != nil {
+				log.WithError(err).Error("redis")
+				return cleanUp(500, "")
+			}
+			return cleanUp(200, "")
+		}
+
+		if callback.Subscription.Type == "channel.chat.message_delete" {
+			deletion := bot.ChatDeletion{
+				BroadcasterID: c.Params("id"),
+				DeletedAt:     t,
+			}
+			deletion.MessageID, _ = callback.Event["message_id"].(string)
+			deletion.BroadcasterUserName, _ = callback.Event["broadcaster_user_login"].(string)
+			deletion.UserID, _ = callback.Event["target_user_id"].(string)
+			deletion.UserName, _ = callback.Event["target_user_login"].(string)
+			if deletion.MessageID == "" || deletion.UserID == "" {
+				log.WithField("event", callback.Event).Error("bad event")
+				return cleanUp(400, "")
+			}
+			bot.Deletions <- deletion
+			return cleanUp(200, "")
+		}
+
+		if callback.Subscription.Type == "channel.moderate" {
+			action, _ := callback.Event["action"].(string)
+			if action != "delete" {
+				req, ok, err := moderateRequest(c.Context(), msgID, t, c.Params("id"), action, callback.Event)
+				if err != nil {
+					log.WithError(err).Error("redis")
+					return cleanUp(500, "")
+				}
+				if !ok {
+					log.WithField("event", callback.Event).Error("bad event")
+					return cleanUp(400, "")
+				}
+				if req != nil {
+					bot.Callback <- *req
+				}
+				return cleanUp(200, "")
+			}
+			deleted, _ := callback.Event["delete"].(map[string]interface{})
+			messageID, _ := deleted["message_id"].(string)
+			text, _ := deleted["message_body"].(string)
+			moderatorID, _ := callback.Event["moderator_user_id"].(string)
+			moderatorName, _ := callback.Event["moderator_user_login"].(string)
+			if messageID == "" {
+				log.WithField("event", callback.Event).Error("bad event")
+				return cleanUp(400, "")
+			}
+			if err := bot.StoreDeletionModerator(c.Context(), c.Params("id"), messageID, moderatorID, moderatorName, text); err != nil {
+				log.WithError(err).Error("redis")
+				return cleanUp(500, "")
+			}
+			return cleanUp(200, "")
+		}
+
 		req := bot.WebhookRequest{
+			ID:            msgID,
 			CreatedAt:     t,
 			BroadcasterID: c.Params("id"),
 			Action:        callback.Subscription.Type,
@@ -356,6 +609,11 @@ func Twitch(app fiber.Router) fiber.Router {
 				log.WithField("event", callback.Event).Error("bad event")
 				return cleanUp(400, "")
 			}
+			req.UserID, ok = callback.Event["user_id"].(string)
+			if !ok {
+				log.WithField("event", callback.Event).Error("bad event")
+				return cleanUp(400, "")
+			}
 			req.Reason, ok = callback.Event["reason"].(string)
 			if !ok {
 				log.WithField("event", callback.Event).Error("bad event")
@@ -396,6 +654,11 @@ func Twitch(app fiber.Router) fiber.Router {
 				log.WithField("event", callback.Event).Error("bad event")
 				return cleanUp(400, "")
 			}
+			req.UserID, ok = callback.Event["user_id"].(string)
+			if !ok {
+				log.WithField("event", callback.Event).Error("bad event")
+				return cleanUp(400, "")
+			}
 			req.ModeratorUserName, ok = callback.Event["moderator_user_login"].(string)
 			if !ok {
 				log.WithField("event", callback.Event).Error("bad event")
@@ -418,6 +681,11 @@ func Twitch(app fiber.Router) fiber.Router {
 				log.WithField("event", callback.Event).Error("bad event")
 				return cleanUp(400, "")
 			}
+			req.UserID, ok = callback.Event["user_id"].(string)
+			if !ok {
+				log.WithField("event", callback.Event).Error("bad event")
+				return cleanUp(400, "")
+			}
 		} else if callback.Subscription.Type == "channel.moderator.remove" {
 			var ok bool
 			req.BroadcasterUserName, ok = callback.Event["broadcaster_user_login"].(string)
@@ -430,6 +698,11 @@ func Twitch(app fiber.Router) fiber.Router {
 				log.WithField("event", callback.Event).Error("bad event")
 				return cleanUp(400, "")
 			}
+			req.UserID, ok = callback.Event["user_id"].(string)
+			if !ok {
+				log.WithField("event", callback.Event).Error("bad event")
+				return cleanUp(400, "")
+			}
 		}
 
 		bot.Callback <- req
@@ -439,3 +712,191 @@ func Twitch(app fiber.Router) fiber.Router {
 
 	return app
 }
+
+// jsonPage renders the message as a colored json document, the command is left out when empty.
+func jsonPage(statusCode int, message string, command string) []byte {
+	page := fiber.Map{
+		"`status*":  `status_code`,
+		"`message*": "message_content",
+		"`link*":    "link_url",
+	}
+	if command != "" {
+		page["`command*"] = "command_content"
+	}
+
+	jsonData, _ := json.MarshalIndent(page, "", "  ")
+
+	status_code := fmt.Sprintf(`<span class="json-value">%v</span>`, statusCode)
+
+	message = fmt.Sprintf(`<span class="json-string">%s</span>`, html.EscapeString(message))
+
+	command = fmt.Sprintf(`<span class="json-string">%s</span>`, html.EscapeString(command))
+
+	css := `
+.json-key {
+	color: brown;
+}
+.json-value {
+	color: green;
+}
+.json-string {
+	color: teal;
+}`
+
+	jsonStr := strings.Replace(strings.Replace(strings.Replace(strings.Replace(strings.ReplaceAll(strings.ReplaceAll(string(jsonData), "\"`", `<span class="json-key">"`), "*\"", "\"</span>"), "\"status_code\"", status_code, 1), "message_content", message, 1), "command_content", command, 1), "link_url", fmt.Sprintf(`<a href="%s">%s</a>`, configure.Config.GetString("website_url"), configure.Config.GetString("website_url")), 1)
+
+	return []byte(fmt.Sprintf(`<style>%s</style><pre><code>%s</code></pre>`, css, jsonStr))
+}
+
+// moderatorPick holds the channels a moderator can choose from after logging in.
+type moderatorPick struct {
+	ModeratorID string                 `json:"moderator_id"`
+	Channels    []api.ModeratedChannel `json:"channels"`
+}
+
+// findModeratedChannel returns the channel matching the broadcaster id or login, or nil.
+func findModeratedChannel(channels []api.ModeratedChannel, broadcaster string) *api.ModeratedChannel {
+	for i, ch := range channels {
+		if ch.BroadcasterID == broadcaster || strings.EqualFold(ch.BroadcasterLogin, broadcaster) {
+			return &channels[i]
+		}
+	}
+	return nil
+}
+
+// saveModeratedChannel stores the broadcaster of a moderated channel as a user.
+func saveModeratedChannel(c *fiber.Ctx, channel *api.ModeratedChannel) (*mongo.User, error) {
+	user := &mongo.User{
+		ID:    channel.BroadcasterID,
+		Name:  channel.BroadcasterName,
+		Login: channel.BroadcasterLogin,
+	}
+
+	return user, bot.StoreUser(c.Context(), user)
+}
+
+// moderatedChannelsList renders the links a moderator can use to pick one of their channels.
+func moderatedChannelsList(pickCode string, channels []api.ModeratedChannel) []byte {
+	items := []string{}
+	for _, ch := range channels {
+		params, _ := qs.Marshal(map[string]string{
+			"pick": pickCode,
+			"id":   ch.BroadcasterID,
+		})
+		items = append(items, fmt.Sprintf(`<li><a href="/login/channel?%s">%s</a></li>`, html.EscapeString(params), html.EscapeString(ch.BroadcasterName)))
+	}
+
+	return []byte(fmt.Sprintf(`<p>You can also log a channel you moderate:</p><ul>%s</ul>`, strings.Join(items, "")))
+}
+
+// unbanRequest reads the unban request of the callback, the moderator and the resolution are null until it is resolved.
+func unbanRequest(callback *TwitchCallback) (*mongo.UnbanRequest, bool) {
+	request := &mongo.UnbanRequest{Status: "pending"}
+
+	var ok bool
+	if request.ID, ok = callback.Event["id"].(string); !ok {
+		return nil, false
+	}
+	if request.BroadcasterID, ok = callback.Event["broadcaster_user_id"].(string); !ok {
+		return nil, false
+	}
+	if request.BroadcasterUserName, ok = callback.Event["broadcaster_user_login"].(string); !ok {
+		return nil, false
+	}
+	if request.UserID, ok = callback.Event["user_id"].(string); !ok {
+		return nil, false
+	}
+	if request.UserName, ok = callback.Event["user_login"].(string); !ok {
+		return nil, false
+	}
+
+	if callback.Subscription.Type == "channel.unban_request.create" {
+		request.Text, _ = callback.Event["text"].(string)
+		created, _ := callback.Event["created_at"].(string)
+		t, err := time.Parse(time.RFC3339, created)
+		if err != nil {
+			return nil, false
+		}
+		request.CreatedAt = t
+		return request, true
+	}
+
+	if request.Status, ok = callback.Event["status"].(string); !ok {
+		return nil, false
+	}
+	request.ModeratorUserName, _ = callback.Event["moderator_user_login"].(string)
+	request.Resolution, _ = callback.Event["resolution_text"].(string)
+
+	return request, true
+}
+
+// moderateActions are the channel.moderate actions logged, with the subscription which sends them to a broadcaster.
+var moderateActions = map[string]string{
+	"ban":       "channel.ban",
+	"timeout":   "channel.ban",
+	"unban":     "channel.unban",
+	"untimeout": "channel.unban",
+	"mod":       "channel.moderator.add",
+	"unmod":     "channel.moderator.remove",
+}
+
+// moderateRequest turns an action of channel.moderate into the request of the subscription the broadcaster would have,
+// channels authorized by a moderator only have channel.moderate. The request is nil when the action isn't logged or the
+// broadcaster's subscriptions already send it.
+func moderateRequest(ctx context.Context, msgID string, t time.Time, broadcasterID string, action string, event map[string]interface{}) (*bot.WebhookRequest, bool, error) {
+	if _, ok := moderateActions[action]; !ok {
+		return nil, true, nil
+	}
+
+	id, err := redis.Client.HGet(ctx, fmt.Sprintf("webhook:twitch:channel.ban:%s", broadcasterID), "id").Result()
+	if err != nil && err != redis.ErrNil {
+		return nil, false, err
+	}
+	if id != "" {
+		return nil, true, nil
+	}
+
+	req, ok := moderateEvent(msgID, t, broadcasterID, action, event)
+	return req, ok, nil
+}
+
+// moderateEvent reads the target of the channel.moderate action, the request is nil when the action isn't logged and it
+// is false when the event is malformed.
+func moderateEvent(msgID string, t time.Time, broadcasterID string, action string, event map[string]interface{}) (*bot.WebhookRequest, bool) {
+	hookType, ok := moderateActions[action]
+	if !ok {
+		return nil, true
+	}
+
+	target, _ := event[action].(map[string]interface{})
+
+	req := &bot.WebhookRequest{
+		ID:            msgID,
+		CreatedAt:     t,
+		BroadcasterID: broadcasterID,
+		Action:        hookType,
+	}
+	req.BroadcasterUserName, _ = event["broadcaster_user_login"].(string)
+	req.UserName, _ = target["user_login"].(string)
+	req.UserID, _ = target["user_id"].(string)
+	if req.BroadcasterUserName == "" || req.UserName == "" || req.UserID == "" {
+		return nil, false
+	}
+
+	if hookType == "channel.ban" || hookType == "channel.unban" {
+		req.ModeratorUserName, _ = event["moderator_user_login"].(string)
+		req.ModeratorID, _ = event["moderator_user_id"].(string)
+		req.Reason, _ = target["reason"].(string)
+	}
+
+	if action == "timeout" {
+		exp, _ := target["expires_at"].(string)
+		expires, err := time.Parse(time.RFC3339, exp)
+		if err != nil {
+			return nil, false
+		}
+		req.Expires = &expires
+	}
+
+	return req, true
+}
@@ -0,0 +1,82 @@
+package server
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/troydota/modlogs/src/bot"
+)
+
+func TestModerateEvent(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	expires := time.Date(2021, 6, 1, 12, 10, 0, 0, time.UTC)
+
+	event := func(action string, target map[string]interface{}) map[string]interface{} {
+		return map[string]interface{}{
+			"broadcaster_user_id":    "10",
+			"broadcaster_user_login": "streamer",
+			"moderator_user_id":      "20",
+			"moderator_user_login":   "mod",
+			"action":                 action,
+			action:                   target,
+		}
+	}
+	user := func(extra map[string]interface{}) map[string]interface{} {
+		target := map[string]interface{}{"user_id": "30", "user_login": "user", "user_name": "User"}
+		for k, v := range extra {
+			target[k] = v
+		}
+		return target
+	}
+	request := func(action string, moderated bool, reason string, expires *time.Time) *bot.WebhookRequest {
+		req := &bot.WebhookRequest{
+			ID:                  "msg",
+			BroadcasterID:       "10",
+			BroadcasterUserName: "streamer",
+			UserID:              "30",
+			UserName:            "user",
+			Action:              action,
+			Reason:              reason,
+			Expires:             expires,
+			CreatedAt:           now,
+		}
+		if moderated {
+			req.ModeratorID = "20"
+			req.ModeratorUserName = "mod"
+		}
+		return req
+	}
+
+	tests := []struct {
+		name   string
+		action string
+		event  map[string]interface{}
+		want   *bot.WebhookRequest
+		ok     bool
+	}{
+		{"ban", "ban", event("ban", user(map[string]interface{}{"reason": "spam"})), request("channel.ban", true, "spam", nil), true},
+		{"timeout", "timeout", event("timeout", user(map[string]interface{}{"reason": "caps", "expires_at": "2021-06-01T12:10:00Z"})), request("channel.ban", true, "caps", &expires), true},
+		{"unban", "unban", event("unban", user(nil)), request("channel.unban", true, "", nil), true},
+		{"untimeout", "untimeout", event("untimeout", user(nil)), request("channel.unban", true, "", nil), true},
+		{"mod", "mod", event("mod", user(nil)), request("channel.moderator.add", false, "", nil), true},
+		{"unmod", "unmod", event("unmod", user(nil)), request("channel.moderator.remove", false, "", nil), true},
+		{"not logged", "slow", event("slow", map[string]interface{}{"wait_time_seconds": 10}), nil, true},
+		{"deletion is not an action", "delete", event("delete", user(map[string]interface{}{"message_id": "m"})), nil, true},
+		{"timeout without expiry", "timeout", event("timeout", user(nil)), nil, false},
+		{"missing target", "ban", event("ban", nil), nil, false},
+		{"missing user id", "ban", event("ban", map[string]interface{}{"user_login": "user"}), nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := moderateEvent("msg", now, "10", tt.action, tt.event)
+			if ok != tt.ok {
+				t.Errorf("ok = %v, want %v", ok, tt.ok)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("moderateEvent = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package sinks
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/troydota/modlogs/src/mongo"
+)
+
+var ErrInvalidURL = fmt.Errorf("invalid sink url")
+
+const slackHost = "hooks.slack.com"
+
+var blockedNetworks = func() []*net.IPNet {
+	networks := []*net.IPNet{}
+	for _, cidr := range []string{
+		"0.0.0.0/8",
+		"10.0.0.0/8",
+		"100.64.0.0/10",
+		"127.0.0.0/8",
+		"169.254.0.0/16",
+		"172.16.0.0/12",
+		"192.168.0.0/16",
+		"::/128",
+		"::1/128",
+		"fc00::/7",
+		"fe80::/10",
+	} {
+		_, network, _ := net.ParseCIDR(cidr)
+		networks = append(networks, network)
+	}
+	return networks
+}()
+
+// publicIP is false for the loopback, private, link-local and unspecified addresses.
+func publicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified() || ip.IsMulticast() {
+		return false
+	}
+	for _, network := range blockedNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// client only connects to public addresses, the urls are set by guild admins so a host resolving to an internal
+// address, or redirecting to one, is refused when the connection is made.
+var client = &http.Client{
+	Timeout: 10 * time.Second,
+	Transport: &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout: 5 * time.Second,
+			Control: func(network string, address string, c syscall.RawConn) error {
+				host, _, err := net.SplitHostPort(address)
+				if err != nil {
+					return err
+				}
+				if ip := net.ParseIP(host); ip == nil || !publicIP(ip) {
+					return ErrInvalidURL
+				}
+				return nil
+			},
+		}).DialContext,
+		TLSHandshakeTimeout: 5 * time.Second,
+	},
+}
+
+// slackURL is true when the url points at the slack webhook host on the https port.
+func slackURL(u *url.URL) bool {
+	return u.Scheme == "https" && strings.ToLower(u.Hostname()) == slackHost && (u.Port() == "" || u.Port() == "443")
+}
+
+// ValidateURL checks the url of a sink before it is stored, slack urls must be incoming webhooks and matrix homeservers
+// must resolve to public addresses.
+func ValidateURL(ctx context.Context, sinkType int32, raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "https" || u.Host == "" || u.User != nil {
+		return ErrInvalidURL
+	}
+
+	switch sinkType {
+	case mongo.SinkSlack:
+		if !slackURL(u) {
+			return ErrInvalidURL
+		}
+	case mongo.SinkMatrix:
+		ips, err := net.DefaultResolver.LookupIPAddr(ctx, u.Hostname())
+		if err != nil || len(ips) == 0 {
+			return ErrInvalidURL
+		}
+		for _, ip := range ips {
+			if !publicIP(ip.IP) {
+				return ErrInvalidURL
+			}
+		}
+	default:
+		return ErrUnknownSink
+	}
+
+	return nil
+}
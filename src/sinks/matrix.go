@@ -0,0 +1,90 @@
+package sinks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+var ErrInvalidRespMatrix = fmt.Errorf("invalid resp from matrix")
+
+// Matrix posts entries into a matrix room using the client-server api.
+type Matrix struct {
+	Homeserver string
+	RoomID     string
+	Token      string
+	Minimal    bool
+}
+
+type matrixMessage struct {
+	MsgType       string `json:"msgtype"`
+	Body          string `json:"body"`
+	Format        string `json:"format"`
+	FormattedBody string `json:"formatted_body"`
+}
+
+func (m *Matrix) Send(ctx context.Context, entry *Entry) error {
+	data, err := json.Marshal(m.message(entry))
+	if err != nil {
+		return err
+	}
+
+	txnID, _ := uuid.NewRandom()
+
+	u := fmt.Sprintf("%s/_matrix/client/r0/rooms/%s/send/m.room.message/%s", strings.TrimRight(m.Homeserver, "/"), url.PathEscape(m.RoomID), txnID.String())
+
+	req, err := http.NewRequestWithContext(ctx, "PUT", u, bytes.NewBuffer(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", m.Token))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode > 300 {
+		data, err := ioutil.ReadAll(resp.Body)
+		log.WithError(err).WithField("body", string(data)).Error("matrix")
+		return ErrInvalidRespMatrix
+	}
+
+	return nil
+}
+
+// message renders the entry as a matrix notice.
+func (m *Matrix) message(entry *Entry) matrixMessage {
+	executer := strings.ReplaceAll(entry.Executer, "`", "")
+	command := strings.ReplaceAll(entry.Command, "`", "")
+
+	msg := matrixMessage{
+		MsgType:       "m.notice",
+		Format:        "org.matrix.custom.html",
+		Body:          fmt.Sprintf("%s: #%s - %s executed /%s", entry.Title, entry.Broadcaster, executer, command),
+		FormattedBody: fmt.Sprintf("<b>%s: #%s</b> - <code>%s</code> executed <code>/%s</code>", html.EscapeString(entry.Title), html.EscapeString(entry.Broadcaster), html.EscapeString(executer), html.EscapeString(command)),
+	}
+
+	if !m.Minimal {
+		lines := []string{entry.Title}
+		rows := []string{}
+		for _, f := range entry.Fields {
+			lines = append(lines, fmt.Sprintf("%s: %s", f.Name, f.Value))
+			rows = append(rows, fmt.Sprintf("<tr><td><b>%s</b></td><td>%s</td></tr>", html.EscapeString(f.Name), html.EscapeString(f.Value)))
+		}
+		msg.Body = strings.Join(lines, "\n")
+		msg.FormattedBody = fmt.Sprintf(`<h4><font color="%s">%s</font></h4><table>%s</table><sub>%s</sub>`, hexColor(entry.Color), html.EscapeString(entry.Title), strings.Join(rows, ""), entry.CreatedAt.UTC().Format("Mon Jan _2 15:04:05 2006"))
+	}
+
+	return msg
+}
@@ -0,0 +1,55 @@
+package sinks
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMatrixMessage(t *testing.T) {
+	entry := &Entry{
+		Title:       "User Ban Event",
+		Color:       13632027,
+		Broadcaster: "streamer",
+		Executer:    "mod`<b>",
+		Command:     "ban user",
+		Fields: []Field{
+			{Name: "User", Value: "<user>"},
+			{Name: "Reason", Value: "spam & more"},
+		},
+		CreatedAt: time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name          string
+		minimal       bool
+		body          string
+		formattedBody string
+	}{
+		{
+			name:          "minimal",
+			minimal:       true,
+			body:          "User Ban Event: #streamer - mod<b> executed /ban user",
+			formattedBody: "<b>User Ban Event: #streamer</b> - <code>mod&lt;b&gt;</code> executed <code>/ban user</code>",
+		},
+		{
+			name:          "embed",
+			body:          "User Ban Event\nUser: <user>\nReason: spam & more",
+			formattedBody: `<h4><font color="#d0021b">User Ban Event</font></h4><table><tr><td><b>User</b></td><td>&lt;user&gt;</td></tr><tr><td><b>Reason</b></td><td>spam &amp; more</td></tr></table><sub>Tue Jun  1 12:00:00 2021</sub>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := (&Matrix{Minimal: tt.minimal}).message(entry)
+			if msg.MsgType != "m.notice" || msg.Format != "org.matrix.custom.html" {
+				t.Errorf("msgtype = %q, format = %q", msg.MsgType, msg.Format)
+			}
+			if msg.Body != tt.body {
+				t.Errorf("body = %q, want %q", msg.Body, tt.body)
+			}
+			if msg.FormattedBody != tt.formattedBody {
+				t.Errorf("formatted body = %q, want %q", msg.FormattedBody, tt.formattedBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package sinks
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/troydota/modlogs/src/mongo"
+)
+
+var json = jsoniter.ConfigCompatibleWithStandardLibrary
+
+var ErrUnknownSink = fmt.Errorf("unknown sink type")
+
+type Field struct {
+	Name  string
+	Value string
+}
+
+// Entry is a rendered moderation event, the same one that is posted to discord.
+type Entry struct {
+	Title       string
+	Color       int
+	Broadcaster string
+	Executer    string
+	ExecuterID  string
+	Command     string
+	Fields      []Field
+	CreatedAt   time.Time
+}
+
+type Sink interface {
+	Send(ctx context.Context, entry *Entry) error
+}
+
+func New(s *mongo.Sink) (Sink, error) {
+	switch s.Type {
+	case mongo.SinkSlack:
+		return &Slack{URL: s.URL, Minimal: s.Mode == mongo.ModeMinimal}, nil
+	case mongo.SinkMatrix:
+		return &Matrix{Homeserver: s.URL, RoomID: s.RoomID, Token: s.Token, Minimal: s.Mode == mongo.ModeMinimal}, nil
+	}
+	return nil, ErrUnknownSink
+}
+
+func hexColor(color int) string {
+	return fmt.Sprintf("#%06x", color)
+}
@@ -0,0 +1,115 @@
+package sinks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var ErrInvalidRespSlack = fmt.Errorf("invalid resp from slack")
+
+// The most fields slack accepts in a section block.
+const slackSectionFields = 10
+
+// Slack posts entries to a slack incoming webhook.
+type Slack struct {
+	URL     string
+	Minimal bool
+}
+
+type slackMessage struct {
+	Text        string            `json:"text"`
+	Attachments []slackAttachment `json:"attachments,omitempty"`
+}
+
+type slackAttachment struct {
+	Color  string       `json:"color"`
+	Blocks []slackBlock `json:"blocks"`
+}
+
+type slackBlock struct {
+	Type     string      `json:"type"`
+	Text     *slackText  `json:"text,omitempty"`
+	Fields   []slackText `json:"fields,omitempty"`
+	Elements []slackText `json:"elements,omitempty"`
+}
+
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+func (s *Slack) Send(ctx context.Context, entry *Entry) error {
+	data, err := json.Marshal(s.message(entry))
+	if err != nil {
+		return err
+	}
+
+	// Sinks stored before the urls were restricted are checked again.
+	if u, err := url.Parse(s.URL); err != nil || u.User != nil || !slackURL(u) {
+		return ErrInvalidURL
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", s.URL, bytes.NewBuffer(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode > 300 {
+		data, err := ioutil.ReadAll(resp.Body)
+		log.WithError(err).WithField("body", string(data)).Error("slack")
+		return ErrInvalidRespSlack
+	}
+
+	return nil
+}
+
+// message renders the entry as a slack message.
+func (s *Slack) message(entry *Entry) slackMessage {
+	text := fmt.Sprintf("*%s: #%s* - `%s` executed `/%s`", entry.Title, slackEscaper.Replace(entry.Broadcaster), slackEscaper.Replace(strings.ReplaceAll(entry.Executer, "`", "")), slackEscaper.Replace(strings.ReplaceAll(entry.Command, "`", "")))
+
+	msg := slackMessage{
+		Text: text,
+	}
+
+	if !s.Minimal {
+		blocks := []slackBlock{
+			{Type: "header", Text: &slackText{Type: "plain_text", Text: entry.Title}},
+		}
+		// Slack rejects sections with more than 10 fields, longer entries are split over several sections.
+		for i := 0; i < len(entry.Fields); i += slackSectionFields {
+			end := i + slackSectionFields
+			if end > len(entry.Fields) {
+				end = len(entry.Fields)
+			}
+			fields := []slackText{}
+			for _, f := range entry.Fields[i:end] {
+				fields = append(fields, slackText{Type: "mrkdwn", Text: fmt.Sprintf("*%s*\n%s", slackEscaper.Replace(f.Name), slackEscaper.Replace(f.Value))})
+			}
+			blocks = append(blocks, slackBlock{Type: "section", Fields: fields})
+		}
+		blocks = append(blocks, slackBlock{Type: "context", Elements: []slackText{{Type: "mrkdwn", Text: fmt.Sprintf("<!date^%d^{date_short_pretty} {time_secs}|%s>", entry.CreatedAt.Unix(), entry.CreatedAt.UTC().Format("Mon Jan _2 15:04:05 2006"))}}})
+
+		msg.Attachments = []slackAttachment{{
+			Color:  hexColor(entry.Color),
+			Blocks: blocks,
+		}}
+	}
+
+	return msg
+}
@@ -0,0 +1,72 @@
+package sinks
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestSlackMessage(t *testing.T) {
+	entry := &Entry{
+		Title:       "User Ban Event",
+		Color:       13632027,
+		Broadcaster: "streamer",
+		Executer:    "mod`<b>",
+		Command:     "ban user & co",
+		CreatedAt:   time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC),
+	}
+	for i := 0; i < 12; i++ {
+		entry.Fields = append(entry.Fields, Field{Name: fmt.Sprintf("Field %d", i), Value: "<value>"})
+	}
+
+	wantText := "*User Ban Event: #streamer* - `mod&lt;b&gt;` executed `/ban user &amp; co`"
+
+	tests := []struct {
+		name     string
+		minimal  bool
+		sections []int
+	}{
+		{"minimal", true, nil},
+		{"embed", false, []int{10, 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := (&Slack{Minimal: tt.minimal}).message(entry)
+			if msg.Text != wantText {
+				t.Errorf("text = %q, want %q", msg.Text, wantText)
+			}
+			if tt.minimal {
+				if len(msg.Attachments) != 0 {
+					t.Errorf("minimal message has %d attachments", len(msg.Attachments))
+				}
+				return
+			}
+
+			if len(msg.Attachments) != 1 {
+				t.Fatalf("message has %d attachments, want 1", len(msg.Attachments))
+			}
+			attachment := msg.Attachments[0]
+			if attachment.Color != "#d0021b" {
+				t.Errorf("color = %q, want #d0021b", attachment.Color)
+			}
+
+			// A header, the sections holding the fields and the context with the date.
+			blocks := attachment.Blocks
+			if len(blocks) != len(tt.sections)+2 {
+				t.Fatalf("message has %d blocks, want %d", len(blocks), len(tt.sections)+2)
+			}
+			if blocks[0].Type != "header" || blocks[len(blocks)-1].Type != "context" {
+				t.Errorf("blocks start with %q and end with %q", blocks[0].Type, blocks[len(blocks)-1].Type)
+			}
+			for i, count := range tt.sections {
+				if got := len(blocks[i+1].Fields); got != count {
+					t.Errorf("section %d has %d fields, want %d", i, got, count)
+				}
+			}
+			if got := blocks[1].Fields[0].Text; got != "*Field 0*\n&lt;value&gt;" {
+				t.Errorf("field = %q", got)
+			}
+		})
+	}
+}